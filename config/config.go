@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +18,79 @@ type Config struct {
 	MinioSecretKey  string
 	MinioBucketName string
 	SqsQueueUrl     string
+	TrashRetention  time.Duration
+
+	// TrustedProxies are the proxy addresses or CIDRs whose X-Forwarded-For
+	// and X-Real-IP headers are believed; none are by default.
+	TrustedProxies []string
+
+	// StorageURL names the backend documents are stored in, minio://bucket
+	// or file:///path; storage migrations move them to another one.
+	StorageURL string
+	// StorageReplicaURL, when set, names a second backend every object is
+	// also written to, "sync" or "async" per StorageReplication; the two are
+	// compared every StorageReplicaResyncInterval, 0 only at startup.
+	StorageReplicaURL            string
+	StorageReplication           string
+	StorageReplicaResyncInterval time.Duration
+
+	UsageReconcileInterval time.Duration
+
+	RateLimitUploadsPerMinute   int
+	RateLimitUploadsBurst       int
+	RateLimitDownloadsPerMinute int
+	RateLimitDownloadsBurst     int
+	RateLimitMetadataPerMinute  int
+	RateLimitMetadataBurst      int
+	MaxConcurrentTransfers      int
+
+	// Scanner selects the malware scanner: "signature" (EICAR only) or "clamd".
+	Scanner      string
+	ClamdAddress string
+	ClamdTimeout time.Duration
+
+	UploadAllowedTypes      []string
+	UploadDeniedTypes       []string
+	UploadAllowedExtensions []string
+	UploadDeniedExtensions  []string
+	UploadSizeLimits        map[string]int64
+	// UploadTypeMismatch is "reject", "correct" or "allow".
+	UploadTypeMismatch string
+
+	// ThumbnailSizes are the bounding boxes, in pixels, thumbnails are rendered to.
+	ThumbnailSizes []int
+
+	// ArchiveMaxBytes and ArchiveMaxDocuments cap one ZIP download; 0 disables a cap.
+	ArchiveMaxBytes     int64
+	ArchiveMaxDocuments int
+
+	// Import limits guard archive uploads against archive bombs; 0 disables a limit.
+	ImportMaxEntries          int
+	ImportMaxEntryBytes       int64
+	ImportMaxTotalBytes       int64
+	ImportMaxCompressionRatio int64
+
+	// JobWorkers is how many jobs run at once; finished jobs and their
+	// artifacts are kept for JobRetention.
+	JobWorkers   int
+	JobRetention time.Duration
+
+	// FsckInterval schedules storage reconciliation, 0 disables it; FsckRepair
+	// lets the scheduled run delete orphaned objects and mark missing ones.
+	FsckInterval time.Duration
+	FsckRepair   bool
+
+	BootstrapAdminKey string
+
+	JWTHS256Secret      string
+	JWTRSAPublicKeyFile string
+	JWTJWKSFile         string
+	JWTIssuer           string
+	JWTAudience         string
+	JWTRolesClaim       string
+	JWTGroupsClaim      string
+	JWTTenantClaim      string
+	JWTLeeway           time.Duration
 }
 
 func Load() *Config {
@@ -23,13 +99,157 @@ func Load() *Config {
 		log.Fatal("Error loading .env file")
 	}
 
+	bucketName := os.Getenv("MINIO_BUCKET_NAME")
+
 	return &Config{
 		Port:            os.Getenv("PORT"),
 		DBPath:          os.Getenv("DB_PATH"),
 		MinioEndpoint:   os.Getenv("MINIO_ENDPOINT"),
 		MinioAccessKey:  os.Getenv("MINIO_ACCESS_KEY"),
 		MinioSecretKey:  os.Getenv("MINIO_SECRET_KEY"),
-		MinioBucketName: os.Getenv("MINIO_BUCKET_NAME"),
+		MinioBucketName: bucketName,
 		SqsQueueUrl:     os.Getenv("SQS_QUEUE_URL"),
+		TrashRetention:  getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+
+		StorageURL:                   getEnv("STORAGE_URL", "minio://"+bucketName),
+		StorageReplicaURL:            os.Getenv("STORAGE_REPLICA_URL"),
+		StorageReplication:           getEnv("STORAGE_REPLICATION", "sync"),
+		StorageReplicaResyncInterval: getEnvDuration("STORAGE_REPLICA_RESYNC_INTERVAL", 24*time.Hour),
+
+		UsageReconcileInterval: getEnvDuration("USAGE_RECONCILE_INTERVAL", time.Hour),
+
+		RateLimitUploadsPerMinute:   getEnvInt("RATE_LIMIT_UPLOADS_PER_MINUTE", 60),
+		RateLimitUploadsBurst:       getEnvInt("RATE_LIMIT_UPLOADS_BURST", 10),
+		RateLimitDownloadsPerMinute: getEnvInt("RATE_LIMIT_DOWNLOADS_PER_MINUTE", 120),
+		RateLimitDownloadsBurst:     getEnvInt("RATE_LIMIT_DOWNLOADS_BURST", 20),
+		RateLimitMetadataPerMinute:  getEnvInt("RATE_LIMIT_METADATA_PER_MINUTE", 600),
+		RateLimitMetadataBurst:      getEnvInt("RATE_LIMIT_METADATA_BURST", 100),
+		MaxConcurrentTransfers:      getEnvInt("MAX_CONCURRENT_TRANSFERS", 4),
+
+		Scanner:      os.Getenv("SCANNER"),
+		ClamdAddress: os.Getenv("CLAMD_ADDRESS"),
+		ClamdTimeout: getEnvDuration("CLAMD_TIMEOUT", 30*time.Second),
+
+		UploadAllowedTypes:      getEnvList("UPLOAD_ALLOWED_TYPES"),
+		UploadDeniedTypes:       getEnvList("UPLOAD_DENIED_TYPES"),
+		UploadAllowedExtensions: getEnvList("UPLOAD_ALLOWED_EXTENSIONS"),
+		UploadDeniedExtensions:  getEnvList("UPLOAD_DENIED_EXTENSIONS"),
+		UploadSizeLimits:        getEnvSizeLimits("UPLOAD_SIZE_LIMITS"),
+		UploadTypeMismatch:      os.Getenv("UPLOAD_TYPE_MISMATCH"),
+
+		ThumbnailSizes: getEnvIntList("THUMBNAIL_SIZES", []int{128, 512}),
+
+		ArchiveMaxBytes:     int64(getEnvInt("ARCHIVE_MAX_BYTES", 1<<30)),
+		ArchiveMaxDocuments: getEnvInt("ARCHIVE_MAX_DOCUMENTS", 1000),
+
+		ImportMaxEntries:          getEnvInt("IMPORT_MAX_ENTRIES", 10000),
+		ImportMaxEntryBytes:       int64(getEnvInt("IMPORT_MAX_ENTRY_BYTES", 1<<30)),
+		ImportMaxTotalBytes:       int64(getEnvInt("IMPORT_MAX_TOTAL_BYTES", 10<<30)),
+		ImportMaxCompressionRatio: int64(getEnvInt("IMPORT_MAX_COMPRESSION_RATIO", 100)),
+
+		JobWorkers:   getEnvInt("JOB_WORKERS", 2),
+		JobRetention: getEnvDuration("JOB_RETENTION", 7*24*time.Hour),
+
+		FsckInterval: getEnvDuration("FSCK_INTERVAL", 24*time.Hour),
+		FsckRepair:   os.Getenv("FSCK_REPAIR") == "true",
+
+		BootstrapAdminKey: os.Getenv("BOOTSTRAP_ADMIN_KEY"),
+
+		JWTHS256Secret:      os.Getenv("JWT_HS256_SECRET"),
+		JWTRSAPublicKeyFile: os.Getenv("JWT_RSA_PUBLIC_KEY_FILE"),
+		JWTJWKSFile:         os.Getenv("JWT_JWKS_FILE"),
+		JWTIssuer:           os.Getenv("JWT_ISSUER"),
+		JWTAudience:         os.Getenv("JWT_AUDIENCE"),
+		JWTRolesClaim:       os.Getenv("JWT_ROLES_CLAIM"),
+		JWTGroupsClaim:      os.Getenv("JWT_GROUPS_CLAIM"),
+		JWTTenantClaim:      os.Getenv("JWT_TENANT_CLAIM"),
+		JWTLeeway:           getEnvDuration("JWT_LEEWAY", 30*time.Second),
+	}
+}
+
+func getEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
 	}
+
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid duration for %s: %v", key, err)
+	}
+
+	return duration
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Invalid integer for %s: %v", key, err)
+	}
+
+	return number
+}
+
+// getEnvList splits a comma-separated value, dropping empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func getEnvIntList(key string, fallback []int) []int {
+	values := getEnvList(key)
+	if len(values) == 0 {
+		return fallback
+	}
+
+	numbers := make([]int, 0, len(values))
+	for _, value := range values {
+		number, err := strconv.Atoi(value)
+		if err != nil || number <= 0 {
+			log.Fatalf("Invalid positive integer %q for %s", value, key)
+		}
+		numbers = append(numbers, number)
+	}
+
+	return numbers
+}
+
+// getEnvSizeLimits parses "pattern=bytes" pairs such as "image/*=10485760,application/pdf=52428800".
+func getEnvSizeLimits(key string) map[string]int64 {
+	limits := map[string]int64{}
+	for _, entry := range getEnvList(key) {
+		pattern, size, ok := strings.Cut(entry, "=")
+		if !ok {
+			log.Fatalf("Invalid size limit %q for %s, want pattern=bytes", entry, key)
+		}
+
+		limit, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+		if err != nil || limit < 0 {
+			log.Fatalf("Invalid size limit %q for %s, want pattern=bytes", entry, key)
+		}
+		limits[strings.TrimSpace(pattern)] = limit
+	}
+
+	return limits
 }
@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 func RunMigrations(db *sql.DB) error {
@@ -22,6 +23,110 @@ func RunMigrations(db *sql.DB) error {
 		return fmt.Errorf("failed to create document chunks table: %w", err)
 	}
 
+	if err := AddDocumentsTrashColumn(db); err != nil {
+		return fmt.Errorf("failed to add documents trash column: %w", err)
+	}
+
+	if err := CreateDocumentTagsTable(db); err != nil {
+		return fmt.Errorf("failed to create document tags table: %w", err)
+	}
+
+	if err := CreateDocumentMetadataTable(db); err != nil {
+		return fmt.Errorf("failed to create document metadata table: %w", err)
+	}
+
+	if err := CreateFoldersTable(db); err != nil {
+		return fmt.Errorf("failed to create folders table: %w", err)
+	}
+
+	if err := AddDocumentsFolderColumn(db); err != nil {
+		return fmt.Errorf("failed to add documents folder column: %w", err)
+	}
+
+	if err := AddDocumentsVersionColumns(db); err != nil {
+		return fmt.Errorf("failed to add documents version columns: %w", err)
+	}
+
+	if err := CreateRetentionPoliciesTable(db); err != nil {
+		return fmt.Errorf("failed to create retention policies table: %w", err)
+	}
+
+	if err := AddDocumentsRetentionColumns(db); err != nil {
+		return fmt.Errorf("failed to add documents retention columns: %w", err)
+	}
+
+	if err := CreateLegalHoldsTable(db); err != nil {
+		return fmt.Errorf("failed to create legal holds table: %w", err)
+	}
+
+	if err := CreateAuditLogTable(db); err != nil {
+		return fmt.Errorf("failed to create audit log table: %w", err)
+	}
+
+	if err := CreateAPIKeysTable(db); err != nil {
+		return fmt.Errorf("failed to create api keys table: %w", err)
+	}
+
+	if err := AddDocumentsOwnerColumn(db); err != nil {
+		return fmt.Errorf("failed to add documents owner column: %w", err)
+	}
+
+	if err := CreateDocumentACLTable(db); err != nil {
+		return fmt.Errorf("failed to create document acl table: %w", err)
+	}
+
+	if err := CreateShareLinksTable(db); err != nil {
+		return fmt.Errorf("failed to create share links table: %w", err)
+	}
+
+	if err := CreateTenantsTable(db); err != nil {
+		return fmt.Errorf("failed to create tenants table: %w", err)
+	}
+
+	if err := AddTenantColumns(db); err != nil {
+		return fmt.Errorf("failed to add tenant columns: %w", err)
+	}
+
+	if err := AddTenantQuotaColumns(db); err != nil {
+		return fmt.Errorf("failed to add tenant quota columns: %w", err)
+	}
+
+	if err := CreateStorageUsageTable(db); err != nil {
+		return fmt.Errorf("failed to create storage usage table: %w", err)
+	}
+
+	if err := AddDocumentScanColumns(db); err != nil {
+		return fmt.Errorf("failed to add document scan columns: %w", err)
+	}
+
+	if err := AddDocumentContentTypeColumns(db); err != nil {
+		return fmt.Errorf("failed to add document content type columns: %w", err)
+	}
+
+	if err := CreateDocumentThumbnailsTable(db); err != nil {
+		return fmt.Errorf("failed to create document thumbnails table: %w", err)
+	}
+
+	if err := CreateImportsTables(db); err != nil {
+		return fmt.Errorf("failed to create imports tables: %w", err)
+	}
+
+	if err := CreateJobsTable(db); err != nil {
+		return fmt.Errorf("failed to create jobs table: %w", err)
+	}
+
+	if err := AddDocumentMissingColumn(db); err != nil {
+		return fmt.Errorf("failed to add document missing column: %w", err)
+	}
+
+	if err := CreateStorageMigrationsTables(db); err != nil {
+		return fmt.Errorf("failed to create storage migrations tables: %w", err)
+	}
+
+	if err := AddDocumentScanAttemptColumns(db); err != nil {
+		return fmt.Errorf("failed to add document scan attempt columns: %w", err)
+	}
+
 	return nil
 }
 
@@ -99,3 +204,616 @@ func CreateDocumentChunksTable(db *sql.DB) error {
 	fmt.Println("Table 'document_chunks' created successfully")
 	return nil
 }
+
+func AddDocumentsTrashColumn(db *sql.DB) error {
+	if _, err := addColumnIfNotExists(db, "documents", "deleted_at", "DATETIME"); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_documents_deleted_at ON documents (deleted_at)`); err != nil {
+		return fmt.Errorf("failed to create documents deleted_at index: %w", err)
+	}
+
+	return nil
+}
+
+func CreateDocumentTagsTable(db *sql.DB) error {
+	createDocumentTagsQuery := ` CREATE TABLE IF NOT EXISTS document_tags (
+            document_id TEXT NOT NULL,
+            tag TEXT NOT NULL,
+            PRIMARY KEY (document_id, tag)
+    );
+    CREATE INDEX IF NOT EXISTS idx_document_tags_tag ON document_tags (tag);
+	`
+
+	_, err := db.Exec(createDocumentTagsQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create document_tags table: %w", err)
+	}
+
+	fmt.Println("Table 'document_tags' created successfully")
+	return nil
+}
+
+func CreateDocumentMetadataTable(db *sql.DB) error {
+	createDocumentMetadataQuery := ` CREATE TABLE IF NOT EXISTS document_metadata (
+            document_id TEXT NOT NULL,
+            key TEXT NOT NULL,
+            value TEXT NOT NULL,
+            PRIMARY KEY (document_id, key)
+    );
+    CREATE INDEX IF NOT EXISTS idx_document_metadata_key_value ON document_metadata (key, value);
+	`
+
+	_, err := db.Exec(createDocumentMetadataQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create document_metadata table: %w", err)
+	}
+
+	fmt.Println("Table 'document_metadata' created successfully")
+	return nil
+}
+
+func CreateFoldersTable(db *sql.DB) error {
+	createFoldersQuery := ` CREATE TABLE IF NOT EXISTS folders (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL,
+            parent_id TEXT REFERENCES folders(id),
+            path TEXT NOT NULL,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL
+    );
+    CREATE INDEX IF NOT EXISTS idx_folders_path ON folders (path);
+	`
+
+	_, err := db.Exec(createFoldersQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create folders table: %w", err)
+	}
+
+	fmt.Println("Table 'folders' created successfully")
+	return nil
+}
+
+func AddDocumentsFolderColumn(db *sql.DB) error {
+	if _, err := addColumnIfNotExists(db, "documents", "folder_id", "TEXT REFERENCES folders(id)"); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_documents_folder_id ON documents (folder_id)`); err != nil {
+		return fmt.Errorf("failed to create documents folder_id index: %w", err)
+	}
+
+	return nil
+}
+
+func AddDocumentsVersionColumns(db *sql.DB) error {
+	if _, err := addColumnIfNotExists(db, "documents", "version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+
+	if _, err := addColumnIfNotExists(db, "documents", "storage_key", "TEXT"); err != nil {
+		return err
+	}
+
+	// Documents uploaded before storage keys were introduced are stored under
+	// their file name; recording it keeps renames from moving their object.
+	if _, err := db.Exec(`UPDATE documents SET storage_key = file_name WHERE storage_key IS NULL OR storage_key = ''`); err != nil {
+		return fmt.Errorf("failed to backfill documents storage_key: %w", err)
+	}
+
+	return nil
+}
+
+func CreateRetentionPoliciesTable(db *sql.DB) error {
+	createRetentionPoliciesQuery := ` CREATE TABLE IF NOT EXISTS retention_policies (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL UNIQUE,
+            content_type TEXT NOT NULL DEFAULT '',
+            folder_id TEXT REFERENCES folders(id),
+            default_ttl_seconds INTEGER,
+            min_retention_seconds INTEGER,
+            max_retention_seconds INTEGER,
+            never_expire INTEGER NOT NULL DEFAULT 0,
+            priority INTEGER NOT NULL DEFAULT 0,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL
+    );
+	`
+
+	_, err := db.Exec(createRetentionPoliciesQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create retention_policies table: %w", err)
+	}
+
+	fmt.Println("Table 'retention_policies' created successfully")
+	return nil
+}
+
+func AddDocumentsRetentionColumns(db *sql.DB) error {
+	added, err := addColumnIfNotExists(db, "documents", "requested_expires_at", "DATETIME")
+	if err != nil {
+		return err
+	}
+
+	// Every expiry recorded before policies existed came straight from expires_in.
+	if added {
+		if _, err := db.Exec(`UPDATE documents SET requested_expires_at = expires_at`); err != nil {
+			return fmt.Errorf("failed to backfill requested expiry: %w", err)
+		}
+	}
+
+	if _, err := addColumnIfNotExists(db, "documents", "retain_forever", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	_, err = addColumnIfNotExists(db, "documents", "retention_policy_id", "TEXT REFERENCES retention_policies(id)")
+	return err
+}
+
+// CreateLegalHoldsTable keeps hold history, so document_id deliberately has no
+// foreign key and released holds outlive a purged document.
+func CreateLegalHoldsTable(db *sql.DB) error {
+	createLegalHoldsQuery := ` CREATE TABLE IF NOT EXISTS legal_holds (
+            id TEXT PRIMARY KEY,
+            document_id TEXT NOT NULL,
+            reason TEXT NOT NULL,
+            created_by TEXT NOT NULL,
+            created_at DATETIME NOT NULL,
+            released_by TEXT,
+            released_at DATETIME,
+            release_reason TEXT
+    );
+    CREATE INDEX IF NOT EXISTS idx_legal_holds_document ON legal_holds (document_id, released_at);
+	`
+
+	_, err := db.Exec(createLegalHoldsQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create legal_holds table: %w", err)
+	}
+
+	fmt.Println("Table 'legal_holds' created successfully")
+	return nil
+}
+
+// CreateAuditLogTable also installs triggers that make the table append-only.
+func CreateAuditLogTable(db *sql.DB) error {
+	createAuditLogQuery := ` CREATE TABLE IF NOT EXISTS audit_log (
+            seq INTEGER PRIMARY KEY AUTOINCREMENT,
+            timestamp DATETIME NOT NULL,
+            action TEXT NOT NULL,
+            document_id TEXT NOT NULL DEFAULT '',
+            actor TEXT NOT NULL,
+            client_ip TEXT NOT NULL DEFAULT '',
+            user_agent TEXT NOT NULL DEFAULT '',
+            request_id TEXT NOT NULL DEFAULT '',
+            outcome TEXT NOT NULL,
+            detail TEXT NOT NULL DEFAULT '',
+            prev_hash TEXT NOT NULL,
+            hash TEXT NOT NULL
+    );
+    CREATE INDEX IF NOT EXISTS idx_audit_log_document ON audit_log (document_id, seq);
+    CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor, seq);
+    CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+    BEGIN
+        SELECT RAISE(ABORT, 'audit_log is append-only');
+    END;
+    CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+    BEGIN
+        SELECT RAISE(ABORT, 'audit_log is append-only');
+    END;
+	`
+
+	_, err := db.Exec(createAuditLogQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create audit_log table: %w", err)
+	}
+
+	fmt.Println("Table 'audit_log' created successfully")
+	return nil
+}
+
+func CreateAPIKeysTable(db *sql.DB) error {
+	createAPIKeysQuery := ` CREATE TABLE IF NOT EXISTS api_keys (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL,
+            prefix TEXT NOT NULL UNIQUE,
+            secret_hash TEXT NOT NULL,
+            permissions TEXT NOT NULL,
+            created_at DATETIME NOT NULL,
+            expires_at DATETIME,
+            last_used_at DATETIME,
+            revoked_at DATETIME
+    );
+	`
+
+	_, err := db.Exec(createAPIKeysQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create api_keys table: %w", err)
+	}
+
+	fmt.Println("Table 'api_keys' created successfully")
+	return nil
+}
+
+// AddDocumentsOwnerColumn stores the uploader's principal ID. User principals
+// are "user:<id>" with the id taken from the users table.
+func AddDocumentsOwnerColumn(db *sql.DB) error {
+	if _, err := addColumnIfNotExists(db, "documents", "owner_id", "TEXT"); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_documents_owner_id ON documents (owner_id)`); err != nil {
+		return fmt.Errorf("failed to create documents owner_id index: %w", err)
+	}
+
+	return nil
+}
+
+func CreateDocumentACLTable(db *sql.DB) error {
+	createDocumentACLQuery := ` CREATE TABLE IF NOT EXISTS document_acl (
+            id TEXT PRIMARY KEY,
+            document_id TEXT NOT NULL REFERENCES documents(id),
+            subject TEXT NOT NULL,
+            permission TEXT NOT NULL,
+            created_by TEXT NOT NULL,
+            created_at DATETIME NOT NULL,
+            UNIQUE (document_id, subject, permission)
+    );
+    CREATE INDEX IF NOT EXISTS idx_document_acl_subject ON document_acl (subject, document_id);
+	`
+
+	_, err := db.Exec(createDocumentACLQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create document_acl table: %w", err)
+	}
+
+	fmt.Println("Table 'document_acl' created successfully")
+	return nil
+}
+
+func CreateShareLinksTable(db *sql.DB) error {
+	createShareLinksQuery := ` CREATE TABLE IF NOT EXISTS share_links (
+            id TEXT PRIMARY KEY,
+            document_id TEXT NOT NULL REFERENCES documents(id),
+            token_hash TEXT NOT NULL UNIQUE,
+            password_hash TEXT,
+            expires_at DATETIME,
+            max_downloads INTEGER,
+            download_count INTEGER NOT NULL DEFAULT 0,
+            created_by TEXT NOT NULL,
+            created_at DATETIME NOT NULL,
+            revoked_at DATETIME,
+            last_used_at DATETIME
+    );
+    CREATE INDEX IF NOT EXISTS idx_share_links_document ON share_links (document_id);
+	`
+
+	_, err := db.Exec(createShareLinksQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create share_links table: %w", err)
+	}
+
+	fmt.Println("Table 'share_links' created successfully")
+	return nil
+}
+
+// CreateTenantsTable also registers the default tenant that owns everything
+// created before tenants existed.
+func CreateTenantsTable(db *sql.DB) error {
+	createTenantsQuery := ` CREATE TABLE IF NOT EXISTS tenants (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL,
+            quota_bytes INTEGER,
+            default_retention_seconds INTEGER,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL
+    );
+	`
+
+	_, err := db.Exec(createTenantsQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create tenants table: %w", err)
+	}
+
+	now := time.Now()
+	_, err = db.Exec(`INSERT OR IGNORE INTO tenants (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)`, "default", "Default", now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create default tenant: %w", err)
+	}
+
+	fmt.Println("Table 'tenants' created successfully")
+	return nil
+}
+
+// AddTenantColumns scopes documents and folders to a tenant. Folder names are
+// unique per tenant and parent, which replaces the global index earlier
+// versions created. API keys without a tenant are platform keys.
+func AddTenantColumns(db *sql.DB) error {
+	for _, table := range []string{"documents", "folders"} {
+		if _, err := addColumnIfNotExists(db, table, "tenant_id", "TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id)"); err != nil {
+			return err
+		}
+	}
+
+	if _, err := addColumnIfNotExists(db, "api_keys", "tenant_id", "TEXT REFERENCES tenants(id)"); err != nil {
+		return err
+	}
+
+	for _, query := range []string{
+		`CREATE INDEX IF NOT EXISTS idx_documents_tenant_id ON documents (tenant_id, created_at)`,
+		`DROP INDEX IF EXISTS idx_folders_parent_name`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_folders_tenant_parent_name ON folders (tenant_id, COALESCE(parent_id, ''), name)`,
+	} {
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("failed to index tenant columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func AddTenantQuotaColumns(db *sql.DB) error {
+	for _, column := range []string{"quota_documents", "user_quota_bytes", "user_quota_documents"} {
+		if _, err := addColumnIfNotExists(db, "tenants", column, "INTEGER"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateStorageUsageTable keeps running byte and document totals per tenant
+// and per owner. Missing rows are seeded from the documents table so existing
+// deployments start with correct totals.
+func CreateStorageUsageTable(db *sql.DB) error {
+	createStorageUsageQuery := ` CREATE TABLE IF NOT EXISTS storage_usage (
+            scope TEXT NOT NULL,
+            subject_id TEXT NOT NULL,
+            bytes INTEGER NOT NULL DEFAULT 0,
+            documents INTEGER NOT NULL DEFAULT 0,
+            updated_at DATETIME NOT NULL,
+            PRIMARY KEY (scope, subject_id)
+    );
+	`
+
+	_, err := db.Exec(createStorageUsageQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create storage_usage table: %w", err)
+	}
+
+	seedQuery := `INSERT OR IGNORE INTO storage_usage (scope, subject_id, bytes, documents, updated_at)
+		SELECT 'tenant', tenant_id, COALESCE(SUM(file_size), 0), COUNT(*), ? FROM documents GROUP BY tenant_id
+		UNION ALL
+		SELECT 'user', owner_id, COALESCE(SUM(file_size), 0), COUNT(*), ? FROM documents WHERE owner_id IS NOT NULL GROUP BY owner_id`
+	now := time.Now()
+	if _, err := db.Exec(seedQuery, now, now); err != nil {
+		return fmt.Errorf("failed to seed storage_usage table: %w", err)
+	}
+
+	fmt.Println("Table 'storage_usage' created successfully")
+	return nil
+}
+
+// AddDocumentScanColumns records malware scan results. Existing documents start
+// out pending so the background scan job covers them before they can be
+// downloaded again.
+func AddDocumentScanColumns(db *sql.DB) error {
+	if _, err := addColumnIfNotExists(db, "documents", "scan_status", "TEXT NOT NULL DEFAULT 'pending'"); err != nil {
+		return err
+	}
+
+	if _, err := addColumnIfNotExists(db, "documents", "scan_result", "TEXT"); err != nil {
+		return err
+	}
+
+	if _, err := addColumnIfNotExists(db, "documents", "scanned_at", "DATETIME"); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_documents_scan_status ON documents (scan_status)`); err != nil {
+		return fmt.Errorf("failed to create documents scan_status index: %w", err)
+	}
+
+	return nil
+}
+
+// AddDocumentContentTypeColumns keeps the type a client declared next to the
+// type sniffed from the content. Both stay empty for older documents.
+func AddDocumentContentTypeColumns(db *sql.DB) error {
+	if _, err := addColumnIfNotExists(db, "documents", "declared_content_type", "TEXT"); err != nil {
+		return err
+	}
+
+	_, err := addColumnIfNotExists(db, "documents", "detected_content_type", "TEXT")
+	return err
+}
+
+func CreateDocumentThumbnailsTable(db *sql.DB) error {
+	createDocumentThumbnailsQuery := ` CREATE TABLE IF NOT EXISTS document_thumbnails (
+            document_id TEXT NOT NULL REFERENCES documents(id),
+            size INTEGER NOT NULL,
+            storage_key TEXT NOT NULL,
+            content_type TEXT NOT NULL,
+            width INTEGER NOT NULL,
+            height INTEGER NOT NULL,
+            file_size INTEGER NOT NULL,
+            created_at DATETIME NOT NULL,
+            PRIMARY KEY (document_id, size)
+    );
+	`
+
+	_, err := db.Exec(createDocumentThumbnailsQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create document_thumbnails table: %w", err)
+	}
+
+	fmt.Println("Table 'document_thumbnails' created successfully")
+	return nil
+}
+
+func CreateImportsTables(db *sql.DB) error {
+	createImportsQuery := ` CREATE TABLE IF NOT EXISTS imports (
+            id TEXT PRIMARY KEY,
+            tenant_id TEXT NOT NULL,
+            file_name TEXT NOT NULL,
+            folder_id TEXT,
+            status TEXT NOT NULL,
+            imported INTEGER NOT NULL DEFAULT 0,
+            skipped INTEGER NOT NULL DEFAULT 0,
+            failed INTEGER NOT NULL DEFAULT 0,
+            error TEXT,
+            created_by TEXT,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL,
+            completed_at DATETIME
+    );
+	`
+
+	if _, err := db.Exec(createImportsQuery); err != nil {
+		return fmt.Errorf("failed to create imports table: %w", err)
+	}
+
+	createImportResultsQuery := ` CREATE TABLE IF NOT EXISTS import_results (
+            import_id TEXT NOT NULL REFERENCES imports(id),
+            position INTEGER NOT NULL,
+            path TEXT NOT NULL,
+            status TEXT NOT NULL,
+            document_id TEXT,
+            error TEXT,
+            PRIMARY KEY (import_id, position)
+    );
+	`
+
+	if _, err := db.Exec(createImportResultsQuery); err != nil {
+		return fmt.Errorf("failed to create import_results table: %w", err)
+	}
+
+	fmt.Println("Tables 'imports' and 'import_results' created successfully")
+	return nil
+}
+
+func CreateJobsTable(db *sql.DB) error {
+	createJobsQuery := ` CREATE TABLE IF NOT EXISTS jobs (
+            id TEXT PRIMARY KEY,
+            tenant_id TEXT NOT NULL,
+            kind TEXT NOT NULL,
+            status TEXT NOT NULL,
+            params TEXT NOT NULL,
+            principal TEXT,
+            progress INTEGER NOT NULL DEFAULT 0,
+            total INTEGER NOT NULL DEFAULT 0,
+            result TEXT,
+            artifact_key TEXT,
+            artifact_name TEXT,
+            artifact_content_type TEXT,
+            artifact_size INTEGER,
+            error TEXT,
+            attempts INTEGER NOT NULL DEFAULT 0,
+            cancel_requested INTEGER NOT NULL DEFAULT 0,
+            created_by TEXT,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL,
+            started_at DATETIME,
+            completed_at DATETIME
+    );
+    CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs (status, created_at);
+    CREATE INDEX IF NOT EXISTS idx_jobs_tenant ON jobs (tenant_id, created_at);
+	`
+
+	_, err := db.Exec(createJobsQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create jobs table: %w", err)
+	}
+
+	fmt.Println("Table 'jobs' created successfully")
+	return nil
+}
+
+// AddDocumentMissingColumn marks documents whose object storage reconciliation
+// could not find.
+func AddDocumentMissingColumn(db *sql.DB) error {
+	_, err := addColumnIfNotExists(db, "documents", "missing_at", "DATETIME")
+	return err
+}
+
+func CreateStorageMigrationsTables(db *sql.DB) error {
+	createStorageMigrationsQuery := ` CREATE TABLE IF NOT EXISTS storage_migrations (
+            id TEXT PRIMARY KEY,
+            source TEXT NOT NULL,
+            target TEXT NOT NULL,
+            status TEXT NOT NULL,
+            created_by TEXT,
+            created_at DATETIME NOT NULL,
+            updated_at DATETIME NOT NULL,
+            completed_at DATETIME
+    );
+    CREATE TABLE IF NOT EXISTS storage_migration_items (
+            migration_id TEXT NOT NULL REFERENCES storage_migrations(id),
+            object_key TEXT NOT NULL,
+            size INTEGER NOT NULL,
+            status TEXT NOT NULL,
+            checksum TEXT,
+            error TEXT,
+            updated_at DATETIME NOT NULL,
+            PRIMARY KEY (migration_id, object_key)
+    );
+	`
+
+	_, err := db.Exec(createStorageMigrationsQuery)
+	if err != nil {
+		return fmt.Errorf("failed to create storage_migrations tables: %w", err)
+	}
+
+	fmt.Println("Tables 'storage_migrations' and 'storage_migration_items' created successfully")
+	return nil
+}
+
+// AddDocumentScanAttemptColumns counts failed background scans, so that
+// documents the scanner keeps failing on make way for the others and are
+// eventually given up on.
+func AddDocumentScanAttemptColumns(db *sql.DB) error {
+	if _, err := addColumnIfNotExists(db, "documents", "scan_attempts", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	_, err := addColumnIfNotExists(db, "documents", "scan_attempted_at", "DATETIME")
+	return err
+}
+
+func addColumnIfNotExists(db *sql.DB, table, column, definition string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s table info: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			columnType string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return false, fmt.Errorf("failed to scan %s table info: %w", table, err)
+		}
+
+		if name == column {
+			return false, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("failed to iterate %s table info: %w", table, err)
+	}
+
+	alterQuery := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+	if _, err := db.Exec(alterQuery); err != nil {
+		return false, fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+
+	fmt.Printf("Column '%s.%s' added successfully\n", table, column)
+	return true, nil
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"docvault/entity"
+	"time"
+)
+
+type GrantACLRequest struct {
+	Subject    string `json:"subject" binding:"required"`
+	Permission string `json:"permission" binding:"required"`
+}
+
+type ACLEntryResponse struct {
+	ID         string    `json:"id"`
+	DocumentID string    `json:"document_id"`
+	Subject    string    `json:"subject"`
+	Permission string    `json:"permission"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func FromACLEntry(entry *entity.ACLEntry) *ACLEntryResponse {
+	return &ACLEntryResponse{
+		ID:         entry.ID,
+		DocumentID: entry.DocumentID,
+		Subject:    entry.Subject,
+		Permission: entry.Permission,
+		CreatedBy:  entry.CreatedBy,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+func FromACLEntries(entries []*entity.ACLEntry) []*ACLEntryResponse {
+	responses := []*ACLEntryResponse{}
+	for _, entry := range entries {
+		responses = append(responses, FromACLEntry(entry))
+	}
+
+	return responses
+}
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"docvault/entity"
+	"time"
+)
+
+type CreateAPIKeyRequest struct {
+	Name             string   `json:"name" binding:"required"`
+	Permissions      []string `json:"permissions" binding:"required"`
+	ExpiresInSeconds int64    `json:"expires_in_seconds"`
+	// TenantID pins the key to a tenant; omit it for a platform key.
+	TenantID string `json:"tenant_id"`
+}
+
+type APIKeyResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Permissions []string   `json:"permissions"`
+	TenantID    *string    `json:"tenant_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
+
+// CreatedAPIKeyResponse is the only response that carries the secret.
+type CreatedAPIKeyResponse struct {
+	*APIKeyResponse
+	Key string `json:"key"`
+}
+
+func FromAPIKey(key *entity.APIKey) *APIKeyResponse {
+	response := &APIKeyResponse{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Permissions: key.Permissions,
+		CreatedAt:   key.CreatedAt,
+		ExpiresAt:   key.ExpiresAt,
+		LastUsedAt:  key.LastUsedAt,
+		RevokedAt:   key.RevokedAt,
+	}
+	if key.TenantID != "" {
+		response.TenantID = &key.TenantID
+	}
+
+	return response
+}
+
+func FromAPIKeys(keys []*entity.APIKey) []*APIKeyResponse {
+	responses := []*APIKeyResponse{}
+	for _, key := range keys {
+		responses = append(responses, FromAPIKey(key))
+	}
+
+	return responses
+}
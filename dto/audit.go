@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"docvault/entity"
+	"time"
+)
+
+type AuditEntryResponse struct {
+	Seq        int64     `json:"seq"`
+	Timestamp  time.Time `json:"timestamp"`
+	Action     string    `json:"action"`
+	DocumentID string    `json:"document_id"`
+	Actor      string    `json:"actor"`
+	ClientIP   string    `json:"client_ip"`
+	UserAgent  string    `json:"user_agent"`
+	RequestID  string    `json:"request_id"`
+	Outcome    string    `json:"outcome"`
+	Detail     string    `json:"detail"`
+	PrevHash   string    `json:"prev_hash"`
+	Hash       string    `json:"hash"`
+}
+
+type AuditVerificationResponse struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func FromAuditEntry(entry *entity.AuditEntry) *AuditEntryResponse {
+	return &AuditEntryResponse{
+		Seq:        entry.Seq,
+		Timestamp:  entry.Timestamp,
+		Action:     entry.Action,
+		DocumentID: entry.DocumentID,
+		Actor:      entry.Actor,
+		ClientIP:   entry.ClientIP,
+		UserAgent:  entry.UserAgent,
+		RequestID:  entry.RequestID,
+		Outcome:    entry.Outcome,
+		Detail:     entry.Detail,
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
+	}
+}
+
+func FromAuditEntries(entries []*entity.AuditEntry) []*AuditEntryResponse {
+	responses := []*AuditEntryResponse{}
+	for _, entry := range entries {
+		responses = append(responses, FromAuditEntry(entry))
+	}
+
+	return responses
+}
+
+func FromAuditVerification(result *entity.AuditVerification) *AuditVerificationResponse {
+	return &AuditVerificationResponse{
+		Valid:    result.Valid,
+		Checked:  result.Checked,
+		BrokenAt: result.BrokenAt,
+		Reason:   result.Reason,
+	}
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"docvault/entity"
+	"time"
+)
+
+type FolderResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  *string   `json:"parent_id"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type FolderContentsResponse struct {
+	Folder    *FolderResponse     `json:"folder"`
+	Folders   []*FolderResponse   `json:"folders"`
+	Documents []*DocumentResponse `json:"documents"`
+	Page      int                 `json:"page"`
+	PageSize  int                 `json:"page_size"`
+	Total     int64               `json:"total"`
+}
+
+type FolderStatsResponse struct {
+	FolderID      string `json:"folder_id"`
+	FolderCount   int64  `json:"folder_count"`
+	DocumentCount int64  `json:"document_count"`
+	TotalSize     int64  `json:"total_size"`
+}
+
+func FromFolder(folder *entity.Folder) *FolderResponse {
+	return &FolderResponse{
+		ID:        folder.ID,
+		Name:      folder.Name,
+		ParentID:  folder.ParentID,
+		Path:      folder.Path,
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+	}
+}
+
+func FromFolders(folders []*entity.Folder) []*FolderResponse {
+	responses := []*FolderResponse{}
+	for _, folder := range folders {
+		responses = append(responses, FromFolder(folder))
+	}
+
+	return responses
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"docvault/entity"
+	"time"
+)
+
+type PlaceHoldRequest struct {
+	Reason    string `json:"reason" binding:"required"`
+	CreatedBy string `json:"created_by"`
+}
+
+type ReleaseHoldRequest struct {
+	ReleasedBy string `json:"released_by"`
+	Reason     string `json:"reason"`
+}
+
+type LegalHoldResponse struct {
+	ID            string     `json:"id"`
+	DocumentID    string     `json:"document_id"`
+	Reason        string     `json:"reason"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Active        bool       `json:"active"`
+	ReleasedBy    *string    `json:"released_by,omitempty"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty"`
+	ReleaseReason *string    `json:"release_reason,omitempty"`
+}
+
+func FromLegalHold(hold *entity.LegalHold) *LegalHoldResponse {
+	return &LegalHoldResponse{
+		ID:            hold.ID,
+		DocumentID:    hold.DocumentID,
+		Reason:        hold.Reason,
+		CreatedBy:     hold.CreatedBy,
+		CreatedAt:     hold.CreatedAt,
+		Active:        hold.IsActive(),
+		ReleasedBy:    hold.ReleasedBy,
+		ReleasedAt:    hold.ReleasedAt,
+		ReleaseReason: hold.ReleaseReason,
+	}
+}
+
+func FromLegalHolds(holds []*entity.LegalHold) []*LegalHoldResponse {
+	responses := []*LegalHoldResponse{}
+	for _, hold := range holds {
+		responses = append(responses, FromLegalHold(hold))
+	}
+
+	return responses
+}
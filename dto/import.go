@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"docvault/entity"
+	"time"
+)
+
+type ImportResponse struct {
+	ID          string                 `json:"id"`
+	FileName    string                 `json:"file_name"`
+	FolderID    *string                `json:"folder_id"`
+	Status      string                 `json:"status"`
+	Imported    int                    `json:"imported"`
+	Skipped     int                    `json:"skipped"`
+	Failed      int                    `json:"failed"`
+	Error       *string                `json:"error"`
+	Results     []ImportResultResponse `json:"results"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	CompletedAt *time.Time             `json:"completed_at"`
+}
+
+type ImportResultResponse struct {
+	Path       string  `json:"path"`
+	Status     string  `json:"status"`
+	DocumentID *string `json:"document_id"`
+	Error      *string `json:"error"`
+}
+
+func FromImport(imp *entity.Import) *ImportResponse {
+	results := make([]ImportResultResponse, 0, len(imp.Results))
+	for _, result := range imp.Results {
+		results = append(results, ImportResultResponse{
+			Path:       result.Path,
+			Status:     result.Status,
+			DocumentID: result.DocumentID,
+			Error:      result.Error,
+		})
+	}
+
+	return &ImportResponse{
+		ID:          imp.ID,
+		FileName:    imp.FileName,
+		FolderID:    imp.FolderID,
+		Status:      imp.Status,
+		Imported:    imp.Imported,
+		Skipped:     imp.Skipped,
+		Failed:      imp.Failed,
+		Error:       imp.Error,
+		Results:     results,
+		CreatedAt:   imp.CreatedAt,
+		UpdatedAt:   imp.UpdatedAt,
+		CompletedAt: imp.CompletedAt,
+	}
+}
@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"docvault/entity"
+	"encoding/json"
+	"time"
+)
+
+// SubmitJobRequest queues a job; Params depend on the kind.
+type SubmitJobRequest struct {
+	Kind   string          `json:"kind" binding:"required"`
+	Params json.RawMessage `json:"params"`
+}
+
+type JobResponse struct {
+	ID          string               `json:"id"`
+	Kind        string               `json:"kind"`
+	Status      string               `json:"status"`
+	Params      json.RawMessage      `json:"params"`
+	Progress    int64                `json:"progress"`
+	Total       int64                `json:"total"`
+	Result      json.RawMessage      `json:"result"`
+	Artifact    *JobArtifactResponse `json:"artifact"`
+	Error       *string              `json:"error"`
+	Attempts    int                  `json:"attempts"`
+	CreatedBy   *string              `json:"created_by"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+	StartedAt   *time.Time           `json:"started_at"`
+	CompletedAt *time.Time           `json:"completed_at"`
+}
+
+type JobArtifactResponse struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+func FromJob(job *entity.Job) *JobResponse {
+	response := &JobResponse{
+		ID:          job.ID,
+		Kind:        job.Kind,
+		Status:      job.Status,
+		Params:      json.RawMessage(job.Params),
+		Progress:    job.Progress,
+		Total:       job.Total,
+		Error:       job.Error,
+		Attempts:    job.Attempts,
+		CreatedBy:   job.CreatedBy,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
+	}
+	if job.Result != nil {
+		response.Result = json.RawMessage(*job.Result)
+	}
+	if job.ArtifactKey != nil {
+		response.Artifact = &JobArtifactResponse{Name: *job.ArtifactName, ContentType: *job.ArtifactContentType, Size: *job.ArtifactSize}
+	}
+
+	return response
+}
+
+func FromJobs(jobs []*entity.Job) []*JobResponse {
+	responses := make([]*JobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		responses = append(responses, FromJob(job))
+	}
+
+	return responses
+}
@@ -1 +1,77 @@
 package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type UpdateDocumentRequest struct {
+	FileName    *string            `json:"file_name"`
+	ContentType *string            `json:"content_type"`
+	ExpiresAt   NullableTime       `json:"expires_at"`
+	Tags        *[]string          `json:"tags"`
+	Metadata    *map[string]string `json:"metadata"`
+	FolderID    *string            `json:"folder_id"`
+}
+
+// NullableTime tells an explicit null apart from an omitted field, so a PATCH
+// can clear a timestamp without clearing it on every request.
+type NullableTime struct {
+	Set   bool
+	Value *time.Time
+}
+
+func (t *NullableTime) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	if string(data) == "null" {
+		t.Value = nil
+		return nil
+	}
+
+	var value time.Time
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	t.Value = &value
+
+	return nil
+}
+
+type CreateFolderRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID string `json:"parent_id"`
+}
+
+type UpdateFolderRequest struct {
+	Name     *string `json:"name"`
+	ParentID *string `json:"parent_id"`
+}
+
+// ArchiveRequest selects documents for a ZIP download either by ids or by
+// the same filters as listing documents.
+type ArchiveRequest struct {
+	IDs       []string          `json:"ids"`
+	FolderID  *string           `json:"folder_id"`
+	Recursive bool              `json:"recursive"`
+	Tags      []string          `json:"tags"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// BatchRequest selects documents either by ids or by filter. The remaining
+// fields are the parameters of the batch action.
+type BatchRequest struct {
+	IDs    []string     `json:"ids"`
+	Filter *BatchFilter `json:"filter"`
+	DryRun bool         `json:"dry_run"`
+
+	AddTags    []string     `json:"add_tags"`
+	RemoveTags []string     `json:"remove_tags"`
+	ExpiresAt  NullableTime `json:"expires_at"`
+	FolderID   *string      `json:"folder_id"`
+}
+
+type BatchFilter struct {
+	FolderID *string           `json:"folder_id"`
+	Tags     []string          `json:"tags"`
+	Metadata map[string]string `json:"metadata"`
+}
@@ -6,12 +6,29 @@ import (
 )
 
 type DocumentResponse struct {
-	ID          string     `json:"id"`
-	FileName    string     `json:"file_name"`
-	FileSize    int64      `json:"file_size"`
-	ContentType string     `json:"content_type"`
-	CreatedAt   time.Time  `json:"created_at"`
-	ExpiresAt   *time.Time `json:"expires_at"`
+	ID          string            `json:"id"`
+	FileName    string            `json:"file_name"`
+	FileSize    int64             `json:"file_size"`
+	ContentType string            `json:"content_type"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ExpiresAt   *time.Time        `json:"expires_at"`
+	DeletedAt   *time.Time        `json:"deleted_at,omitempty"`
+	Tags        []string          `json:"tags"`
+	Metadata    map[string]string `json:"metadata"`
+	FolderID    *string           `json:"folder_id"`
+	Version     int64             `json:"version"`
+
+	RetentionPolicyID *string `json:"retention_policy_id"`
+	OwnerID           *string `json:"owner_id"`
+
+	DeclaredContentType string `json:"declared_content_type"`
+	DetectedContentType string `json:"detected_content_type"`
+
+	ScanStatus string     `json:"scan_status"`
+	ScanResult *string    `json:"scan_result"`
+	ScannedAt  *time.Time `json:"scanned_at"`
+
+	MissingAt *time.Time `json:"missing_at,omitempty"`
 }
 
 func FromEntity(doc *entity.Document) *DocumentResponse {
@@ -22,5 +39,79 @@ func FromEntity(doc *entity.Document) *DocumentResponse {
 		ContentType: doc.ContentType,
 		CreatedAt:   doc.CreatedAt,
 		ExpiresAt:   doc.ExpiresAt,
+		DeletedAt:   doc.DeletedAt,
+		Tags:        doc.Tags,
+		Metadata:    doc.Metadata,
+		FolderID:    doc.FolderID,
+		Version:     doc.Version,
+
+		RetentionPolicyID: doc.RetentionPolicyID,
+		OwnerID:           doc.OwnerID,
+
+		DeclaredContentType: doc.DeclaredContentType,
+		DetectedContentType: doc.DetectedContentType,
+
+		ScanStatus: doc.ScanStatus,
+		ScanResult: doc.ScanResult,
+		ScannedAt:  doc.ScannedAt,
+
+		MissingAt: doc.MissingAt,
+	}
+}
+
+func FromEntities(docs []*entity.Document) []*DocumentResponse {
+	responses := []*DocumentResponse{}
+	for _, doc := range docs {
+		responses = append(responses, FromEntity(doc))
 	}
+
+	return responses
+}
+
+type BatchResponse struct {
+	Action    string                `json:"action"`
+	DryRun    bool                  `json:"dry_run"`
+	Changed   int                   `json:"changed"`
+	Unchanged int                   `json:"unchanged"`
+	Failed    int                   `json:"failed"`
+	Results   []BatchResultResponse `json:"results"`
+}
+
+type BatchResultResponse struct {
+	DocumentID string   `json:"document_id"`
+	Status     string   `json:"status"`
+	Changes    []string `json:"changes,omitempty"`
+	Error      *string  `json:"error,omitempty"`
+	// Code is the HTTP status the failure would have had as a single request.
+	Code int `json:"code,omitempty"`
+}
+
+type ReconcileRequest struct {
+	Repair bool `json:"repair"`
+}
+
+type ReconcileResponse struct {
+	Repair    bool                       `json:"repair"`
+	Objects   int                        `json:"objects"`
+	Documents int                        `json:"documents"`
+	Orphans   []ReconcileOrphanResponse  `json:"orphans"`
+	Missing   []ReconcileMissingResponse `json:"missing"`
+	Found     []string                   `json:"found"`
+}
+
+type ReconcileOrphanResponse struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	Deleted      bool      `json:"deleted"`
+	Error        *string   `json:"error,omitempty"`
+}
+
+type ReconcileMissingResponse struct {
+	DocumentID string  `json:"document_id"`
+	TenantID   string  `json:"tenant_id"`
+	FileName   string  `json:"file_name"`
+	Key        string  `json:"key"`
+	Marked     bool    `json:"marked"`
+	Error      *string `json:"error,omitempty"`
 }
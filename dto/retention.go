@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"docvault/entity"
+	"time"
+)
+
+// Durations are exchanged as whole seconds, matching expires_in on upload.
+type RetentionPolicyRequest struct {
+	Name                string `json:"name" binding:"required"`
+	ContentType         string `json:"content_type"`
+	FolderID            string `json:"folder_id"`
+	DefaultTTLSeconds   *int64 `json:"default_ttl_seconds"`
+	MinRetentionSeconds *int64 `json:"min_retention_seconds"`
+	MaxRetentionSeconds *int64 `json:"max_retention_seconds"`
+	NeverExpire         bool   `json:"never_expire"`
+	Priority            int    `json:"priority"`
+}
+
+type RetentionPolicyResponse struct {
+	ID                  string    `json:"id"`
+	Name                string    `json:"name"`
+	ContentType         string    `json:"content_type"`
+	FolderID            *string   `json:"folder_id"`
+	DefaultTTLSeconds   *int64    `json:"default_ttl_seconds"`
+	MinRetentionSeconds *int64    `json:"min_retention_seconds"`
+	MaxRetentionSeconds *int64    `json:"max_retention_seconds"`
+	NeverExpire         bool      `json:"never_expire"`
+	Priority            int       `json:"priority"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+func FromRetentionPolicy(policy *entity.RetentionPolicy) *RetentionPolicyResponse {
+	return &RetentionPolicyResponse{
+		ID:                  policy.ID,
+		Name:                policy.Name,
+		ContentType:         policy.ContentType,
+		FolderID:            policy.FolderID,
+		DefaultTTLSeconds:   toSeconds(policy.DefaultTTL),
+		MinRetentionSeconds: toSeconds(policy.MinRetention),
+		MaxRetentionSeconds: toSeconds(policy.MaxRetention),
+		NeverExpire:         policy.NeverExpire,
+		Priority:            policy.Priority,
+		CreatedAt:           policy.CreatedAt,
+		UpdatedAt:           policy.UpdatedAt,
+	}
+}
+
+func FromRetentionPolicies(policies []*entity.RetentionPolicy) []*RetentionPolicyResponse {
+	responses := []*RetentionPolicyResponse{}
+	for _, policy := range policies {
+		responses = append(responses, FromRetentionPolicy(policy))
+	}
+
+	return responses
+}
+
+func FromSeconds(seconds *int64) *time.Duration {
+	if seconds == nil {
+		return nil
+	}
+
+	duration := time.Duration(*seconds) * time.Second
+	return &duration
+}
+
+func toSeconds(duration *time.Duration) *int64 {
+	if duration == nil {
+		return nil
+	}
+
+	seconds := int64(duration.Seconds())
+	return &seconds
+}
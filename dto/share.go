@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"docvault/entity"
+	"time"
+)
+
+type CreateShareRequest struct {
+	ExpiresInSeconds int    `json:"expires_in_seconds"`
+	Password         string `json:"password"`
+	MaxDownloads     int64  `json:"max_downloads"`
+}
+
+type ShareLinkResponse struct {
+	ID                string     `json:"id"`
+	DocumentID        string     `json:"document_id"`
+	PasswordProtected bool       `json:"password_protected"`
+	ExpiresAt         *time.Time `json:"expires_at"`
+	MaxDownloads      *int64     `json:"max_downloads"`
+	DownloadCount     int64      `json:"download_count"`
+	Active            bool       `json:"active"`
+	CreatedBy         string     `json:"created_by"`
+	CreatedAt         time.Time  `json:"created_at"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	LastUsedAt        *time.Time `json:"last_used_at"`
+}
+
+// CreatedShareLinkResponse is the only response that carries the token.
+type CreatedShareLinkResponse struct {
+	*ShareLinkResponse
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+func FromShareLink(link *entity.ShareLink) *ShareLinkResponse {
+	return &ShareLinkResponse{
+		ID:                link.ID,
+		DocumentID:        link.DocumentID,
+		PasswordProtected: link.HasPassword(),
+		ExpiresAt:         link.ExpiresAt,
+		MaxDownloads:      link.MaxDownloads,
+		DownloadCount:     link.DownloadCount,
+		Active:            link.IsActive(time.Now()),
+		CreatedBy:         link.CreatedBy,
+		CreatedAt:         link.CreatedAt,
+		RevokedAt:         link.RevokedAt,
+		LastUsedAt:        link.LastUsedAt,
+	}
+}
+
+func FromShareLinks(links []*entity.ShareLink) []*ShareLinkResponse {
+	responses := []*ShareLinkResponse{}
+	for _, link := range links {
+		responses = append(responses, FromShareLink(link))
+	}
+
+	return responses
+}
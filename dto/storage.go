@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type StorageStatusResponse struct {
+	Active    string                    `json:"active"`
+	Migration *StorageMigrationResponse `json:"migration"`
+}
+
+// StorageMigrationResponse counts the objects by outcome and lists the ones
+// that failed to copy, which the next run of the migration retries.
+type StorageMigrationResponse struct {
+	ID          string                         `json:"id"`
+	Source      string                         `json:"source"`
+	Target      string                         `json:"target"`
+	Status      string                         `json:"status"`
+	Items       map[string]int                 `json:"items"`
+	Failed      []StorageMigrationItemResponse `json:"failed"`
+	CreatedBy   *string                        `json:"created_by"`
+	CreatedAt   time.Time                      `json:"created_at"`
+	UpdatedAt   time.Time                      `json:"updated_at"`
+	CompletedAt *time.Time                     `json:"completed_at"`
+}
+
+type StorageMigrationItemResponse struct {
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	Error     *string   `json:"error"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"docvault/entity"
+	"time"
+)
+
+type TenantRequest struct {
+	// ID is only read on create; tenant ids cannot change.
+	ID                      string `json:"id"`
+	Name                    string `json:"name" binding:"required"`
+	QuotaBytes              *int64 `json:"quota_bytes"`
+	QuotaDocuments          *int64 `json:"quota_documents"`
+	UserQuotaBytes          *int64 `json:"user_quota_bytes"`
+	UserQuotaDocuments      *int64 `json:"user_quota_documents"`
+	DefaultRetentionSeconds *int64 `json:"default_retention_seconds"`
+}
+
+type TenantResponse struct {
+	ID                      string    `json:"id"`
+	Name                    string    `json:"name"`
+	QuotaBytes              *int64    `json:"quota_bytes"`
+	QuotaDocuments          *int64    `json:"quota_documents"`
+	UserQuotaBytes          *int64    `json:"user_quota_bytes"`
+	UserQuotaDocuments      *int64    `json:"user_quota_documents"`
+	DefaultRetentionSeconds *int64    `json:"default_retention_seconds"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+func FromTenant(tenant *entity.Tenant) *TenantResponse {
+	return &TenantResponse{
+		ID:                      tenant.ID,
+		Name:                    tenant.Name,
+		QuotaBytes:              tenant.QuotaBytes,
+		QuotaDocuments:          tenant.QuotaDocuments,
+		UserQuotaBytes:          tenant.UserQuotaBytes,
+		UserQuotaDocuments:      tenant.UserQuotaDocuments,
+		DefaultRetentionSeconds: toSeconds(tenant.DefaultRetention),
+		CreatedAt:               tenant.CreatedAt,
+		UpdatedAt:               tenant.UpdatedAt,
+	}
+}
+
+func FromTenants(tenants []*entity.Tenant) []*TenantResponse {
+	responses := []*TenantResponse{}
+	for _, tenant := range tenants {
+		responses = append(responses, FromTenant(tenant))
+	}
+
+	return responses
+}
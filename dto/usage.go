@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"docvault/entity"
+	"time"
+)
+
+type UsageResponse struct {
+	SubjectID    string    `json:"subject_id"`
+	Bytes        int64     `json:"bytes"`
+	Documents    int64     `json:"documents"`
+	MaxBytes     *int64    `json:"max_bytes"`
+	MaxDocuments *int64    `json:"max_documents"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type UsageReportResponse struct {
+	Tenant *UsageResponse `json:"tenant"`
+	User   *UsageResponse `json:"user,omitempty"`
+}
+
+type QuotaErrorResponse struct {
+	Scope     string `json:"scope"`
+	SubjectID string `json:"subject_id"`
+	Resource  string `json:"resource"`
+	Limit     int64  `json:"limit"`
+	Used      int64  `json:"used"`
+	Requested int64  `json:"requested"`
+}
+
+func FromUsage(usage *entity.Usage, quota entity.Quota) *UsageResponse {
+	return &UsageResponse{
+		SubjectID:    usage.SubjectID,
+		Bytes:        usage.Bytes,
+		Documents:    usage.Documents,
+		MaxBytes:     quota.MaxBytes,
+		MaxDocuments: quota.MaxDocuments,
+		UpdatedAt:    usage.UpdatedAt,
+	}
+}
+
+func FromQuotaError(err *entity.QuotaError) *QuotaErrorResponse {
+	return &QuotaErrorResponse{
+		Scope:     err.Quota.Scope,
+		SubjectID: err.Quota.SubjectID,
+		Resource:  err.Resource,
+		Limit:     err.Limit,
+		Used:      err.Used,
+		Requested: err.Requested,
+	}
+}
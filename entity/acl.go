@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+const (
+	ACLPermissionRead   = "read"
+	ACLPermissionWrite  = "write"
+	ACLPermissionDelete = "delete"
+)
+
+var ACLPermissions = []string{ACLPermissionRead, ACLPermissionWrite, ACLPermissionDelete}
+
+const SubjectGroupPrefix = "group:"
+
+// ACLEntry grants one permission on a document to a subject: a principal ID
+// such as "user:<sub>" or "api_key:<id>", or a group as "group:<name>".
+type ACLEntry struct {
+	ID         string
+	DocumentID string
+	Subject    string
+	Permission string
+	CreatedBy  string
+	CreatedAt  time.Time
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// APIKey stores only a hash of the secret. Prefix is the public part of the
+// key used to look it up.
+type APIKey struct {
+	ID          string
+	Name        string
+	Prefix      string
+	SecretHash  string
+	Permissions []string
+	CreatedAt   time.Time
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+	// TenantID is empty for platform keys.
+	TenantID string
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+func (k *APIKey) Principal() *Principal {
+	return &Principal{
+		ID:          PrincipalTypeAPIKey + ":" + k.ID,
+		Name:        k.Name,
+		Type:        PrincipalTypeAPIKey,
+		Permissions: k.Permissions,
+		TenantID:    k.TenantID,
+	}
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditActionUpload      = "upload"
+	AuditActionDownload    = "download"
+	AuditActionRead        = "read"
+	AuditActionUpdate      = "update"
+	AuditActionDelete      = "delete"
+	AuditActionExpire      = "expire"
+	AuditActionRestore     = "restore"
+	AuditActionPurge       = "purge"
+	AuditActionHoldPlace   = "hold.place"
+	AuditActionHoldRelease = "hold.release"
+	AuditActionACLGrant    = "acl.grant"
+	AuditActionACLRevoke   = "acl.revoke"
+	AuditActionShareCreate = "share.create"
+	AuditActionShareRevoke = "share.revoke"
+	AuditActionShareUse    = "share.download"
+	AuditActionQuarantine  = "quarantine"
+
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEntry is one record of the append-only audit log. Each entry's Hash
+// covers its own fields and the previous entry's hash, so editing or removing
+// any entry breaks the chain from that point on.
+type AuditEntry struct {
+	Seq        int64
+	Timestamp  time.Time
+	Action     string
+	DocumentID string
+	Actor      string
+	ClientIP   string
+	UserAgent  string
+	RequestID  string
+	Outcome    string
+	Detail     string
+	PrevHash   string
+	Hash       string
+}
+
+type AuditFilter struct {
+	DocumentID string
+	Actor      string
+	Action     string
+	Outcome    string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+type AuditVerification struct {
+	Valid   bool
+	Checked int64
+	// BrokenAt is the sequence number of the first entry that fails verification.
+	BrokenAt *int64
+	Reason   string
+}
+
+// ComputeHash hashes the entry's content together with PrevHash. Seq is left
+// out because it is assigned by the database after hashing.
+func (e *AuditEntry) ComputeHash() string {
+	content, _ := json.Marshal([]string{
+		e.PrevHash,
+		e.Timestamp.UTC().Format(time.RFC3339Nano),
+		e.Action,
+		e.DocumentID,
+		e.Actor,
+		e.ClientIP,
+		e.UserAgent,
+		e.RequestID,
+		e.Outcome,
+		e.Detail,
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
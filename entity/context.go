@@ -0,0 +1,22 @@
+package entity
+
+import "context"
+
+// RequestInfo describes the HTTP request an operation runs on behalf of.
+// Background work such as the scheduler runs without one.
+type RequestInfo struct {
+	RequestID string
+	ClientIP  string
+	UserAgent string
+}
+
+type requestInfoKey struct{}
+
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
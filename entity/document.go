@@ -2,6 +2,15 @@ package entity
 
 import "time"
 
+const (
+	ScanStatusPending  = "pending"
+	ScanStatusClean    = "clean"
+	ScanStatusInfected = "infected"
+	// ScanStatusError is given to documents the background scan failed on
+	// too often; they stay blocked like pending ones.
+	ScanStatusError = "error"
+)
+
 type Document struct {
 	ID          string
 	FileName    string
@@ -9,4 +18,64 @@ type Document struct {
 	CreatedAt   time.Time
 	ExpiresAt   *time.Time
 	ContentType string
+	DeletedAt   *time.Time
+	Tags        []string
+	Metadata    map[string]string
+	FolderID    *string
+	StorageKey  string
+	Version     int64
+
+	// DeclaredContentType is what the client sent and DetectedContentType what
+	// the content's magic bytes show; ContentType is the type served back.
+	DeclaredContentType string
+	DetectedContentType string
+
+	// RequestedExpiresAt and RetainForever record what the uploader asked for;
+	// ExpiresAt is the effective expiry once retention policies are applied.
+	RequestedExpiresAt *time.Time
+	RetainForever      bool
+	RetentionPolicyID  *string
+
+	TenantID string
+
+	// OwnerID is the principal ID of the uploader; documents uploaded before
+	// ownership was recorded have none and stay visible to every caller.
+	OwnerID *string
+
+	// ScanStatus is pending until a malware scan has passed; infected documents
+	// are moved under the quarantine prefix and ScanResult names the signature.
+	ScanStatus string
+	ScanResult *string
+	ScannedAt  *time.Time
+
+	// MissingAt is when reconciliation found the document's object missing
+	// from storage; such documents cannot be downloaded.
+	MissingAt *time.Time
+}
+
+func (d *Document) IsTrashed() bool {
+	return d.DeletedAt != nil
+}
+
+// ObjectKey is the key of the document's object in storage.
+func (d *Document) ObjectKey() string {
+	return d.StorageKey
+}
+
+type DocumentFilter struct {
+	Tags     []string
+	Metadata map[string]string
+
+	// FolderID limits results to a single folder; a pointer to "" selects the root.
+	FolderID *string
+	// FolderPath limits results to a folder subtree by materialized path.
+	FolderPath string
+	// Subjects limits results to documents owned by, or shared with, one of
+	// these ACL subjects; nil applies no restriction.
+	Subjects []string
+	// TenantID limits results to one tenant; "" spans every tenant.
+	TenantID string
+
+	Limit  int
+	Offset int
 }
@@ -0,0 +1,55 @@
+package entity
+
+import "errors"
+
+var (
+	ErrDocumentNotFound   = errors.New("document not found")
+	ErrDocumentNotTrashed = errors.New("document is not in trash")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrVersionConflict    = errors.New("document version does not match")
+
+	ErrFolderNotFound = errors.New("folder not found")
+	ErrFolderNotEmpty = errors.New("folder is not empty")
+	ErrFolderExists   = errors.New("folder with this name already exists")
+
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	ErrRetentionPolicyExists   = errors.New("retention policy with this name already exists")
+
+	ErrLegalHoldNotFound = errors.New("legal hold not found")
+	ErrLegalHoldReleased = errors.New("legal hold is already released")
+	ErrDocumentOnHold    = errors.New("document is under legal hold")
+
+	ErrUnauthorized   = errors.New("authentication required")
+	ErrForbidden      = errors.New("permission denied")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	ErrACLEntryNotFound = errors.New("acl entry not found")
+
+	ErrShareLinkNotFound    = errors.New("share link not found")
+	ErrShareLinkUnavailable = errors.New("share link is expired, revoked or used up")
+
+	ErrTenantNotFound = errors.New("tenant not found")
+	ErrTenantExists   = errors.New("tenant already exists")
+	ErrTenantNotEmpty = errors.New("tenant still has documents or folders")
+	ErrQuotaExceeded  = errors.New("storage quota exceeded")
+
+	ErrUnsupportedContent = errors.New("file type is not allowed")
+	ErrFileTooLarge       = errors.New("file is too large for its type")
+
+	ErrThumbnailNotFound = errors.New("thumbnail not found")
+
+	ErrScanPending      = errors.New("document has not passed a malware scan yet")
+	ErrScanFailed       = errors.New("document could not be scanned for malware")
+	ErrDocumentInfected = errors.New("document is quarantined as infected")
+	ErrDocumentMissing  = errors.New("document content is missing from storage")
+
+	ErrArchiveTooLarge = errors.New("archive is too large")
+	ErrImportNotFound  = errors.New("import not found")
+
+	ErrJobNotFound   = errors.New("job not found")
+	ErrJobFinished   = errors.New("job has already finished")
+	ErrJobCanceled   = errors.New("job was canceled")
+	ErrNoJobArtifact = errors.New("job has no artifact")
+
+	ErrStorageMigrationNotFound = errors.New("storage migration not found")
+)
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type Folder struct {
+	ID        string
+	Name      string
+	ParentID  *string
+	Path      string
+	TenantID  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type FolderStats struct {
+	FolderCount   int64
+	DocumentCount int64
+	TotalSize     int64
+}
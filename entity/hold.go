@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// LegalHold blocks expiry and deletion of a document until it is released.
+// Released holds are kept so the history of a document's holds stays intact.
+type LegalHold struct {
+	ID            string
+	DocumentID    string
+	Reason        string
+	CreatedBy     string
+	CreatedAt     time.Time
+	ReleasedBy    *string
+	ReleasedAt    *time.Time
+	ReleaseReason *string
+}
+
+func (h *LegalHold) IsActive() bool {
+	return h.ReleasedAt == nil
+}
@@ -0,0 +1,56 @@
+package entity
+
+import "time"
+
+const (
+	ImportStatusQueued    = "queued"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+	ImportStatusCanceled  = "canceled"
+)
+
+const (
+	ImportEntryImported = "imported"
+	ImportEntrySkipped  = "skipped"
+	ImportEntryFailed   = "failed"
+)
+
+// Import unpacks a zip or tar archive into documents. Results holds one entry
+// per file in the archive, in archive order.
+type Import struct {
+	ID          string
+	TenantID    string
+	FileName    string
+	FolderID    *string
+	Status      string
+	Imported    int
+	Skipped     int
+	Failed      int
+	Results     []ImportResult
+	Error       *string
+	CreatedBy   *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+type ImportResult struct {
+	Path       string
+	Status     string
+	DocumentID *string
+	Error      *string
+}
+
+// Add records result and counts it.
+func (i *Import) Add(result ImportResult) {
+	i.Results = append(i.Results, result)
+	switch result.Status {
+	case ImportEntryImported:
+		i.Imported++
+	case ImportEntrySkipped:
+		i.Skipped++
+	default:
+		i.Failed++
+	}
+}
@@ -0,0 +1,54 @@
+package entity
+
+import "time"
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCanceled  = "canceled"
+)
+
+// Job is a long-running operation run by the job workers on behalf of the
+// principal that submitted it. Params is the kind's JSON parameters.
+type Job struct {
+	ID        string
+	TenantID  string
+	Kind      string
+	Status    string
+	Params    string
+	Principal *Principal
+	// Progress counts units of work done out of Total, where Total is 0 until
+	// the job knows how much there is to do.
+	Progress int64
+	Total    int64
+	// Result is the JSON outcome of a succeeded job.
+	Result *string
+	// Artifact names an object the job stored as its output.
+	ArtifactKey         *string
+	ArtifactName        *string
+	ArtifactContentType *string
+	ArtifactSize        *int64
+	Error               *string
+	Attempts            int
+	CancelRequested     bool
+	CreatedBy           *string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	StartedAt           *time.Time
+	CompletedAt         *time.Time
+}
+
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed || j.Status == JobStatusCanceled
+}
+
+type JobFilter struct {
+	TenantID  string
+	CreatedBy *string
+	Kind      string
+	Status    string
+	Limit     int
+	Offset    int
+}
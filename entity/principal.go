@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"context"
+	"slices"
+)
+
+const (
+	PermissionDocumentsRead   = "documents:read"
+	PermissionDocumentsWrite  = "documents:write"
+	PermissionDocumentsDelete = "documents:delete"
+	PermissionAdmin           = "admin"
+)
+
+var Permissions = []string{
+	PermissionDocumentsRead,
+	PermissionDocumentsWrite,
+	PermissionDocumentsDelete,
+	PermissionAdmin,
+}
+
+const (
+	PrincipalTypeAPIKey = "api_key"
+	PrincipalTypeUser   = "user"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// ID identifies the caller in audit entries, e.g. "api_key:<id>" or "user:<sub>".
+	ID          string
+	Name        string
+	Type        string
+	Roles       []string
+	Groups      []string
+	Permissions []string
+	// TenantID pins the principal to one tenant; platform principals have none
+	// and may act on any tenant.
+	TenantID string
+}
+
+func (p *Principal) IsPlatform() bool {
+	return p != nil && p.TenantID == ""
+}
+
+// Can reports whether the principal holds permission. Admin implies every permission.
+func (p *Principal) Can(permission string) bool {
+	if p == nil {
+		return false
+	}
+
+	return slices.Contains(p.Permissions, PermissionAdmin) || slices.Contains(p.Permissions, permission)
+}
+
+// Subjects lists the ACL subjects the principal acts as: itself and its groups.
+func (p *Principal) Subjects() []string {
+	subjects := []string{p.ID}
+	for _, group := range p.Groups {
+		subjects = append(subjects, SubjectGroupPrefix+group)
+	}
+
+	return subjects
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+type RetentionPolicy struct {
+	ID           string
+	Name         string
+	ContentType  string
+	FolderID     *string
+	DefaultTTL   *time.Duration
+	MinRetention *time.Duration
+	MaxRetention *time.Duration
+	NeverExpire  bool
+	Priority     int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Matches reports whether the policy governs doc. An empty ContentType matches
+// any type and a "type/*" pattern matches every subtype.
+func (p *RetentionPolicy) Matches(doc *Document) bool {
+	if p.FolderID != nil && (doc.FolderID == nil || *doc.FolderID != *p.FolderID) {
+		return false
+	}
+
+	if p.ContentType == "" {
+		return true
+	}
+
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(doc.ContentType, ";")[0]))
+	if prefix, ok := strings.CutSuffix(p.ContentType, "/*"); ok {
+		return strings.HasPrefix(contentType, prefix+"/")
+	}
+
+	return contentType == p.ContentType
+}
+
+func (p *RetentionPolicy) specificity() int {
+	score := 0
+	if p.FolderID != nil {
+		score += 4
+	}
+	switch {
+	case p.ContentType == "":
+	case strings.HasSuffix(p.ContentType, "/*"):
+		score += 1
+	default:
+		score += 2
+	}
+
+	return score
+}
+
+// ExpiresAt computes the effective expiry of doc under the policy, starting
+// from what the uploader asked for and clamping it to the retention bounds.
+func (p *RetentionPolicy) ExpiresAt(doc *Document) *time.Time {
+	if p != nil && p.NeverExpire {
+		return nil
+	}
+
+	var expiresAt *time.Time
+	switch {
+	case doc.RetainForever:
+	case doc.RequestedExpiresAt != nil:
+		requested := *doc.RequestedExpiresAt
+		expiresAt = &requested
+	case p != nil && p.DefaultTTL != nil:
+		defaultExpiry := doc.CreatedAt.Add(*p.DefaultTTL)
+		expiresAt = &defaultExpiry
+	}
+
+	if p == nil {
+		return expiresAt
+	}
+
+	if p.MinRetention != nil && expiresAt != nil {
+		if earliest := doc.CreatedAt.Add(*p.MinRetention); expiresAt.Before(earliest) {
+			expiresAt = &earliest
+		}
+	}
+
+	if p.MaxRetention != nil {
+		if latest := doc.CreatedAt.Add(*p.MaxRetention); expiresAt == nil || expiresAt.After(latest) {
+			expiresAt = &latest
+		}
+	}
+
+	return expiresAt
+}
+
+// SelectRetentionPolicy picks the policy that governs doc: the highest
+// priority match, then the most specific one, then the first by name.
+func SelectRetentionPolicy(policies []*RetentionPolicy, doc *Document) *RetentionPolicy {
+	var selected *RetentionPolicy
+	for _, policy := range policies {
+		if !policy.Matches(doc) {
+			continue
+		}
+
+		if selected == nil ||
+			policy.Priority > selected.Priority ||
+			(policy.Priority == selected.Priority && policy.specificity() > selected.specificity()) ||
+			(policy.Priority == selected.Priority && policy.specificity() == selected.specificity() && policy.Name < selected.Name) {
+			selected = policy
+		}
+	}
+
+	return selected
+}
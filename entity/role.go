@@ -0,0 +1,32 @@
+package entity
+
+import "slices"
+
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// RolePermissions maps the roles carried in bearer tokens to permissions.
+var RolePermissions = map[string][]string{
+	RoleViewer: {PermissionDocumentsRead},
+	RoleEditor: {PermissionDocumentsRead, PermissionDocumentsWrite, PermissionDocumentsDelete},
+	RoleAdmin:  {PermissionAdmin},
+}
+
+// PermissionsForRoles returns the sorted union of permissions granted by
+// roles. Unknown roles grant nothing.
+func PermissionsForRoles(roles []string) []string {
+	permissions := []string{}
+	for _, role := range roles {
+		for _, permission := range RolePermissions[role] {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+
+	slices.Sort(permissions)
+	return permissions
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// ShareLink grants unauthenticated download of one document to whoever holds
+// its token. Only a SHA-256 hash of the token is stored.
+type ShareLink struct {
+	ID            string
+	DocumentID    string
+	TokenHash     string
+	PasswordHash  *string
+	ExpiresAt     *time.Time
+	MaxDownloads  *int64
+	DownloadCount int64
+	CreatedBy     string
+	CreatedAt     time.Time
+	RevokedAt     *time.Time
+	LastUsedAt    *time.Time
+}
+
+func (l *ShareLink) HasPassword() bool {
+	return l.PasswordHash != nil
+}
+
+// IsActive reports whether the link can still be used at now.
+func (l *ShareLink) IsActive(now time.Time) bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+
+	if l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return false
+	}
+
+	return l.MaxDownloads == nil || l.DownloadCount < *l.MaxDownloads
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+const (
+	StorageMigrationCopying   = "copying"
+	StorageMigrationCompleted = "completed"
+)
+
+const (
+	StorageMigrationItemCopied = "copied"
+	// StorageMigrationItemPresent is an object the target already had, such
+	// as one written there since the migration began; it is left alone.
+	StorageMigrationItemPresent = "present"
+	StorageMigrationItemFailed  = "failed"
+)
+
+// StorageMigration moves every object from the Source backend to Target,
+// both given as storage URLs such as minio://bucket or file:///srv/docvault.
+type StorageMigration struct {
+	ID          string
+	Source      string
+	Target      string
+	Status      string
+	CreatedBy   *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// StorageMigrationItem is the outcome of copying one object; Checksum is the
+// hex SHA-256 both copies were verified to have.
+type StorageMigrationItem struct {
+	MigrationID string
+	Key         string
+	Size        int64
+	Status      string
+	Checksum    *string
+	Error       *string
+	UpdatedAt   time.Time
+}
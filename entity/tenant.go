@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultTenantID owns every document created before tenants existed and is
+// used when a platform caller does not pick a tenant.
+const DefaultTenantID = "default"
+
+type Tenant struct {
+	ID   string
+	Name string
+	// QuotaBytes and QuotaDocuments cap what the tenant stores, trash
+	// included; nil is unlimited.
+	QuotaBytes     *int64
+	QuotaDocuments *int64
+	// UserQuotaBytes and UserQuotaDocuments cap what each owner stores within
+	// the tenant.
+	UserQuotaBytes     *int64
+	UserQuotaDocuments *int64
+	// DefaultRetention applies to documents no retention policy governs; nil keeps them.
+	DefaultRetention *time.Duration
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Quotas lists the limits an upload by ownerID must respect; ownerID is nil
+// for anonymous uploads.
+func (t *Tenant) Quotas(ownerID *string) []Quota {
+	quotas := []Quota{{Scope: UsageScopeTenant, SubjectID: t.ID, MaxBytes: t.QuotaBytes, MaxDocuments: t.QuotaDocuments}}
+	if ownerID != nil {
+		quotas = append(quotas, Quota{Scope: UsageScopeUser, SubjectID: *ownerID, MaxBytes: t.UserQuotaBytes, MaxDocuments: t.UserQuotaDocuments})
+	}
+
+	return quotas
+}
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant a request is scoped to. Internal work
+// such as the scheduler runs without one and spans every tenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
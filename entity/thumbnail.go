@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// ThumbnailContentTypes are the image types thumbnails are generated for.
+var ThumbnailContentTypes = []string{"image/png", "image/jpeg", "image/gif"}
+
+// Thumbnail is a downscaled copy of an image document, stored as a derived
+// object. Size is the bounding box it was rendered to fit in.
+type Thumbnail struct {
+	DocumentID  string
+	Size        int
+	StorageKey  string
+	ContentType string
+	Width       int
+	Height      int
+	FileSize    int64
+	CreatedAt   time.Time
+}
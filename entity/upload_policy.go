@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ContentMismatchReject refuses uploads whose declared type contradicts
+	// the type detected from their content.
+	ContentMismatchReject = "reject"
+	// ContentMismatchCorrect stores such uploads under the detected type.
+	ContentMismatchCorrect = "correct"
+	// ContentMismatchAllow keeps the declared type; it is the zero value.
+	ContentMismatchAllow = "allow"
+)
+
+// UploadPolicy restricts which files may be uploaded. Type patterns are MIME
+// types, optionally with a "*" subtype such as "image/*"; extensions include
+// the leading dot. Empty allow lists allow everything not denied.
+type UploadPolicy struct {
+	AllowedTypes      []string
+	DeniedTypes       []string
+	AllowedExtensions []string
+	DeniedExtensions  []string
+	// SizeLimits maps type patterns to a maximum size in bytes; the smallest
+	// matching limit applies.
+	SizeLimits map[string]int64
+	OnMismatch string
+}
+
+// AllowsType reports whether mediaType passes the allow and deny lists.
+func (p UploadPolicy) AllowsType(mediaType string) bool {
+	if matchesAnyType(p.DeniedTypes, mediaType) {
+		return false
+	}
+
+	return len(p.AllowedTypes) == 0 || matchesAnyType(p.AllowedTypes, mediaType)
+}
+
+// AllowsExtension reports whether fileName's extension passes the allow and
+// deny lists. Files without an extension only pass an empty allow list.
+func (p UploadPolicy) AllowsExtension(fileName string) bool {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	for _, denied := range p.DeniedExtensions {
+		if strings.EqualFold(denied, ext) {
+			return false
+		}
+	}
+
+	if len(p.AllowedExtensions) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedExtensions {
+		if strings.EqualFold(allowed, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SizeLimit returns the size limit for mediaType, if any applies.
+func (p UploadPolicy) SizeLimit(mediaType string) (int64, bool) {
+	limit, found := int64(0), false
+	for pattern, max := range p.SizeLimits {
+		if MatchesMediaType(pattern, mediaType) && (!found || max < limit) {
+			limit, found = max, true
+		}
+	}
+
+	return limit, found
+}
+
+// MatchesMediaType reports whether mediaType matches pattern, which may be
+// "*/*" or "type/*".
+func MatchesMediaType(pattern, mediaType string) bool {
+	pattern, mediaType = strings.ToLower(pattern), strings.ToLower(mediaType)
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(pattern, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+func matchesAnyType(patterns []string, mediaType string) bool {
+	for _, pattern := range patterns {
+		if MatchesMediaType(pattern, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	UsageScopeTenant = "tenant"
+	UsageScopeUser   = "user"
+)
+
+// Usage is the running total stored by a tenant or by a document owner.
+// Trashed documents count until they are purged.
+type Usage struct {
+	Scope     string
+	SubjectID string
+	Bytes     int64
+	Documents int64
+	UpdatedAt time.Time
+}
+
+// Quota caps a usage total; nil limits are unlimited.
+type Quota struct {
+	Scope        string
+	SubjectID    string
+	MaxBytes     *int64
+	MaxDocuments *int64
+}
+
+// Check reports whether adding bytes and documents to usage stays within q.
+func (q Quota) Check(usage *Usage, bytes, documents int64) error {
+	if q.MaxBytes != nil && usage.Bytes+bytes > *q.MaxBytes {
+		return &QuotaError{Quota: q, Resource: "bytes", Limit: *q.MaxBytes, Used: usage.Bytes, Requested: bytes}
+	}
+
+	if q.MaxDocuments != nil && usage.Documents+documents > *q.MaxDocuments {
+		return &QuotaError{Quota: q, Resource: "documents", Limit: *q.MaxDocuments, Used: usage.Documents, Requested: documents}
+	}
+
+	return nil
+}
+
+// QuotaError describes the limit an upload would break. It matches
+// ErrQuotaExceeded with errors.Is.
+type QuotaError struct {
+	Quota     Quota
+	Resource  string
+	Limit     int64
+	Used      int64
+	Requested int64
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %s %s uses %d of %d %s and the upload needs %d more",
+		ErrQuotaExceeded, e.Quota.Scope, e.Quota.SubjectID, e.Used, e.Limit, e.Resource, e.Requested)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
@@ -5,12 +5,16 @@ import (
 	"database/sql"
 	"docvault/config"
 	"docvault/database"
+	"docvault/entity"
 	"docvault/handler"
+	"docvault/middleware"
 	"docvault/repository"
 	"docvault/service"
 	"docvault/usecase"
 	"docvault/worker"
 	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
@@ -22,8 +26,26 @@ import (
 type Factory struct {
 	DB                 *sql.DB
 	DocumentHandler    *handler.DocumentHandler
+	FolderHandler      *handler.FolderHandler
+	RetentionHandler   *handler.RetentionHandler
+	LegalHoldHandler   *handler.LegalHoldHandler
+	AuditHandler       *handler.AuditHandler
+	APIKeyHandler      *handler.APIKeyHandler
+	ACLHandler         *handler.ACLHandler
+	ShareHandler       *handler.ShareHandler
+	TenantHandler      *handler.TenantHandler
+	UsageHandler       *handler.UsageHandler
+	ImportHandler      *handler.ImportHandler
+	JobHandler         *handler.JobHandler
+	StorageHandler     *handler.StorageHandler
+	Authenticators     []middleware.Authenticator
+	TenantResolver     middleware.TenantResolver
+	RateLimiter        service.RateLimiter
 	NotificationWorker *worker.NotificationWorker
 	SchedulerWorker    *worker.SchedulerWorker
+	JobWorker          *worker.JobWorker
+	// ReplicationWorker is nil unless storage is replicated.
+	ReplicationWorker *worker.ReplicationWorker
 }
 
 func New(cfg *config.Config) (*Factory, error) {
@@ -62,20 +84,240 @@ func New(cfg *config.Config) (*Factory, error) {
 
 	docRepo := repository.NewSQLiteDocumentRepository(db)
 
-	storageService := service.NewMinIOStorage(minioClient, cfg.MinioBucketName)
+	folderRepo := repository.NewSQLiteFolderRepository(db)
 
-	docUsecase := usecase.NewDocumentUsecase(docRepo, storageService, queueService)
+	retentionRepo := repository.NewSQLiteRetentionPolicyRepository(db)
+
+	holdRepo := repository.NewSQLiteLegalHoldRepository(db)
+
+	auditRepo := repository.NewSQLiteAuditRepository(db)
+
+	apiKeyRepo := repository.NewSQLiteAPIKeyRepository(db)
+
+	aclRepo := repository.NewSQLiteDocumentACLRepository(db)
+
+	shareRepo := repository.NewSQLiteShareLinkRepository(db)
+
+	tenantRepo := repository.NewSQLiteTenantRepository(db)
+
+	usageRepo := repository.NewSQLiteUsageRepository(db)
+
+	thumbnailRepo := repository.NewSQLiteThumbnailRepository(db)
+	importRepo := repository.NewSQLiteImportRepository(db)
+	jobRepo := repository.NewSQLiteJobRepository(db)
+
+	openStorage := storageOpener(minioClient)
+	activeStorage, err := openStorage(cfg.StorageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open STORAGE_URL: %w", err)
+	}
+
+	var replicatedStorage *service.ReplicatedStorage
+	if cfg.StorageReplicaURL != "" {
+		if strings.TrimSuffix(cfg.StorageReplicaURL, "/") == strings.TrimSuffix(cfg.StorageURL, "/") {
+			return nil, fmt.Errorf("invalid STORAGE_REPLICA_URL: %s is also STORAGE_URL", cfg.StorageReplicaURL)
+		}
+		replicaStorage, err := openStorage(cfg.StorageReplicaURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open STORAGE_REPLICA_URL: %w", err)
+		}
+		replicatedStorage, err = service.NewReplicatedStorage(activeStorage, replicaStorage, cfg.StorageReplication)
+		if err != nil {
+			return nil, fmt.Errorf("invalid STORAGE_REPLICATION: %w", err)
+		}
+		activeStorage = replicatedStorage
+	}
+	storageService := service.NewMigratingStorage(activeStorage)
+
+	storageMigrationRepo := repository.NewSQLiteStorageMigrationRepository(db)
+	storageMigrationUsecase := usecase.NewStorageMigrationUsecase(storageMigrationRepo, storageService, openStorage, cfg.StorageURL)
+	if err := storageMigrationUsecase.Restore(context.Background()); err != nil {
+		return nil, err
+	}
+
+	var scanner service.Scanner
+	switch cfg.Scanner {
+	case "", "signature":
+		scanner = service.NewSignatureScanner()
+	case "clamd":
+		if cfg.ClamdAddress == "" {
+			return nil, fmt.Errorf("CLAMD_ADDRESS is required when SCANNER is clamd")
+		}
+		scanner = service.NewClamdScanner(cfg.ClamdAddress, cfg.ClamdTimeout)
+	default:
+		return nil, fmt.Errorf("unknown SCANNER %q, want signature or clamd", cfg.Scanner)
+	}
+
+	uploadPolicy := entity.UploadPolicy{
+		AllowedTypes:      cfg.UploadAllowedTypes,
+		DeniedTypes:       cfg.UploadDeniedTypes,
+		AllowedExtensions: cfg.UploadAllowedExtensions,
+		DeniedExtensions:  cfg.UploadDeniedExtensions,
+		SizeLimits:        cfg.UploadSizeLimits,
+		OnMismatch:        cfg.UploadTypeMismatch,
+	}
+	switch uploadPolicy.OnMismatch {
+	case "":
+		uploadPolicy.OnMismatch = entity.ContentMismatchReject
+	case entity.ContentMismatchReject, entity.ContentMismatchCorrect, entity.ContentMismatchAllow:
+	default:
+		return nil, fmt.Errorf("unknown UPLOAD_TYPE_MISMATCH %q, want reject, correct or allow", uploadPolicy.OnMismatch)
+	}
+
+	auditUsecase := usecase.NewAuditUsecase(auditRepo)
+
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo)
+	if cfg.BootstrapAdminKey != "" {
+		if err := apiKeyUsecase.EnsureBootstrapKey(context.Background(), cfg.BootstrapAdminKey); err != nil {
+			return nil, fmt.Errorf("failed to register bootstrap admin key: %w", err)
+		}
+	}
+
+	authenticators := []middleware.Authenticator{apiKeyUsecase}
+	if cfg.JWTHS256Secret != "" || cfg.JWTRSAPublicKeyFile != "" || cfg.JWTJWKSFile != "" {
+		verifier, err := service.NewJWTVerifier(service.JWTOptions{
+			HMACSecret:       []byte(cfg.JWTHS256Secret),
+			RSAPublicKeyFile: cfg.JWTRSAPublicKeyFile,
+			JWKSFile:         cfg.JWTJWKSFile,
+			Issuer:           cfg.JWTIssuer,
+			Audience:         cfg.JWTAudience,
+			Leeway:           cfg.JWTLeeway,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize JWT verifier: %w", err)
+		}
+		authenticators = append(authenticators, usecase.NewJWTAuthenticator(verifier, cfg.JWTRolesClaim, cfg.JWTGroupsClaim, cfg.JWTTenantClaim))
+	}
+
+	docUsecase := usecase.NewDocumentUsecase(docRepo, storageService, queueService,
+		usecase.WithFolders(folderRepo),
+		usecase.WithRetentionPolicies(retentionRepo),
+		usecase.WithLegalHolds(holdRepo),
+		usecase.WithACL(aclRepo),
+		usecase.WithAudit(auditUsecase),
+		usecase.WithTenants(tenantRepo),
+		usecase.WithUsage(usageRepo),
+		usecase.WithScanner(scanner),
+		usecase.WithUploadPolicy(uploadPolicy),
+		usecase.WithThumbnails(thumbnailRepo, service.NewImageThumbnailer(), cfg.ThumbnailSizes),
+		usecase.WithArchiveLimits(cfg.ArchiveMaxBytes, cfg.ArchiveMaxDocuments),
+	)
+
+	jobUsecase := usecase.NewJobUsecase(jobRepo, storageService, cfg.JobRetention)
+	docUsecase.RegisterJobs(jobUsecase)
+	storageMigrationUsecase.RegisterJobs(jobUsecase)
+
+	folderUsecase := usecase.NewFolderUsecase(folderRepo, docUsecase)
+
+	retentionUsecase := usecase.NewRetentionUsecase(retentionRepo, folderRepo, docUsecase)
+
+	holdUsecase := usecase.NewLegalHoldUsecase(holdRepo, docUsecase)
+
+	aclUsecase := usecase.NewACLUsecase(aclRepo, docUsecase)
+
+	shareUsecase := usecase.NewShareUsecase(shareRepo, docUsecase)
+
+	tenantUsecase := usecase.NewTenantUsecase(tenantRepo, docUsecase)
+
+	usageUsecase := usecase.NewUsageUsecase(usageRepo, tenantRepo)
+
+	importUsecase := usecase.NewImportUsecase(importRepo, docUsecase, folderUsecase, storageService, usecase.ImportLimits{
+		MaxEntries:          cfg.ImportMaxEntries,
+		MaxEntryBytes:       cfg.ImportMaxEntryBytes,
+		MaxTotalBytes:       cfg.ImportMaxTotalBytes,
+		MaxCompressionRatio: cfg.ImportMaxCompressionRatio,
+	})
+	importUsecase.RegisterJobs(jobUsecase)
 
 	docHandler := handler.NewDocumentHandler(docUsecase)
 
-	notificationWorker := worker.NewNotificationWorker(queueService)
+	folderHandler := handler.NewFolderHandler(folderUsecase)
+
+	retentionHandler := handler.NewRetentionHandler(retentionUsecase)
+
+	holdHandler := handler.NewLegalHoldHandler(holdUsecase)
+
+	auditHandler := handler.NewAuditHandler(auditUsecase)
+
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyUsecase)
+
+	aclHandler := handler.NewACLHandler(aclUsecase)
+
+	shareHandler := handler.NewShareHandler(shareUsecase)
 
-	schedulerWorker := worker.NewSchedulerWorker(docUsecase)
+	tenantHandler := handler.NewTenantHandler(tenantUsecase)
+
+	usageHandler := handler.NewUsageHandler(usageUsecase)
+
+	importHandler := handler.NewImportHandler(importUsecase)
+
+	jobHandler := handler.NewJobHandler(jobUsecase)
+
+	storageHandler := handler.NewStorageHandler(storageMigrationUsecase)
+
+	notificationWorker := worker.NewNotificationWorker(queueService, docUsecase)
+
+	schedulerWorker := worker.NewSchedulerWorker(docUsecase, usageUsecase, cfg.TrashRetention, cfg.UsageReconcileInterval).
+		WithFsck(cfg.FsckInterval, cfg.FsckRepair)
+
+	jobWorker := worker.NewJobWorker(jobUsecase, cfg.JobWorkers)
+
+	var replicationWorker *worker.ReplicationWorker
+	if replicatedStorage != nil {
+		replicationWorker = worker.NewReplicationWorker(replicatedStorage, docUsecase, cfg.StorageReplicaResyncInterval)
+	}
 
 	return &Factory{
 		DB:                 db,
 		DocumentHandler:    docHandler,
+		FolderHandler:      folderHandler,
+		RetentionHandler:   retentionHandler,
+		LegalHoldHandler:   holdHandler,
+		AuditHandler:       auditHandler,
+		APIKeyHandler:      apiKeyHandler,
+		ACLHandler:         aclHandler,
+		ShareHandler:       shareHandler,
+		TenantHandler:      tenantHandler,
+		UsageHandler:       usageHandler,
+		ImportHandler:      importHandler,
+		JobHandler:         jobHandler,
+		StorageHandler:     storageHandler,
+		Authenticators:     authenticators,
+		TenantResolver:     tenantUsecase,
+		RateLimiter:        service.NewMemoryRateLimiter(),
 		NotificationWorker: notificationWorker,
 		SchedulerWorker:    schedulerWorker,
+		JobWorker:          jobWorker,
+		ReplicationWorker:  replicationWorker,
 	}, nil
 }
+
+// storageOpener opens minio://bucket URLs with the configured MinIO client
+// and file:///path URLs as local directories.
+func storageOpener(client *minio.Client) usecase.StorageOpener {
+	return func(rawURL string) (service.StorageService, error) {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid storage URL %q: %w", rawURL, err)
+		}
+
+		switch parsed.Scheme {
+		case "minio":
+			if parsed.Host == "" {
+				return nil, fmt.Errorf("storage URL %q names no bucket", rawURL)
+			}
+			return service.NewMinIOStorage(client, parsed.Host), nil
+		case "file":
+			root := parsed.Path
+			if root == "" {
+				root = parsed.Opaque
+			}
+			if root == "" {
+				return nil, fmt.Errorf("storage URL %q names no directory", rawURL)
+			}
+			return service.NewLocalStorage(root)
+		default:
+			return nil, fmt.Errorf("unknown storage URL scheme %q, want minio or file", parsed.Scheme)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ACLHandler struct {
+	usecase *usecase.ACLUsecase
+}
+
+func NewACLHandler(usecase *usecase.ACLUsecase) *ACLHandler {
+	return &ACLHandler{usecase: usecase}
+}
+
+func (h *ACLHandler) Grant(c *gin.Context) {
+	var req dto.GrantACLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	entry, err := h.usecase.Grant(c.Request.Context(), c.Param("id"), req.Subject, req.Permission)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromACLEntry(entry))
+}
+
+func (h *ACLHandler) List(c *gin.Context) {
+	entries, err := h.usecase.List(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromACLEntries(entries))
+}
+
+func (h *ACLHandler) Revoke(c *gin.Context) {
+	if err := h.usecase.Revoke(c.Request.Context(), c.Param("id"), c.Param("entry_id")); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "acl entry revoked"})
+}
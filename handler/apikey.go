@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/usecase"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	usecase *usecase.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(usecase *usecase.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{usecase: usecase}
+}
+
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	key, secret, err := h.usecase.Create(c.Request.Context(), req.Name, req.Permissions, time.Duration(req.ExpiresInSeconds)*time.Second, req.TenantID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, dto.CreatedAPIKeyResponse{APIKeyResponse: dto.FromAPIKey(key), Key: secret})
+}
+
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.usecase.List(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromAPIKeys(keys))
+}
+
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	if err := h.usecase.Revoke(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "api key revoked"})
+}
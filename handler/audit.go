@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/entity"
+	"docvault/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	usecase *usecase.AuditUsecase
+}
+
+func NewAuditHandler(usecase *usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{usecase: usecase}
+}
+
+func (h *AuditHandler) List(c *gin.Context) {
+	filter := entity.AuditFilter{
+		DocumentID: c.Query("document_id"),
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		Outcome:    c.Query("outcome"),
+	}
+
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 timestamp"})
+			return
+		}
+		*target = &parsed
+	}
+
+	page, pageSize := parsePagination(c)
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
+	total, err := h.usecase.Count(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+	entries, err := h.usecase.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromAuditEntries(entries))
+}
+
+func (h *AuditHandler) Verify(c *gin.Context) {
+	result, err := h.usecase.Verify(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromAuditVerification(result))
+}
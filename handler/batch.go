@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/entity"
+	"docvault/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Each batch action has its own route so that it can require the permission
+// the single-document endpoint requires.
+
+func (h *DocumentHandler) BatchDelete(c *gin.Context) {
+	h.batch(c, usecase.BatchActionDelete)
+}
+
+func (h *DocumentHandler) BatchTags(c *gin.Context) {
+	h.batch(c, usecase.BatchActionTags)
+}
+
+func (h *DocumentHandler) BatchExpiry(c *gin.Context) {
+	h.batch(c, usecase.BatchActionExpiry)
+}
+
+func (h *DocumentHandler) BatchMove(c *gin.Context) {
+	h.batch(c, usecase.BatchActionMove)
+}
+
+func (h *DocumentHandler) batch(c *gin.Context, action string) {
+	var req dto.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	input := usecase.BatchInput{
+		Action:       action,
+		IDs:          req.IDs,
+		DryRun:       req.DryRun,
+		AddTags:      req.AddTags,
+		RemoveTags:   req.RemoveTags,
+		ExpiresAt:    req.ExpiresAt.Value,
+		SetExpiresAt: req.ExpiresAt.Set,
+		FolderID:     req.FolderID,
+	}
+	if req.Filter != nil {
+		input.Filter = entity.DocumentFilter{
+			FolderID: req.Filter.FolderID,
+			Tags:     splitTags(req.Filter.Tags),
+			Metadata: req.Filter.Metadata,
+		}
+	}
+
+	report, err := h.usecase.Batch(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := dto.BatchResponse{
+		Action:    report.Action,
+		DryRun:    report.DryRun,
+		Changed:   report.Changed,
+		Unchanged: report.Unchanged,
+		Failed:    report.Failed,
+		Results:   []dto.BatchResultResponse{},
+	}
+	for _, result := range report.Results {
+		item := dto.BatchResultResponse{DocumentID: result.DocumentID, Status: result.Status, Changes: result.Changes}
+		if result.Err != nil {
+			message := result.Err.Error()
+			item.Error = &message
+			item.Code = statusFromError(result.Err)
+		}
+		response.Results = append(response.Results, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
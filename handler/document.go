@@ -2,11 +2,13 @@ package handler
 
 import (
 	"docvault/dto"
+	"docvault/entity"
 	"docvault/usecase"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -36,16 +38,18 @@ func (h *DocumentHandler) Upload(c *gin.Context) {
 	}
 	defer fileReader.Close()
 
-	doc, err := h.usecase.Upload(
-		c.Request.Context(),
-		file.Filename,
-		file.Size,
-		file.Header.Get("Content-Type"),
-		fileReader,
-		expiresIn,
-	)
+	doc, err := h.usecase.Upload(c.Request.Context(), usecase.UploadInput{
+		FileName:    file.Filename,
+		FileSize:    file.Size,
+		ContentType: file.Header.Get("Content-Type"),
+		File:        fileReader,
+		ExpiresIn:   expiresIn,
+		Tags:        splitTags(c.PostFormArray("tags")),
+		Metadata:    prefixedValues(c.Request.PostForm, metadataPrefix),
+		FolderID:    c.PostForm("folder_id"),
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusFromError(err), errorBody(err))
 		return
 	}
 
@@ -54,9 +58,29 @@ func (h *DocumentHandler) Upload(c *gin.Context) {
 }
 
 func (h *DocumentHandler) List(c *gin.Context) {
-	docs, err := h.usecase.List(c.Request.Context())
+	filter := entity.DocumentFilter{
+		Tags:     splitTags(c.QueryArray("tag")),
+		Metadata: prefixedValues(c.Request.URL.Query(), metadataPrefix),
+	}
+	if folderID, ok := c.GetQuery("folder_id"); ok {
+		filter.FolderID = &folderID
+	}
+	if c.Query("page") != "" || c.Query("page_size") != "" {
+		page, pageSize := parsePagination(c)
+		filter.Limit = pageSize
+		filter.Offset = (page - 1) * pageSize
+
+		total, err := h.usecase.Count(c.Request.Context(), filter)
+		if err != nil {
+			c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	}
+
+	docs, err := h.usecase.List(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -68,6 +92,40 @@ func (h *DocumentHandler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, responses)
 }
 
+func (h *DocumentHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	expectedVersion, err := parseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.UpdateDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	doc, err := h.usecase.Update(c.Request.Context(), id, usecase.UpdateInput{
+		FileName:        req.FileName,
+		ContentType:     req.ContentType,
+		ExpiresAt:       req.ExpiresAt.Value,
+		SetExpiresAt:    req.ExpiresAt.Set,
+		Tags:            req.Tags,
+		Metadata:        req.Metadata,
+		FolderID:        req.FolderID,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", versionETag(doc.Version))
+	c.JSON(http.StatusOK, dto.FromEntity(doc))
+}
+
 func (h *DocumentHandler) GetMetadata(c *gin.Context) {
 	id := c.Param("id")
 
@@ -79,27 +137,78 @@ func (h *DocumentHandler) GetMetadata(c *gin.Context) {
 
 	response := dto.FromEntity(doc)
 
+	c.Header("ETag", versionETag(doc.Version))
 	c.JSON(http.StatusOK, response)
 }
 
 func (h *DocumentHandler) Download(c *gin.Context) {
 	id := c.Param("id")
 
-	doc, err := h.usecase.GetMetadata(c.Request.Context(), id)
+	doc, fileStream, err := h.usecase.Download(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer fileStream.Close()
+
+	c.Header("Content-Type", doc.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", doc.FileName))
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, fileStream)
+}
+
+func (h *DocumentHandler) Archive(c *gin.Context) {
+	var req dto.ArchiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
 		return
 	}
 
-	fileStream, err := h.usecase.Download(c.Request.Context(), doc.FileName)
+	archive, err := h.usecase.PrepareArchive(c.Request.Context(), usecase.ArchiveInput{
+		IDs: req.IDs,
+		Filter: entity.DocumentFilter{
+			FolderID: req.FolderID,
+			Tags:     splitTags(req.Tags),
+			Metadata: req.Metadata,
+		},
+		Recursive: req.Recursive,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"documents-%s.zip\"", time.Now().UTC().Format("20060102-150405")))
+	c.Status(http.StatusOK)
+
+	// The status is already sent, so a failure can only cut the archive short.
+	if err := h.usecase.WriteArchive(c.Request.Context(), archive, c.Writer); err != nil {
+		fmt.Printf("Failed to stream archive: %v\n", err)
+	}
+}
+
+func (h *DocumentHandler) Thumbnail(c *gin.Context) {
+	size := 0
+	if value := c.Query("size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "size must be an integer"})
+			return
+		}
+		size = parsed
+	}
+
+	thumbnail, fileStream, err := h.usecase.Thumbnail(c.Request.Context(), c.Param("id"), size)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
 		return
 	}
 	defer fileStream.Close()
 
-	c.Header("Content-Type", doc.ContentType)
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", doc.FileName))
+	c.Header("Content-Type", thumbnail.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(thumbnail.FileSize, 10))
+	c.Header("Cache-Control", "private, max-age=3600")
 	c.Status(http.StatusOK)
 	io.Copy(c.Writer, fileStream)
 }
@@ -108,13 +217,53 @@ func (h *DocumentHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := h.usecase.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "document deleted"})
 }
 
+func (h *DocumentHandler) ListTrash(c *gin.Context) {
+	var filter entity.DocumentFilter
+	if c.Query("page") != "" || c.Query("page_size") != "" {
+		page, pageSize := parsePagination(c)
+		filter.Limit = pageSize
+		filter.Offset = (page - 1) * pageSize
+	}
+
+	docs, err := h.usecase.ListTrash(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromEntities(docs))
+}
+
+func (h *DocumentHandler) Restore(c *gin.Context) {
+	id := c.Param("id")
+
+	doc, err := h.usecase.Restore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromEntity(doc))
+}
+
+func (h *DocumentHandler) Purge(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.usecase.Purge(c.Request.Context(), id); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "document purged"})
+}
+
 func (h *DocumentHandler) Health(c *gin.Context) {
 	status := h.usecase.Health(c.Request.Context())
 	c.JSON(200, gin.H{"status": "healthy", "services": status})
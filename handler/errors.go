@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/entity"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, entity.ErrDocumentNotFound), errors.Is(err, entity.ErrFolderNotFound), errors.Is(err, entity.ErrRetentionPolicyNotFound),
+		errors.Is(err, entity.ErrLegalHoldNotFound), errors.Is(err, entity.ErrAPIKeyNotFound),
+		errors.Is(err, entity.ErrACLEntryNotFound), errors.Is(err, entity.ErrShareLinkNotFound),
+		errors.Is(err, entity.ErrTenantNotFound), errors.Is(err, entity.ErrThumbnailNotFound),
+		errors.Is(err, entity.ErrImportNotFound), errors.Is(err, entity.ErrJobNotFound), errors.Is(err, entity.ErrNoJobArtifact),
+		errors.Is(err, entity.ErrStorageMigrationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entity.ErrDocumentNotTrashed), errors.Is(err, entity.ErrFolderNotEmpty), errors.Is(err, entity.ErrFolderExists),
+		errors.Is(err, entity.ErrRetentionPolicyExists), errors.Is(err, entity.ErrDocumentOnHold), errors.Is(err, entity.ErrLegalHoldReleased),
+		errors.Is(err, entity.ErrTenantExists), errors.Is(err, entity.ErrTenantNotEmpty),
+		errors.Is(err, entity.ErrScanPending), errors.Is(err, entity.ErrDocumentInfected), errors.Is(err, entity.ErrJobFinished),
+		errors.Is(err, entity.ErrDocumentMissing), errors.Is(err, entity.ErrScanFailed):
+		return http.StatusConflict
+	case errors.Is(err, entity.ErrQuotaExceeded), errors.Is(err, entity.ErrFileTooLarge),
+		errors.Is(err, entity.ErrArchiveTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, entity.ErrUnsupportedContent):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, entity.ErrShareLinkUnavailable):
+		return http.StatusGone
+	case errors.Is(err, entity.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, entity.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, entity.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, entity.ErrForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorBody adds the broken limit to quota errors so clients can tell which
+// quota an upload ran into.
+func errorBody(err error) gin.H {
+	body := gin.H{"error": err.Error()}
+
+	var quotaErr *entity.QuotaError
+	if errors.As(err, &quotaErr) {
+		body["quota"] = dto.FromQuotaError(quotaErr)
+	}
+
+	return body
+}
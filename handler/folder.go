@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FolderHandler struct {
+	usecase *usecase.FolderUsecase
+}
+
+func NewFolderHandler(usecase *usecase.FolderUsecase) *FolderHandler {
+	return &FolderHandler{usecase: usecase}
+}
+
+func (h *FolderHandler) Create(c *gin.Context) {
+	var req dto.CreateFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	folder, err := h.usecase.Create(c.Request.Context(), req.Name, req.ParentID)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromFolder(folder))
+}
+
+func (h *FolderHandler) List(c *gin.Context) {
+	folders, err := h.usecase.ListRoot(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromFolders(folders))
+}
+
+func (h *FolderHandler) Get(c *gin.Context) {
+	folder, err := h.usecase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromFolder(folder))
+}
+
+func (h *FolderHandler) Update(c *gin.Context) {
+	var req dto.UpdateFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	folder, err := h.usecase.Update(c.Request.Context(), c.Param("id"), usecase.FolderUpdateInput{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromFolder(folder))
+}
+
+func (h *FolderHandler) Delete(c *gin.Context) {
+	cascade := c.Query("cascade") == "true"
+
+	if err := h.usecase.Delete(c.Request.Context(), c.Param("id"), cascade); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "folder deleted"})
+}
+
+func (h *FolderHandler) Contents(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	contents, err := h.usecase.Contents(c.Request.Context(), c.Param("id"), page, pageSize)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FolderContentsResponse{
+		Folder:    dto.FromFolder(contents.Folder),
+		Folders:   dto.FromFolders(contents.Folders),
+		Documents: dto.FromEntities(contents.Documents),
+		Page:      page,
+		PageSize:  pageSize,
+		Total:     contents.Total,
+	})
+}
+
+func (h *FolderHandler) Stats(c *gin.Context) {
+	id := c.Param("id")
+
+	stats, err := h.usecase.Stats(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FolderStatsResponse{
+		FolderID:      id,
+		FolderCount:   stats.FolderCount,
+		DocumentCount: stats.DocumentCount,
+		TotalSize:     stats.TotalSize,
+	})
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LegalHoldHandler struct {
+	usecase *usecase.LegalHoldUsecase
+}
+
+func NewLegalHoldHandler(usecase *usecase.LegalHoldUsecase) *LegalHoldHandler {
+	return &LegalHoldHandler{usecase: usecase}
+}
+
+func (h *LegalHoldHandler) Place(c *gin.Context) {
+	var req dto.PlaceHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	hold, err := h.usecase.Place(c.Request.Context(), c.Param("id"), req.Reason, req.CreatedBy)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromLegalHold(hold))
+}
+
+func (h *LegalHoldHandler) List(c *gin.Context) {
+	activeOnly := c.Query("active") == "true"
+
+	holds, err := h.usecase.List(c.Request.Context(), c.Param("id"), activeOnly)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromLegalHolds(holds))
+}
+
+func (h *LegalHoldHandler) Release(c *gin.Context) {
+	var req dto.ReleaseHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	hold, err := h.usecase.Release(c.Request.Context(), c.Param("id"), c.Param("hold_id"), req.ReleasedBy, req.Reason)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromLegalHold(hold))
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImportHandler struct {
+	usecase *usecase.ImportUsecase
+}
+
+func NewImportHandler(usecase *usecase.ImportUsecase) *ImportHandler {
+	return &ImportHandler{usecase: usecase}
+}
+
+func (h *ImportHandler) Create(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to get file from request"})
+		return
+	}
+
+	async := false
+	if value := c.PostForm("async"); value != "" {
+		if async, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "async must be true or false"})
+			return
+		}
+	}
+
+	fileReader, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer fileReader.Close()
+
+	input := usecase.ImportInput{
+		FileName: file.Filename,
+		FileSize: file.Size,
+		File:     fileReader,
+		FolderID: c.PostForm("folder_id"),
+		Tags:     splitTags(c.PostFormArray("tags")),
+		Metadata: prefixedValues(c.Request.PostForm, metadataPrefix),
+	}
+
+	if async {
+		imp, err := h.usecase.Start(c.Request.Context(), input)
+		if err != nil {
+			c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Location", "/api/imports/"+imp.ID)
+		c.JSON(http.StatusAccepted, dto.FromImport(imp))
+		return
+	}
+
+	imp, err := h.usecase.Import(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromImport(imp))
+}
+
+func (h *ImportHandler) Get(c *gin.Context) {
+	imp, err := h.usecase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromImport(imp))
+}
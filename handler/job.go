@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/entity"
+	"docvault/usecase"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	usecase *usecase.JobUsecase
+}
+
+func NewJobHandler(usecase *usecase.JobUsecase) *JobHandler {
+	return &JobHandler{usecase: usecase}
+}
+
+func (h *JobHandler) Submit(c *gin.Context) {
+	var req dto.SubmitJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	job, err := h.usecase.Submit(c.Request.Context(), req.Kind, req.Params)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", "/api/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, dto.FromJob(job))
+}
+
+func (h *JobHandler) List(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	jobs, err := h.usecase.List(c.Request.Context(), entity.JobFilter{
+		Kind:   c.Query("kind"),
+		Status: c.Query("status"),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromJobs(jobs))
+}
+
+func (h *JobHandler) Get(c *gin.Context) {
+	job, err := h.usecase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromJob(job))
+}
+
+func (h *JobHandler) Cancel(c *gin.Context) {
+	job, err := h.usecase.Cancel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromJob(job))
+}
+
+func (h *JobHandler) Artifact(c *gin.Context) {
+	job, fileStream, err := h.usecase.Artifact(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer fileStream.Close()
+
+	c.Header("Content-Type", *job.ArtifactContentType)
+	c.Header("Content-Length", strconv.FormatInt(*job.ArtifactSize, 10))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", *job.ArtifactName))
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, fileStream)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const metadataPrefix = "meta."
+
+func splitTags(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+func prefixedValues(values url.Values, prefix string) map[string]string {
+	result := map[string]string{}
+	for key, vals := range values {
+		if !strings.HasPrefix(key, prefix) || len(vals) == 0 {
+			continue
+		}
+
+		result[strings.TrimPrefix(key, prefix)] = vals[0]
+	}
+
+	return result
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+func parsePagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	return page, pageSize
+}
+
+func versionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch reads the document version from an If-Match header. A missing
+// header or "*" means the caller does not require a specific version.
+func parseIfMatch(header string) (*int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid If-Match header %q", header)
+	}
+
+	return &version, nil
+}
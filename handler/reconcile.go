@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"docvault/dto"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reconcile compares storage with the documents table; an empty body only
+// reports what repair would fix.
+func (h *DocumentHandler) Reconcile(c *gin.Context) {
+	var req dto.ReconcileRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+			return
+		}
+	}
+
+	report, err := h.usecase.Reconcile(c.Request.Context(), req.Repair)
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := dto.ReconcileResponse{
+		Repair:    report.Repair,
+		Objects:   report.Objects,
+		Documents: report.Documents,
+		Orphans:   []dto.ReconcileOrphanResponse{},
+		Missing:   []dto.ReconcileMissingResponse{},
+		Found:     report.Found,
+	}
+	for _, orphan := range report.Orphans {
+		item := dto.ReconcileOrphanResponse{Key: orphan.Key, Size: orphan.Size, LastModified: orphan.LastModified, Deleted: orphan.Deleted}
+		if orphan.Err != nil {
+			message := orphan.Err.Error()
+			item.Error = &message
+		}
+		response.Orphans = append(response.Orphans, item)
+	}
+	for _, missing := range report.Missing {
+		doc := missing.Document
+		item := dto.ReconcileMissingResponse{DocumentID: doc.ID, TenantID: doc.TenantID, FileName: doc.FileName, Key: doc.ObjectKey(), Marked: missing.Marked}
+		if missing.Err != nil {
+			message := missing.Err.Error()
+			item.Error = &message
+		}
+		response.Missing = append(response.Missing, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
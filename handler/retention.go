@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionHandler struct {
+	usecase *usecase.RetentionUsecase
+}
+
+func NewRetentionHandler(usecase *usecase.RetentionUsecase) *RetentionHandler {
+	return &RetentionHandler{usecase: usecase}
+}
+
+func policyInput(req dto.RetentionPolicyRequest) usecase.RetentionPolicyInput {
+	return usecase.RetentionPolicyInput{
+		Name:         req.Name,
+		ContentType:  req.ContentType,
+		FolderID:     req.FolderID,
+		DefaultTTL:   dto.FromSeconds(req.DefaultTTLSeconds),
+		MinRetention: dto.FromSeconds(req.MinRetentionSeconds),
+		MaxRetention: dto.FromSeconds(req.MaxRetentionSeconds),
+		NeverExpire:  req.NeverExpire,
+		Priority:     req.Priority,
+	}
+}
+
+func (h *RetentionHandler) Create(c *gin.Context) {
+	var req dto.RetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	policy, err := h.usecase.Create(c.Request.Context(), policyInput(req))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromRetentionPolicy(policy))
+}
+
+func (h *RetentionHandler) List(c *gin.Context) {
+	policies, err := h.usecase.List(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromRetentionPolicies(policies))
+}
+
+func (h *RetentionHandler) Get(c *gin.Context) {
+	policy, err := h.usecase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromRetentionPolicy(policy))
+}
+
+func (h *RetentionHandler) Update(c *gin.Context) {
+	var req dto.RetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	policy, err := h.usecase.Update(c.Request.Context(), c.Param("id"), policyInput(req))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromRetentionPolicy(policy))
+}
+
+func (h *RetentionHandler) Delete(c *gin.Context) {
+	if err := h.usecase.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "retention policy deleted"})
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/entity"
+	"docvault/usecase"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const sharePasswordHeader = "X-Share-Password"
+
+type ShareHandler struct {
+	usecase *usecase.ShareUsecase
+}
+
+func NewShareHandler(usecase *usecase.ShareUsecase) *ShareHandler {
+	return &ShareHandler{usecase: usecase}
+}
+
+func (h *ShareHandler) Create(c *gin.Context) {
+	var req dto.CreateShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	link, token, err := h.usecase.Create(c.Request.Context(), c.Param("id"), usecase.ShareInput{
+		ExpiresIn:    req.ExpiresInSeconds,
+		Password:     req.Password,
+		MaxDownloads: req.MaxDownloads,
+	})
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, &dto.CreatedShareLinkResponse{
+		ShareLinkResponse: dto.FromShareLink(link),
+		Token:             token,
+		URL:               "/s/" + token,
+	})
+}
+
+func (h *ShareHandler) List(c *gin.Context) {
+	links, err := h.usecase.List(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromShareLinks(links))
+}
+
+func (h *ShareHandler) Revoke(c *gin.Context) {
+	if err := h.usecase.Revoke(c.Request.Context(), c.Param("id"), c.Param("share_id")); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "share link revoked"})
+}
+
+// Download serves a shared document without authentication. The password is
+// only taken from the X-Share-Password header, as URLs end up in logs,
+// browser history and Referer headers.
+func (h *ShareHandler) Download(c *gin.Context) {
+	password := c.GetHeader(sharePasswordHeader)
+
+	doc, fileStream, err := h.usecase.Download(c.Request.Context(), c.Param("token"), password)
+	if err != nil {
+		if errors.Is(err, entity.ErrUnauthorized) {
+			c.Header("WWW-Authenticate", sharePasswordHeader)
+		}
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer fileStream.Close()
+
+	c.Header("Content-Type", doc.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", doc.FileName))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, fileStream)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/entity"
+	"docvault/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StorageHandler struct {
+	migrations *usecase.StorageMigrationUsecase
+}
+
+func NewStorageHandler(migrations *usecase.StorageMigrationUsecase) *StorageHandler {
+	return &StorageHandler{migrations: migrations}
+}
+
+// Status reports the backend documents are served from and the progress of
+// the latest migration to another one.
+func (h *StorageHandler) Status(c *gin.Context) {
+	status, err := h.migrations.Status(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := dto.StorageStatusResponse{Active: status.Active}
+	if migration := status.Migration; migration != nil {
+		response.Migration = &dto.StorageMigrationResponse{
+			ID:          migration.ID,
+			Source:      migration.Source,
+			Target:      migration.Target,
+			Status:      migration.Status,
+			Items:       map[string]int{},
+			Failed:      []dto.StorageMigrationItemResponse{},
+			CreatedBy:   migration.CreatedBy,
+			CreatedAt:   migration.CreatedAt,
+			UpdatedAt:   migration.UpdatedAt,
+			CompletedAt: migration.CompletedAt,
+		}
+		for _, item := range status.Items {
+			response.Migration.Items[item.Status]++
+			if item.Status == entity.StorageMigrationItemFailed {
+				response.Migration.Failed = append(response.Migration.Failed, dto.StorageMigrationItemResponse{
+					Key:       item.Key,
+					Size:      item.Size,
+					Error:     item.Error,
+					UpdatedAt: item.UpdatedAt,
+				})
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	usecase *usecase.TenantUsecase
+}
+
+func NewTenantHandler(usecase *usecase.TenantUsecase) *TenantHandler {
+	return &TenantHandler{usecase: usecase}
+}
+
+func tenantInput(req dto.TenantRequest) usecase.TenantInput {
+	return usecase.TenantInput{
+		Name:               req.Name,
+		QuotaBytes:         req.QuotaBytes,
+		QuotaDocuments:     req.QuotaDocuments,
+		UserQuotaBytes:     req.UserQuotaBytes,
+		UserQuotaDocuments: req.UserQuotaDocuments,
+		DefaultRetention:   dto.FromSeconds(req.DefaultRetentionSeconds),
+	}
+}
+
+func (h *TenantHandler) Create(c *gin.Context) {
+	var req dto.TenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	tenant, err := h.usecase.Create(c.Request.Context(), req.ID, tenantInput(req))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromTenant(tenant))
+}
+
+func (h *TenantHandler) List(c *gin.Context) {
+	tenants, err := h.usecase.List(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromTenants(tenants))
+}
+
+func (h *TenantHandler) Get(c *gin.Context) {
+	tenant, err := h.usecase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromTenant(tenant))
+}
+
+func (h *TenantHandler) Update(c *gin.Context) {
+	var req dto.TenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	tenant, err := h.usecase.Update(c.Request.Context(), c.Param("id"), tenantInput(req))
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromTenant(tenant))
+}
+
+func (h *TenantHandler) Delete(c *gin.Context) {
+	if err := h.usecase.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tenant deleted"})
+}
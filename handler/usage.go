@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"docvault/dto"
+	"docvault/entity"
+	"docvault/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsageHandler struct {
+	usecase *usecase.UsageUsecase
+}
+
+func NewUsageHandler(usecase *usecase.UsageUsecase) *UsageHandler {
+	return &UsageHandler{usecase: usecase}
+}
+
+func (h *UsageHandler) Get(c *gin.Context) {
+	report, err := h.usecase.Get(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFromError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := dto.UsageReportResponse{}
+	for _, item := range report {
+		switch item.Quota.Scope {
+		case entity.UsageScopeTenant:
+			response.Tenant = dto.FromUsage(item.Usage, item.Quota)
+		case entity.UsageScopeUser:
+			response.User = dto.FromUsage(item.Usage, item.Quota)
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
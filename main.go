@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"docvault/config"
+	"docvault/entity"
 	"docvault/factory"
 	"docvault/middleware"
+	"docvault/service"
 	"log"
 	"net/http"
 	"os"
@@ -25,7 +27,7 @@ func main() {
 	defer cancel()
 
 	var wg sync.WaitGroup
-	wg.Add(2)
+	wg.Add(3)
 
 	f, err := factory.New(cfg)
 	if err != nil {
@@ -33,17 +35,109 @@ func main() {
 	}
 
 	r := gin.Default()
+	// Client IPs go into the audit log and key anonymous rate limits, so
+	// forwarding headers only count when a configured proxy sent them.
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
 
 	r.Use(middleware.LoggingMiddleware())
 	r.Use(middleware.RecoveryMiddleware())
+	r.Use(middleware.RequestContextMiddleware())
 
 	r.GET("/health", f.DocumentHandler.Health)
 
-	r.POST("/api/documents/upload", f.DocumentHandler.Upload)
-	r.GET("/api/documents", f.DocumentHandler.List)
-	r.GET("/api/documents/:id", f.DocumentHandler.GetMetadata)
-	r.GET("/api/documents/:id/download", f.DocumentHandler.Download)
-	r.DELETE("/api/documents/:id", f.DocumentHandler.Delete)
+	limitUploads := middleware.RateLimitMiddleware(f.RateLimiter, "uploads",
+		service.RateLimit{PerMinute: cfg.RateLimitUploadsPerMinute, Burst: cfg.RateLimitUploadsBurst})
+	limitDownloads := middleware.RateLimitMiddleware(f.RateLimiter, "downloads",
+		service.RateLimit{PerMinute: cfg.RateLimitDownloadsPerMinute, Burst: cfg.RateLimitDownloadsBurst})
+	limitMetadata := middleware.RateLimitMiddleware(f.RateLimiter, "metadata",
+		service.RateLimit{PerMinute: cfg.RateLimitMetadataPerMinute, Burst: cfg.RateLimitMetadataBurst})
+	limitTransfers := middleware.TransferLimitMiddleware(f.RateLimiter, cfg.MaxConcurrentTransfers)
+
+	r.GET("/s/:token", limitDownloads, limitTransfers, f.ShareHandler.Download)
+
+	api := r.Group("/api", middleware.AuthMiddleware(f.Authenticators...), middleware.TenantMiddleware(f.TenantResolver))
+
+	canRead := middleware.RequirePermission(entity.PermissionDocumentsRead)
+	canWrite := middleware.RequirePermission(entity.PermissionDocumentsWrite)
+	canDelete := middleware.RequirePermission(entity.PermissionDocumentsDelete)
+	isAdmin := middleware.RequirePermission(entity.PermissionAdmin)
+	isPlatform := middleware.RequirePlatform()
+
+	api.POST("/documents/upload", limitUploads, limitTransfers, canWrite, f.DocumentHandler.Upload)
+	api.GET("/documents/:id/download", limitDownloads, limitTransfers, canRead, f.DocumentHandler.Download)
+	api.POST("/documents/archive", limitDownloads, limitTransfers, canRead, f.DocumentHandler.Archive)
+	api.POST("/documents/import", limitUploads, limitTransfers, canWrite, f.ImportHandler.Create)
+
+	meta := api.Group("", limitMetadata)
+
+	meta.GET("/documents", canRead, f.DocumentHandler.List)
+	meta.GET("/documents/:id", canRead, f.DocumentHandler.GetMetadata)
+	meta.GET("/documents/:id/thumbnail", canRead, f.DocumentHandler.Thumbnail)
+	meta.POST("/documents/batch/delete", canDelete, f.DocumentHandler.BatchDelete)
+	meta.POST("/documents/batch/tags", canWrite, f.DocumentHandler.BatchTags)
+	meta.POST("/documents/batch/expiry", canWrite, f.DocumentHandler.BatchExpiry)
+	meta.POST("/documents/batch/move", canWrite, f.DocumentHandler.BatchMove)
+	meta.PATCH("/documents/:id", canWrite, f.DocumentHandler.Update)
+	meta.DELETE("/documents/:id", canDelete, f.DocumentHandler.Delete)
+
+	meta.GET("/usage", canRead, f.UsageHandler.Get)
+	meta.GET("/imports/:id", canWrite, f.ImportHandler.Get)
+
+	meta.POST("/jobs", f.JobHandler.Submit)
+	meta.GET("/jobs", f.JobHandler.List)
+	meta.GET("/jobs/:id", f.JobHandler.Get)
+	meta.POST("/jobs/:id/cancel", f.JobHandler.Cancel)
+	api.GET("/jobs/:id/artifact", limitDownloads, limitTransfers, f.JobHandler.Artifact)
+
+	meta.GET("/trash", canRead, f.DocumentHandler.ListTrash)
+	meta.POST("/trash/:id/restore", canWrite, f.DocumentHandler.Restore)
+	meta.DELETE("/trash/:id", canDelete, f.DocumentHandler.Purge)
+
+	meta.POST("/folders", canWrite, f.FolderHandler.Create)
+	meta.GET("/folders", canRead, f.FolderHandler.List)
+	meta.GET("/folders/:id", canRead, f.FolderHandler.Get)
+	meta.PATCH("/folders/:id", canWrite, f.FolderHandler.Update)
+	meta.DELETE("/folders/:id", canDelete, f.FolderHandler.Delete)
+	meta.GET("/folders/:id/contents", canRead, f.FolderHandler.Contents)
+	meta.GET("/folders/:id/stats", canRead, f.FolderHandler.Stats)
+
+	meta.GET("/documents/:id/acl", canRead, f.ACLHandler.List)
+	meta.POST("/documents/:id/acl", canWrite, f.ACLHandler.Grant)
+	meta.DELETE("/documents/:id/acl/:entry_id", canWrite, f.ACLHandler.Revoke)
+
+	meta.POST("/documents/:id/shares", canWrite, f.ShareHandler.Create)
+	meta.GET("/documents/:id/shares", canRead, f.ShareHandler.List)
+	meta.DELETE("/documents/:id/shares/:share_id", canWrite, f.ShareHandler.Revoke)
+
+	meta.POST("/documents/:id/holds", isAdmin, f.LegalHoldHandler.Place)
+	meta.GET("/documents/:id/holds", canRead, f.LegalHoldHandler.List)
+	meta.POST("/documents/:id/holds/:hold_id/release", isAdmin, f.LegalHoldHandler.Release)
+
+	meta.GET("/audit", isAdmin, isPlatform, f.AuditHandler.List)
+	meta.GET("/audit/verify", isAdmin, isPlatform, f.AuditHandler.Verify)
+
+	meta.POST("/retention-policies", isAdmin, isPlatform, f.RetentionHandler.Create)
+	meta.GET("/retention-policies", canRead, f.RetentionHandler.List)
+	meta.GET("/retention-policies/:id", canRead, f.RetentionHandler.Get)
+	meta.PUT("/retention-policies/:id", isAdmin, isPlatform, f.RetentionHandler.Update)
+	meta.DELETE("/retention-policies/:id", isAdmin, isPlatform, f.RetentionHandler.Delete)
+
+	admin := meta.Group("/admin", isAdmin)
+	admin.POST("/api-keys", f.APIKeyHandler.Create)
+	admin.GET("/api-keys", f.APIKeyHandler.List)
+	admin.DELETE("/api-keys/:id", f.APIKeyHandler.Revoke)
+
+	admin.POST("/fsck", isPlatform, f.DocumentHandler.Reconcile)
+	admin.GET("/storage", isPlatform, f.StorageHandler.Status)
+
+	tenants := admin.Group("/tenants", isPlatform)
+	tenants.POST("", f.TenantHandler.Create)
+	tenants.GET("", f.TenantHandler.List)
+	tenants.GET("/:id", f.TenantHandler.Get)
+	tenants.PUT("/:id", f.TenantHandler.Update)
+	tenants.DELETE("/:id", f.TenantHandler.Delete)
 
 	server := &http.Server{
 		Addr:    ":" + cfg.Port,
@@ -66,6 +160,17 @@ func main() {
 		f.SchedulerWorker.Start(ctx)
 		wg.Done()
 	}()
+	go func() {
+		f.JobWorker.Start(ctx)
+		wg.Done()
+	}()
+	if f.ReplicationWorker != nil {
+		wg.Add(1)
+		go func() {
+			f.ReplicationWorker.Start(ctx)
+			wg.Done()
+		}()
+	}
 
 	<-quit
 
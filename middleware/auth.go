@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"docvault/entity"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// Authenticator resolves a credential to a principal. It returns (nil, nil)
+// for credentials it does not recognise so the next authenticator can try.
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*entity.Principal, error)
+}
+
+func AuthMiddleware(authenticators ...Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		credential := credentialFromRequest(ctx.Request)
+		if credential == "" {
+			ctx.Header("WWW-Authenticate", "Bearer")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": entity.ErrUnauthorized.Error()})
+			return
+		}
+
+		for _, authenticator := range authenticators {
+			principal, err := authenticator.Authenticate(ctx.Request.Context(), credential)
+			if err != nil {
+				status := http.StatusInternalServerError
+				if errors.Is(err, entity.ErrUnauthorized) {
+					status = http.StatusUnauthorized
+					ctx.Header("WWW-Authenticate", "Bearer")
+				}
+				ctx.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+				return
+			}
+
+			if principal != nil {
+				ctx.Request = ctx.Request.WithContext(entity.WithPrincipal(ctx.Request.Context(), principal))
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.Header("WWW-Authenticate", "Bearer")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": entity.ErrUnauthorized.Error() + ": unrecognized credential"})
+	}
+}
+
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, _ := entity.PrincipalFromContext(ctx.Request.Context())
+		if !principal.Can(permission) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": entity.ErrForbidden.Error() + ": requires " + permission})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func credentialFromRequest(request *http.Request) string {
+	if key := strings.TrimSpace(request.Header.Get(apiKeyHeader)); key != "" {
+		return key
+	}
+
+	scheme, token, ok := strings.Cut(strings.TrimSpace(request.Header.Get("Authorization")), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+
+	return ""
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"docvault/entity"
+	"docvault/service"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// retryTransferAfter is suggested to clients turned away because all of their
+// transfer slots are busy; transfers have no predictable end.
+const retryTransferAfter = time.Second
+
+// RateLimitMiddleware charges each request against the caller's bucket for
+// class. Authenticated callers are keyed by principal, others by client IP.
+func RateLimitMiddleware(limiter service.RateLimiter, class string, limit service.RateLimit) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !limit.Enabled() {
+			ctx.Next()
+			return
+		}
+
+		decision, err := limiter.Allow(ctx.Request.Context(), class+":"+clientKey(ctx), limit)
+		if err != nil {
+			// A broken limiter store should not take the API down with it.
+			fmt.Printf("Failed to check rate limit: %v\n", err)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+		ctx.Header("RateLimit-Policy", fmt.Sprintf("%d;w=60;burst=%d", limit.PerMinute, limit.Burst))
+
+		if !decision.Allowed {
+			ctx.Header("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded for " + class})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// TransferLimitMiddleware caps how many streaming uploads and downloads a
+// caller may run at once; max <= 0 disables the cap.
+func TransferLimitMiddleware(limiter service.RateLimiter, max int) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if max <= 0 {
+			ctx.Next()
+			return
+		}
+
+		release, ok, err := limiter.Acquire(ctx.Request.Context(), "transfers:"+clientKey(ctx), max)
+		if err != nil {
+			fmt.Printf("Failed to acquire transfer slot: %v\n", err)
+			ctx.Next()
+			return
+		}
+
+		if !ok {
+			ctx.Header("Retry-After", strconv.Itoa(ceilSeconds(retryTransferAfter)))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("too many concurrent transfers, at most %d allowed", max)})
+			return
+		}
+		defer release()
+
+		ctx.Next()
+	}
+}
+
+func clientKey(ctx *gin.Context) string {
+	if principal, ok := entity.PrincipalFromContext(ctx.Request.Context()); ok {
+		return principal.ID
+	}
+
+	return "ip:" + ctx.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
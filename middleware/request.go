@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"docvault/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+func RequestContextMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.New().String()
+		}
+		ctx.Header(requestIDHeader, requestID)
+
+		info := entity.RequestInfo{
+			RequestID: requestID,
+			ClientIP:  ctx.ClientIP(),
+			UserAgent: ctx.Request.UserAgent(),
+		}
+		ctx.Request = ctx.Request.WithContext(entity.WithRequestInfo(ctx.Request.Context(), info))
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"docvault/entity"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const tenantHeader = "X-Tenant-ID"
+
+// TenantResolver confirms that a tenant named by a request exists.
+type TenantResolver interface {
+	ResolveTenant(ctx context.Context, id string) error
+}
+
+// TenantMiddleware scopes the request to a tenant. Principals pinned to a
+// tenant always work in it; platform principals pick one with X-Tenant-ID and
+// fall back to the default tenant.
+func TenantMiddleware(resolver TenantResolver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requested := strings.TrimSpace(ctx.GetHeader(tenantHeader))
+		principal, _ := entity.PrincipalFromContext(ctx.Request.Context())
+
+		tenantID := requested
+		if principal != nil && !principal.IsPlatform() {
+			if requested != "" && requested != principal.TenantID {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": entity.ErrForbidden.Error() + ": credential is bound to another tenant"})
+				return
+			}
+			tenantID = principal.TenantID
+		}
+		if tenantID == "" {
+			tenantID = entity.DefaultTenantID
+		}
+
+		if err := resolver.ResolveTenant(ctx.Request.Context(), tenantID); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, entity.ErrTenantNotFound) {
+				status = http.StatusNotFound
+			}
+			ctx.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Request = ctx.Request.WithContext(entity.WithTenant(ctx.Request.Context(), tenantID))
+		ctx.Next()
+	}
+}
+
+// RequirePlatform restricts a route to principals not pinned to a tenant.
+func RequirePlatform() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, _ := entity.PrincipalFromContext(ctx.Request.Context())
+		if !principal.IsPlatform() {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": entity.ErrForbidden.Error() + ": requires a platform credential"})
+			return
+		}
+
+		ctx.Next()
+	}
+}
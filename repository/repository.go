@@ -7,11 +7,168 @@ import (
 )
 
 type DocumentRepository interface {
-	Save(ctx context.Context, doc *entity.Document) error
+	// Save also counts doc towards its usage totals, enforcing quotas atomically.
+	Save(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error
 	FindById(ctx context.Context, id string) (*entity.Document, error)
-	FindAll(ctx context.Context) ([]*entity.Document, error)
+	FindAll(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error)
 	Delete(ctx context.Context, id string) error
 	FindExpired(ctx context.Context, now time.Time) ([]*entity.Document, error)
+	Update(ctx context.Context, doc *entity.Document, expectedVersion int64) error
+	Count(ctx context.Context, filter entity.DocumentFilter) (int64, error)
+	UpdateRetention(ctx context.Context, doc *entity.Document) error
+	UpdateScan(ctx context.Context, doc *entity.Document) error
+	// FindByScanStatus returns up to limit live documents that are in
+	// storage and were not tried since attemptedBefore, the least tried and
+	// then the oldest first.
+	FindByScanStatus(ctx context.Context, status string, attemptedBefore time.Time, limit int) ([]*entity.Document, error)
+	// RecordScanFailure counts a failed scan of a pending document, moving
+	// it to the error status with failure as its result after maxAttempts.
+	RecordScanFailure(ctx context.Context, id, failure string, attemptedAt time.Time, maxAttempts int) error
+
+	Trash(ctx context.Context, id string, deletedAt time.Time) error
+	Restore(ctx context.Context, id string) error
+	// FindTrashed returns trashed documents matching filter, most recently
+	// trashed first.
+	FindTrashed(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error)
+	FindTrashedBefore(ctx context.Context, before time.Time) ([]*entity.Document, error)
+
+	// FindStored returns every document of every tenant, trashed ones
+	// included, as each of them has an object in storage. Only the id,
+	// tenant, file name, storage key and missing mark are filled in.
+	FindStored(ctx context.Context) ([]*entity.Document, error)
+	// UpdateMissing marks a document's object missing at missingAt, or found
+	// again when missingAt is nil.
+	UpdateMissing(ctx context.Context, id string, missingAt *time.Time) error
 
 	Ping(ctx context.Context) error
 }
+
+type FolderRepository interface {
+	Save(ctx context.Context, folder *entity.Folder) error
+	FindById(ctx context.Context, id string) (*entity.Folder, error)
+	// FindChildren lists a folder's subfolders; a nil parentID lists the tenant's root folders.
+	FindChildren(ctx context.Context, tenantID string, parentID *string) ([]*entity.Folder, error)
+	Update(ctx context.Context, folder *entity.Folder) error
+	Move(ctx context.Context, folder *entity.Folder, parent *entity.Folder) error
+	DeleteSubtree(ctx context.Context, folder *entity.Folder) error
+	Stats(ctx context.Context, folder *entity.Folder) (*entity.FolderStats, error)
+}
+
+type RetentionPolicyRepository interface {
+	Save(ctx context.Context, policy *entity.RetentionPolicy) error
+	FindById(ctx context.Context, id string) (*entity.RetentionPolicy, error)
+	FindAll(ctx context.Context) ([]*entity.RetentionPolicy, error)
+	Update(ctx context.Context, policy *entity.RetentionPolicy) error
+	Delete(ctx context.Context, id string) error
+}
+
+type LegalHoldRepository interface {
+	Save(ctx context.Context, hold *entity.LegalHold) error
+	FindById(ctx context.Context, id string) (*entity.LegalHold, error)
+	FindByDocument(ctx context.Context, documentID string, activeOnly bool) ([]*entity.LegalHold, error)
+	Release(ctx context.Context, hold *entity.LegalHold) error
+	CountActive(ctx context.Context, documentID string) (int64, error)
+}
+
+type AuditRepository interface {
+	Append(ctx context.Context, entry *entity.AuditEntry) error
+	Last(ctx context.Context) (*entity.AuditEntry, error)
+	FindAll(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error)
+	Count(ctx context.Context, filter entity.AuditFilter) (int64, error)
+	FindAfter(ctx context.Context, afterSeq int64, limit int) ([]*entity.AuditEntry, error)
+}
+
+type APIKeyRepository interface {
+	Save(ctx context.Context, key *entity.APIKey) error
+	FindById(ctx context.Context, id string) (*entity.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	FindAll(ctx context.Context) ([]*entity.APIKey, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type DocumentACLRepository interface {
+	Save(ctx context.Context, entry *entity.ACLEntry) error
+	FindByDocument(ctx context.Context, documentID string) ([]*entity.ACLEntry, error)
+	// FindPermissions returns the distinct permissions granted on a document to any of subjects.
+	FindPermissions(ctx context.Context, documentID string, subjects []string) ([]string, error)
+	Delete(ctx context.Context, documentID, id string) error
+}
+
+type ShareLinkRepository interface {
+	Save(ctx context.Context, link *entity.ShareLink) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.ShareLink, error)
+	FindByDocument(ctx context.Context, documentID string) ([]*entity.ShareLink, error)
+	Revoke(ctx context.Context, documentID, id string, revokedAt time.Time) error
+	// ConsumeDownload atomically counts one download, failing with
+	// ErrShareLinkUnavailable when the link is revoked, expired or used up.
+	ConsumeDownload(ctx context.Context, id string, now time.Time) error
+}
+
+type TenantRepository interface {
+	Save(ctx context.Context, tenant *entity.Tenant) error
+	FindById(ctx context.Context, id string) (*entity.Tenant, error)
+	FindAll(ctx context.Context) ([]*entity.Tenant, error)
+	Update(ctx context.Context, tenant *entity.Tenant) error
+	// Delete removes a tenant that no longer owns documents or folders.
+	Delete(ctx context.Context, id string) error
+}
+
+type UsageRepository interface {
+	// Find returns zero usage for subjects that have never stored anything.
+	Find(ctx context.Context, scope, subjectID string) (*entity.Usage, error)
+	Reconcile(ctx context.Context) ([]*entity.Usage, error)
+}
+
+type ThumbnailRepository interface {
+	// Save inserts or replaces the thumbnail of one size.
+	Save(ctx context.Context, thumbnail *entity.Thumbnail) error
+	Find(ctx context.Context, documentID string, size int) (*entity.Thumbnail, error)
+	FindByDocument(ctx context.Context, documentID string) ([]*entity.Thumbnail, error)
+}
+
+type ImportRepository interface {
+	Save(ctx context.Context, imp *entity.Import) error
+	// Update stores the status and counts of imp and appends results not
+	// stored yet, so progress can be saved as an import runs.
+	Update(ctx context.Context, imp *entity.Import) error
+	FindById(ctx context.Context, id string) (*entity.Import, error)
+}
+
+type StorageMigrationRepository interface {
+	Save(ctx context.Context, migration *entity.StorageMigration) error
+	// Update stores the status and timestamps of migration.
+	Update(ctx context.Context, migration *entity.StorageMigration) error
+	// FindLatest returns the most recent migration, failing with
+	// ErrStorageMigrationNotFound when there has been none.
+	FindLatest(ctx context.Context) (*entity.StorageMigration, error)
+	// SaveItem records the outcome of copying one object, replacing an
+	// earlier attempt.
+	SaveItem(ctx context.Context, item *entity.StorageMigrationItem) error
+	FindItems(ctx context.Context, migrationID string) ([]*entity.StorageMigrationItem, error)
+}
+
+type JobRepository interface {
+	Save(ctx context.Context, job *entity.Job) error
+	FindById(ctx context.Context, id string) (*entity.Job, error)
+	FindAll(ctx context.Context, filter entity.JobFilter) ([]*entity.Job, error)
+	FindByStatus(ctx context.Context, status string) ([]*entity.Job, error)
+	// Claim marks the oldest queued job running and returns it, or nil when
+	// none is queued. Concurrent callers never claim the same job.
+	Claim(ctx context.Context, now time.Time) (*entity.Job, error)
+	// UpdateProgress stores a running job's progress and reports whether it
+	// has been asked to cancel.
+	UpdateProgress(ctx context.Context, id string, progress, total int64, now time.Time) (bool, error)
+	// Finish stores the final status, result, artifact and error of job.
+	Finish(ctx context.Context, job *entity.Job) error
+	// RequestCancel cancels a queued job outright and flags a running one so
+	// that it stops. It fails with ErrJobFinished for finished jobs.
+	RequestCancel(ctx context.Context, id string, now time.Time) error
+	// Requeue puts a running job back in the queue.
+	Requeue(ctx context.Context, id string, now time.Time) error
+	// Release puts a running job back in the queue without counting the
+	// attempt it was on, unless it was asked to stop.
+	Release(ctx context.Context, id string, now time.Time) error
+	FindFinishedBefore(ctx context.Context, before time.Time) ([]*entity.Job, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+)
+
+const aclEntryColumns = `id, document_id, subject, permission, created_by, created_at`
+
+type SQLiteDocumentACLRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteDocumentACLRepository(db *sql.DB) DocumentACLRepository {
+	return &SQLiteDocumentACLRepository{db: db}
+}
+
+func (r *SQLiteDocumentACLRepository) Save(ctx context.Context, entry *entity.ACLEntry) error {
+	insertQuery := `INSERT INTO document_acl (` + aclEntryColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, insertQuery, entry.ID, entry.DocumentID, entry.Subject, entry.Permission, entry.CreatedBy, entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error inserting acl entry %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteDocumentACLRepository) FindByDocument(ctx context.Context, documentID string) ([]*entity.ACLEntry, error) {
+	findByDocumentQuery := `SELECT ` + aclEntryColumns + ` FROM document_acl WHERE document_id = ? ORDER BY subject, permission`
+
+	rows, err := r.db.QueryContext(ctx, findByDocumentQuery, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding acl entries %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*entity.ACLEntry{}
+	for rows.Next() {
+		entry := &entity.ACLEntry{}
+		if err := rows.Scan(&entry.ID, &entry.DocumentID, &entry.Subject, &entry.Permission, &entry.CreatedBy, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning acl entry %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *SQLiteDocumentACLRepository) FindPermissions(ctx context.Context, documentID string, subjects []string) ([]string, error) {
+	if len(subjects) == 0 {
+		return []string{}, nil
+	}
+
+	args := []any{documentID}
+	for _, subject := range subjects {
+		args = append(args, subject)
+	}
+
+	findPermissionsQuery := `SELECT DISTINCT permission FROM document_acl WHERE document_id = ? AND subject IN (` + placeholders(len(subjects)) + `) ORDER BY permission`
+
+	rows, err := r.db.QueryContext(ctx, findPermissionsQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding acl permissions %w", err)
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, fmt.Errorf("error scanning acl permission %w", err)
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, rows.Err()
+}
+
+func (r *SQLiteDocumentACLRepository) Delete(ctx context.Context, documentID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM document_acl WHERE id = ? AND document_id = ?`, id, documentID)
+	if err != nil {
+		return fmt.Errorf("error deleting acl entry %w", err)
+	}
+
+	return requireAffected(result, entity.ErrACLEntryNotFound)
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const apiKeyColumns = `id, name, prefix, secret_hash, permissions, created_at, expires_at, last_used_at, revoked_at, COALESCE(tenant_id, '')`
+
+type SQLiteAPIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &SQLiteAPIKeyRepository{db: db}
+}
+
+func scanAPIKey(row rowScanner) (*entity.APIKey, error) {
+	key := &entity.APIKey{}
+	var permissions string
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.SecretHash, &permissions, &key.CreatedAt,
+		&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Permissions = []string{}
+	if permissions != "" {
+		key.Permissions = strings.Split(permissions, ",")
+	}
+
+	return key, nil
+}
+
+func (r *SQLiteAPIKeyRepository) Save(ctx context.Context, key *entity.APIKey) error {
+	insertQuery := `INSERT INTO api_keys (id, name, prefix, secret_hash, permissions, created_at, expires_at, last_used_at, revoked_at, tenant_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	var tenantID *string
+	if key.TenantID != "" {
+		tenantID = &key.TenantID
+	}
+
+	_, err := r.db.ExecContext(ctx, insertQuery, key.ID, key.Name, key.Prefix, key.SecretHash, strings.Join(key.Permissions, ","),
+		key.CreatedAt, key.ExpiresAt, key.LastUsedAt, key.RevokedAt, tenantID)
+	if err != nil {
+		return fmt.Errorf("error inserting api key %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteAPIKeyRepository) FindById(ctx context.Context, id string) (*entity.APIKey, error) {
+	return r.findOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`, id)
+}
+
+func (r *SQLiteAPIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	return r.findOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = ?`, prefix)
+}
+
+func (r *SQLiteAPIKeyRepository) findOne(ctx context.Context, query string, arg string) (*entity.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("error fetching api key %w", err)
+	}
+
+	return key, nil
+}
+
+func (r *SQLiteAPIKeyRepository) FindAll(ctx context.Context) ([]*entity.APIKey, error) {
+	findAllQuery := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, findAllQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error finding api keys %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*entity.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning api key %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *SQLiteAPIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	revokeQuery := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, revokeQuery, revokedAt, id)
+	if err != nil {
+		return fmt.Errorf("error revoking api key %w", err)
+	}
+
+	return requireAffected(result, entity.ErrAPIKeyNotFound)
+}
+
+func (r *SQLiteAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	touchQuery := `UPDATE api_keys SET last_used_at = ? WHERE id = ?`
+
+	if _, err := r.db.ExecContext(ctx, touchQuery, usedAt, id); err != nil {
+		return fmt.Errorf("error updating api key last use %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+	"strings"
+)
+
+const auditColumns = `seq, timestamp, action, document_id, actor, client_ip, user_agent, request_id, outcome, detail, prev_hash, hash`
+
+type SQLiteAuditRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAuditRepository(db *sql.DB) AuditRepository {
+	return &SQLiteAuditRepository{db: db}
+}
+
+func scanAuditEntry(row rowScanner) (*entity.AuditEntry, error) {
+	entry := &entity.AuditEntry{}
+	err := row.Scan(&entry.Seq, &entry.Timestamp, &entry.Action, &entry.DocumentID, &entry.Actor, &entry.ClientIP,
+		&entry.UserAgent, &entry.RequestID, &entry.Outcome, &entry.Detail, &entry.PrevHash, &entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *SQLiteAuditRepository) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	insertQuery := `INSERT INTO audit_log (timestamp, action, document_id, actor, client_ip, user_agent, request_id, outcome, detail, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := r.db.ExecContext(ctx, insertQuery, entry.Timestamp.UTC(), entry.Action, entry.DocumentID, entry.Actor, entry.ClientIP,
+		entry.UserAgent, entry.RequestID, entry.Outcome, entry.Detail, entry.PrevHash, entry.Hash)
+	if err != nil {
+		return fmt.Errorf("error inserting audit entry %w", err)
+	}
+
+	seq, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error reading audit sequence %w", err)
+	}
+	entry.Seq = seq
+
+	return nil
+}
+
+func (r *SQLiteAuditRepository) Last(ctx context.Context) (*entity.AuditEntry, error) {
+	lastQuery := `SELECT ` + auditColumns + ` FROM audit_log ORDER BY seq DESC LIMIT 1`
+
+	entry, err := scanAuditEntry(r.db.QueryRowContext(ctx, lastQuery))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching last audit entry %w", err)
+	}
+
+	return entry, nil
+}
+
+func auditFilterClause(filter entity.AuditFilter) (string, []any) {
+	conditions := []string{"1 = 1"}
+	var args []any
+
+	for _, field := range []struct{ column, value string }{
+		{"document_id", filter.DocumentID},
+		{"actor", filter.Actor},
+		{"action", filter.Action},
+		{"outcome", filter.Outcome},
+	} {
+		if field.value != "" {
+			conditions = append(conditions, field.column+` = ?`)
+			args = append(args, field.value)
+		}
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, `timestamp >= ?`)
+		args = append(args, filter.From.UTC())
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, `timestamp < ?`)
+		args = append(args, filter.To.UTC())
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func (r *SQLiteAuditRepository) FindAll(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	where, args := auditFilterClause(filter)
+	findAllQuery := `SELECT ` + auditColumns + ` FROM audit_log WHERE ` + where + ` ORDER BY seq DESC`
+	if filter.Limit > 0 {
+		findAllQuery += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	return r.queryEntries(ctx, findAllQuery, args...)
+}
+
+func (r *SQLiteAuditRepository) Count(ctx context.Context, filter entity.AuditFilter) (int64, error) {
+	where, args := auditFilterClause(filter)
+	countQuery := `SELECT COUNT(*) FROM audit_log WHERE ` + where
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting audit entries %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *SQLiteAuditRepository) FindAfter(ctx context.Context, afterSeq int64, limit int) ([]*entity.AuditEntry, error) {
+	findAfterQuery := `SELECT ` + auditColumns + ` FROM audit_log WHERE seq > ? ORDER BY seq LIMIT ?`
+
+	return r.queryEntries(ctx, findAfterQuery, afterSeq, limit)
+}
+
+func (r *SQLiteAuditRepository) queryEntries(ctx context.Context, query string, args ...any) ([]*entity.AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding audit entries %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*entity.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning audit entry %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
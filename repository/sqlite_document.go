@@ -5,9 +5,18 @@ import (
 	"database/sql"
 	"docvault/entity"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
+const documentColumns = `id, file_name, file_size, content_type, created_at, expires_at, deleted_at, folder_id, COALESCE(storage_key, ''), version,
+	requested_expires_at, retain_forever, retention_policy_id, owner_id, tenant_id, scan_status, scan_result, scanned_at,
+	COALESCE(declared_content_type, ''), COALESCE(detected_content_type, ''), missing_at`
+
+// notOnHold keeps documents under an active legal hold out of automatic expiry and purging.
+const notOnHold = `NOT EXISTS (SELECT 1 FROM legal_holds h WHERE h.document_id = documents.id AND h.released_at IS NULL)`
+
 type SQLiteDocumentRepository struct {
 	db *sql.DB
 }
@@ -16,90 +25,567 @@ func NewSQLiteDocumentRepository(db *sql.DB) DocumentRepository {
 	return &SQLiteDocumentRepository{db: db}
 }
 
-func (r *SQLiteDocumentRepository) Save(ctx context.Context, doc *entity.Document) error {
-	insertQuery := `INSERT INTO documents (id, file_name, file_size, content_type, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)`
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDocument(row rowScanner) (*entity.Document, error) {
+	doc := &entity.Document{}
+	err := row.Scan(&doc.ID, &doc.FileName, &doc.FileSize, &doc.ContentType, &doc.CreatedAt, &doc.ExpiresAt, &doc.DeletedAt, &doc.FolderID, &doc.StorageKey, &doc.Version,
+		&doc.RequestedExpiresAt, &doc.RetainForever, &doc.RetentionPolicyID, &doc.OwnerID, &doc.TenantID, &doc.ScanStatus, &doc.ScanResult, &doc.ScannedAt,
+		&doc.DeclaredContentType, &doc.DetectedContentType, &doc.MissingAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func (r *SQLiteDocumentRepository) queryDocuments(ctx context.Context, query string, args ...any) ([]*entity.Document, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []*entity.Document
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning document %w", err)
+		}
+
+		documents = append(documents, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := r.loadAttributes(ctx, documents); err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+// Save inserts doc and adds it to its usage totals in one transaction, failing
+// with an *entity.QuotaError if that would break one of quotas.
+func (r *SQLiteDocumentRepository) Save(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error {
+	if doc.StorageKey == "" {
+		return fmt.Errorf("error inserting document %s without a storage key", doc.ID)
+	}
 
-	_, err := r.db.ExecContext(ctx, insertQuery, doc.ID, doc.FileName, doc.FileSize, doc.ContentType, doc.CreatedAt, doc.ExpiresAt)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("Error starting documents transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	if doc.Version == 0 {
+		doc.Version = 1
+	}
+
+	insertQuery := `INSERT INTO documents (id, file_name, file_size, content_type, created_at, expires_at, folder_id, storage_key, version,
+		requested_expires_at, retain_forever, retention_policy_id, owner_id, tenant_id, scan_status, scan_result, scanned_at,
+		declared_content_type, detected_content_type) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	if doc.ScanStatus == "" {
+		doc.ScanStatus = entity.ScanStatusPending
+	}
+
+	_, err = tx.ExecContext(ctx, insertQuery, doc.ID, doc.FileName, doc.FileSize, doc.ContentType, doc.CreatedAt, doc.ExpiresAt, doc.FolderID, doc.StorageKey, doc.Version,
+		doc.RequestedExpiresAt, doc.RetainForever, doc.RetentionPolicyID, doc.OwnerID, documentTenant(doc.TenantID), doc.ScanStatus, doc.ScanResult, doc.ScannedAt,
+		doc.DeclaredContentType, doc.DetectedContentType)
 	if err != nil {
 		return fmt.Errorf("Error inserting data to database documents %w", err)
 	}
 
-	return nil
+	if err := replaceAttributes(ctx, tx, doc); err != nil {
+		return err
+	}
+
+	// The insert above holds the write lock, so the totals read here cannot
+	// change before the transaction commits.
+	for _, quota := range quotas {
+		usage, err := findUsage(ctx, tx, quota.Scope, quota.SubjectID)
+		if err != nil {
+			return err
+		}
+		if err := quota.Check(usage, doc.FileSize, 1); err != nil {
+			return err
+		}
+	}
+
+	if err := adjustUsage(ctx, tx, doc.TenantID, doc.OwnerID, doc.FileSize, 1); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *SQLiteDocumentRepository) FindById(ctx context.Context, id string) (*entity.Document, error) {
-	findByIdQuery := `SELECT id, file_name, file_size, content_type, created_at, expires_at FROM documents WHERE id = ?`
-
-	row := r.db.QueryRowContext(ctx, findByIdQuery, id)
+	findByIdQuery := `SELECT ` + documentColumns + ` FROM documents WHERE id = ?`
 
-	doc := &entity.Document{}
-	err := row.Scan(&doc.ID, &doc.FileName, &doc.FileSize, &doc.ContentType, &doc.CreatedAt, &doc.ExpiresAt)
+	doc, err := scanDocument(r.db.QueryRowContext(ctx, findByIdQuery, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("document not found")
+			return nil, entity.ErrDocumentNotFound
 		}
 		return nil, fmt.Errorf("error fetching document %w", err)
 	}
 
+	if err := r.loadAttributes(ctx, []*entity.Document{doc}); err != nil {
+		return nil, err
+	}
+
 	return doc, nil
 }
 
-func (r *SQLiteDocumentRepository) FindAll(ctx context.Context) ([]*entity.Document, error) {
-	findAllQuery := `SELECT id, file_name, file_size, content_type, created_at, expires_at FROM documents ORDER BY created_at DESC`
+func (r *SQLiteDocumentRepository) FindAll(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+	where, args := documentFilterClause(filter)
 
-	rows, err := r.db.QueryContext(ctx, findAllQuery)
+	findAllQuery := `SELECT ` + documentColumns + ` FROM documents WHERE ` + where + ` ORDER BY created_at DESC, id`
+	if filter.Limit > 0 {
+		findAllQuery += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	documents, err := r.queryDocuments(ctx, findAllQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("Error finding data from documents %w", err)
 	}
-	defer rows.Close()
 
-	var documents []*entity.Document
-	for rows.Next() {
-		doc := &entity.Document{}
-		err := rows.Scan(&doc.ID, &doc.FileName, &doc.FileSize, &doc.ContentType, &doc.CreatedAt, &doc.ExpiresAt)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning document %w", err)
+	return documents, nil
+}
+
+func (r *SQLiteDocumentRepository) Count(ctx context.Context, filter entity.DocumentFilter) (int64, error) {
+	where, args := documentFilterClause(filter)
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM documents WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting documents %w", err)
+	}
+
+	return count, nil
+}
+
+func documentFilterClause(filter entity.DocumentFilter) (string, []any) {
+	return documentFilterConditions("deleted_at IS NULL", filter)
+}
+
+// documentFilterConditions joins state, which picks live or trashed
+// documents, with the conditions of filter.
+func documentFilterConditions(state string, filter entity.DocumentFilter) (string, []any) {
+	conditions := []string{state}
+	var args []any
+
+	if filter.TenantID != "" {
+		conditions = append(conditions, `tenant_id = ?`)
+		args = append(args, filter.TenantID)
+	}
+
+	if tags := uniqueStrings(filter.Tags); len(tags) > 0 {
+		conditions = append(conditions, `id IN (SELECT document_id FROM document_tags WHERE tag IN (`+placeholders(len(tags))+`) GROUP BY document_id HAVING COUNT(DISTINCT tag) = ?)`)
+		for _, tag := range tags {
+			args = append(args, tag)
 		}
+		args = append(args, len(tags))
+	}
 
-		documents = append(documents, doc)
+	keys := make([]string, 0, len(filter.Metadata))
+	for key := range filter.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM document_metadata m WHERE m.document_id = documents.id AND m.key = ? AND m.value = ?)`)
+		args = append(args, key, filter.Metadata[key])
 	}
 
-	return documents, nil
+	if filter.FolderID != nil {
+		if *filter.FolderID == "" {
+			conditions = append(conditions, `folder_id IS NULL`)
+		} else {
+			conditions = append(conditions, `folder_id = ?`)
+			args = append(args, *filter.FolderID)
+		}
+	}
+
+	if filter.FolderPath != "" {
+		lower, upper := subtreeBounds(filter.FolderPath)
+		conditions = append(conditions, `folder_id IN (SELECT id FROM folders WHERE path >= ? AND path < ?)`)
+		args = append(args, lower, upper)
+	}
+
+	if filter.Subjects != nil {
+		in := placeholders(len(filter.Subjects))
+		conditions = append(conditions, `(owner_id IS NULL OR owner_id IN (`+in+`) OR EXISTS (SELECT 1 FROM document_acl a WHERE a.document_id = documents.id AND a.subject IN (`+in+`)))`)
+		subjects := make([]any, 0, len(filter.Subjects))
+		for _, subject := range filter.Subjects {
+			subjects = append(subjects, subject)
+		}
+		args = append(args, subjects...)
+		args = append(args, subjects...)
+	}
+
+	return strings.Join(conditions, " AND "), args
 }
 
 func (r *SQLiteDocumentRepository) Delete(ctx context.Context, id string) error {
-	deleteQuery := `DELETE FROM documents where id=?`
-
-	_, err := r.db.ExecContext(ctx, deleteQuery, id)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("error deleting document %w", err)
+		return fmt.Errorf("error starting documents transaction %w", err)
 	}
+	defer tx.Rollback()
 
-	return nil
+	var (
+		fileSize sql.NullInt64
+		tenantID string
+		ownerID  *string
+	)
+	usageQuery := `SELECT file_size, tenant_id, owner_id FROM documents WHERE id = ?`
+	err = tx.QueryRowContext(ctx, usageQuery, id).Scan(&fileSize, &tenantID, &ownerID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error fetching document %w", err)
+	}
+	found := err == nil
+
+	for _, deleteQuery := range []string{
+		`DELETE FROM document_tags WHERE document_id=?`,
+		`DELETE FROM document_metadata WHERE document_id=?`,
+		`DELETE FROM document_acl WHERE document_id=?`,
+		`DELETE FROM share_links WHERE document_id=?`,
+		`DELETE FROM document_thumbnails WHERE document_id=?`,
+		`DELETE FROM documents where id=?`,
+	} {
+		if _, err := tx.ExecContext(ctx, deleteQuery, id); err != nil {
+			return fmt.Errorf("error deleting document %w", err)
+		}
+	}
+
+	if found {
+		if err := adjustUsage(ctx, tx, tenantID, ownerID, -fileSize.Int64, -1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *SQLiteDocumentRepository) FindExpired(ctx context.Context, now time.Time) ([]*entity.Document, error) {
-	findExpiredQuery := `SELECT id, file_name, file_size, content_type, created_at, expires_at FROM documents WHERE expires_at < ?`
+	findExpiredQuery := `SELECT ` + documentColumns + ` FROM documents WHERE expires_at < ? AND deleted_at IS NULL AND ` + notOnHold
 
-	docs, err := r.db.QueryContext(ctx, findExpiredQuery, now)
+	documents, err := r.queryDocuments(ctx, findExpiredQuery, now)
 	if err != nil {
 		return nil, fmt.Errorf("error finding expired documents %w", err)
 	}
 
+	return documents, nil
+}
+
+func (r *SQLiteDocumentRepository) Update(ctx context.Context, doc *entity.Document, expectedVersion int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting documents transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	updateQuery := `UPDATE documents SET file_name = ?, content_type = ?, expires_at = ?, folder_id = ?,
+		requested_expires_at = ?, retain_forever = ?, retention_policy_id = ?, version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL`
+
+	result, err := tx.ExecContext(ctx, updateQuery, doc.FileName, doc.ContentType, doc.ExpiresAt, doc.FolderID,
+		doc.RequestedExpiresAt, doc.RetainForever, doc.RetentionPolicyID, doc.ID, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("error updating document %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error reading affected rows %w", err)
+	}
+	if affected == 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM documents WHERE id = ? AND deleted_at IS NULL)`, doc.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("error checking document %w", err)
+		}
+		if exists {
+			return entity.ErrVersionConflict
+		}
+		return entity.ErrDocumentNotFound
+	}
+
+	if err := replaceAttributes(ctx, tx, doc); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing document update %w", err)
+	}
+
+	doc.Version = expectedVersion + 1
+	return nil
+}
+
+func (r *SQLiteDocumentRepository) UpdateRetention(ctx context.Context, doc *entity.Document) error {
+	updateRetentionQuery := `UPDATE documents SET retention_policy_id = ?, expires_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, updateRetentionQuery, doc.RetentionPolicyID, doc.ExpiresAt, doc.ID)
+	if err != nil {
+		return fmt.Errorf("error updating document retention %w", err)
+	}
+
+	return requireAffected(result, entity.ErrDocumentNotFound)
+}
+
+// UpdateScan records a scan result; quarantining changes the storage key too.
+func (r *SQLiteDocumentRepository) UpdateScan(ctx context.Context, doc *entity.Document) error {
+	updateScanQuery := `UPDATE documents SET scan_status = ?, scan_result = ?, scanned_at = ?, storage_key = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, updateScanQuery, doc.ScanStatus, doc.ScanResult, doc.ScannedAt, doc.StorageKey, doc.ID)
+	if err != nil {
+		return fmt.Errorf("error updating document scan status %w", err)
+	}
+
+	return requireAffected(result, entity.ErrDocumentNotFound)
+}
+
+// RecordScanFailure only touches pending documents, so that a scan finishing
+// in between is not undone.
+func (r *SQLiteDocumentRepository) RecordScanFailure(ctx context.Context, id, failure string, attemptedAt time.Time, maxAttempts int) error {
+	recordScanFailureQuery := `UPDATE documents SET scan_attempts = scan_attempts + 1, scan_attempted_at = ?,
+		scan_status = CASE WHEN scan_attempts + 1 >= ? THEN ? ELSE scan_status END,
+		scan_result = CASE WHEN scan_attempts + 1 >= ? THEN ? ELSE scan_result END
+		WHERE id = ? AND scan_status = ?`
+
+	_, err := r.db.ExecContext(ctx, recordScanFailureQuery, attemptedAt, maxAttempts, entity.ScanStatusError, maxAttempts, failure, id, entity.ScanStatusPending)
+	if err != nil {
+		return fmt.Errorf("error recording document scan failure %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteDocumentRepository) FindByScanStatus(ctx context.Context, status string, attemptedBefore time.Time, limit int) ([]*entity.Document, error) {
+	findByScanStatusQuery := `SELECT ` + documentColumns + ` FROM documents
+		WHERE scan_status = ? AND deleted_at IS NULL AND missing_at IS NULL AND (scan_attempted_at IS NULL OR scan_attempted_at < ?)
+		ORDER BY scan_attempts, scan_attempted_at, created_at, id LIMIT ?`
+
+	documents, err := r.queryDocuments(ctx, findByScanStatusQuery, status, attemptedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error finding documents by scan status %w", err)
+	}
+
+	return documents, nil
+}
+
+func (r *SQLiteDocumentRepository) Trash(ctx context.Context, id string, deletedAt time.Time) error {
+	trashQuery := `UPDATE documents SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, trashQuery, deletedAt, id)
+	if err != nil {
+		return fmt.Errorf("error moving document to trash %w", err)
+	}
+
+	return requireAffected(result, entity.ErrDocumentNotFound)
+}
+
+func (r *SQLiteDocumentRepository) Restore(ctx context.Context, id string) error {
+	restoreQuery := `UPDATE documents SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+
+	result, err := r.db.ExecContext(ctx, restoreQuery, id)
+	if err != nil {
+		return fmt.Errorf("error restoring document %w", err)
+	}
+
+	return requireAffected(result, entity.ErrDocumentNotTrashed)
+}
+
+func (r *SQLiteDocumentRepository) FindTrashed(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+	where, args := documentFilterConditions("deleted_at IS NOT NULL", filter)
+
+	findTrashedQuery := `SELECT ` + documentColumns + ` FROM documents WHERE ` + where + ` ORDER BY deleted_at DESC, id`
+	if filter.Limit > 0 {
+		findTrashedQuery += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	documents, err := r.queryDocuments(ctx, findTrashedQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding trashed documents %w", err)
+	}
+
+	return documents, nil
+}
+
+func (r *SQLiteDocumentRepository) FindTrashedBefore(ctx context.Context, before time.Time) ([]*entity.Document, error) {
+	findTrashedBeforeQuery := `SELECT ` + documentColumns + ` FROM documents WHERE deleted_at IS NOT NULL AND deleted_at < ? AND ` + notOnHold
+
+	documents, err := r.queryDocuments(ctx, findTrashedBeforeQuery, before)
+	if err != nil {
+		return nil, fmt.Errorf("error finding trashed documents %w", err)
+	}
+
+	return documents, nil
+}
+
+// FindStored only reads the columns reconciliation needs, so that it scales
+// to every document in the store.
+func (r *SQLiteDocumentRepository) FindStored(ctx context.Context) ([]*entity.Document, error) {
+	findStoredQuery := `SELECT id, tenant_id, file_name, COALESCE(storage_key, ''), missing_at FROM documents ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, findStoredQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error finding stored documents %w", err)
+	}
+	defer rows.Close()
+
 	var documents []*entity.Document
-	for docs.Next() {
+	for rows.Next() {
 		doc := &entity.Document{}
-		err := docs.Scan(&doc.ID, &doc.FileName, &doc.FileSize, &doc.ContentType, &doc.CreatedAt, &doc.ExpiresAt)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning document %w", err)
+		if err := rows.Scan(&doc.ID, &doc.TenantID, &doc.FileName, &doc.StorageKey, &doc.MissingAt); err != nil {
+			return nil, fmt.Errorf("error scanning stored document %w", err)
 		}
-
 		documents = append(documents, doc)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error finding stored documents %w", err)
+	}
 
 	return documents, nil
 }
 
+func (r *SQLiteDocumentRepository) UpdateMissing(ctx context.Context, id string, missingAt *time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE documents SET missing_at = ? WHERE id = ?`, missingAt, id)
+	if err != nil {
+		return fmt.Errorf("error marking document missing %w", err)
+	}
+
+	return requireAffected(result, entity.ErrDocumentNotFound)
+}
+
 func (r *SQLiteDocumentRepository) Ping(ctx context.Context) error {
 	return r.db.PingContext(ctx)
 }
+
+// attributeBatchSize bounds the ids bound in one attribute query, well below
+// SQLite's limit on variables per statement.
+const attributeBatchSize = 500
+
+func (r *SQLiteDocumentRepository) loadAttributes(ctx context.Context, documents []*entity.Document) error {
+	for start := 0; start < len(documents); start += attributeBatchSize {
+		end := min(start+attributeBatchSize, len(documents))
+		if err := r.loadAttributeBatch(ctx, documents[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLiteDocumentRepository) loadAttributeBatch(ctx context.Context, documents []*entity.Document) error {
+	byID := make(map[string]*entity.Document, len(documents))
+	args := make([]any, 0, len(documents))
+	for _, doc := range documents {
+		doc.Tags = []string{}
+		doc.Metadata = map[string]string{}
+		byID[doc.ID] = doc
+		args = append(args, doc.ID)
+	}
+	in := placeholders(len(documents))
+
+	tagRows, err := r.db.QueryContext(ctx, `SELECT document_id, tag FROM document_tags WHERE document_id IN (`+in+`) ORDER BY tag`, args...)
+	if err != nil {
+		return fmt.Errorf("error fetching document tags %w", err)
+	}
+	defer tagRows.Close()
+
+	for tagRows.Next() {
+		var documentID, tag string
+		if err := tagRows.Scan(&documentID, &tag); err != nil {
+			return fmt.Errorf("error scanning document tag %w", err)
+		}
+		byID[documentID].Tags = append(byID[documentID].Tags, tag)
+	}
+	if err := tagRows.Err(); err != nil {
+		return fmt.Errorf("error fetching document tags %w", err)
+	}
+	tagRows.Close()
+
+	metadataRows, err := r.db.QueryContext(ctx, `SELECT document_id, key, value FROM document_metadata WHERE document_id IN (`+in+`)`, args...)
+	if err != nil {
+		return fmt.Errorf("error fetching document metadata %w", err)
+	}
+	defer metadataRows.Close()
+
+	for metadataRows.Next() {
+		var documentID, key, value string
+		if err := metadataRows.Scan(&documentID, &key, &value); err != nil {
+			return fmt.Errorf("error scanning document metadata %w", err)
+		}
+		byID[documentID].Metadata[key] = value
+	}
+
+	return metadataRows.Err()
+}
+
+func replaceAttributes(ctx context.Context, tx *sql.Tx, doc *entity.Document) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_tags WHERE document_id = ?`, doc.ID); err != nil {
+		return fmt.Errorf("error clearing document tags %w", err)
+	}
+	for _, tag := range uniqueStrings(doc.Tags) {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO document_tags (document_id, tag) VALUES (?, ?)`, doc.ID, tag); err != nil {
+			return fmt.Errorf("error inserting document tag %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_metadata WHERE document_id = ?`, doc.ID); err != nil {
+		return fmt.Errorf("error clearing document metadata %w", err)
+	}
+	for key, value := range doc.Metadata {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO document_metadata (document_id, key, value) VALUES (?, ?, ?)`, doc.ID, key, value); err != nil {
+			return fmt.Errorf("error inserting document metadata %w", err)
+		}
+	}
+
+	return nil
+}
+
+// documentTenant places documents saved without a tenant in the default one.
+func documentTenant(tenantID string) string {
+	if tenantID == "" {
+		return entity.DefaultTenantID
+	}
+
+	return tenantID
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	return unique
+}
+
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error reading affected rows %w", err)
+	}
+	if affected == 0 {
+		return notFound
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const folderColumns = `id, name, parent_id, path, created_at, updated_at, tenant_id`
+
+type SQLiteFolderRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteFolderRepository(db *sql.DB) FolderRepository {
+	return &SQLiteFolderRepository{db: db}
+}
+
+func scanFolder(row rowScanner) (*entity.Folder, error) {
+	folder := &entity.Folder{}
+	err := row.Scan(&folder.ID, &folder.Name, &folder.ParentID, &folder.Path, &folder.CreatedAt, &folder.UpdatedAt, &folder.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+func (r *SQLiteFolderRepository) Save(ctx context.Context, folder *entity.Folder) error {
+	insertQuery := `INSERT INTO folders (` + folderColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, insertQuery, folder.ID, folder.Name, folder.ParentID, folder.Path, folder.CreatedAt, folder.UpdatedAt, documentTenant(folder.TenantID))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.ErrFolderExists
+		}
+		return fmt.Errorf("error inserting folder %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteFolderRepository) FindById(ctx context.Context, id string) (*entity.Folder, error) {
+	findByIdQuery := `SELECT ` + folderColumns + ` FROM folders WHERE id = ?`
+
+	folder, err := scanFolder(r.db.QueryRowContext(ctx, findByIdQuery, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.ErrFolderNotFound
+		}
+		return nil, fmt.Errorf("error fetching folder %w", err)
+	}
+
+	return folder, nil
+}
+
+func (r *SQLiteFolderRepository) FindChildren(ctx context.Context, tenantID string, parentID *string) ([]*entity.Folder, error) {
+	findChildrenQuery := `SELECT ` + folderColumns + ` FROM folders WHERE parent_id IS NULL AND tenant_id = ? ORDER BY name`
+	args := []any{documentTenant(tenantID)}
+	if parentID != nil {
+		findChildrenQuery = `SELECT ` + folderColumns + ` FROM folders WHERE parent_id = ? ORDER BY name`
+		args = []any{*parentID}
+	}
+
+	rows, err := r.db.QueryContext(ctx, findChildrenQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding folders %w", err)
+	}
+	defer rows.Close()
+
+	folders := []*entity.Folder{}
+	for rows.Next() {
+		folder, err := scanFolder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning folder %w", err)
+		}
+
+		folders = append(folders, folder)
+	}
+
+	return folders, rows.Err()
+}
+
+func (r *SQLiteFolderRepository) Update(ctx context.Context, folder *entity.Folder) error {
+	updateQuery := `UPDATE folders SET name = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, updateQuery, folder.Name, folder.UpdatedAt, folder.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.ErrFolderExists
+		}
+		return fmt.Errorf("error updating folder %w", err)
+	}
+
+	return requireAffected(result, entity.ErrFolderNotFound)
+}
+
+func (r *SQLiteFolderRepository) Move(ctx context.Context, folder *entity.Folder, parent *entity.Folder) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting folders transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	oldPath := folder.Path
+	newPath := "/" + folder.ID + "/"
+	var parentID *string
+	if parent != nil {
+		newPath = parent.Path + folder.ID + "/"
+		parentID = &parent.ID
+	}
+
+	moveQuery := `UPDATE folders SET parent_id = ?, updated_at = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, moveQuery, parentID, folder.UpdatedAt, folder.ID); err != nil {
+		if isUniqueViolation(err) {
+			return entity.ErrFolderExists
+		}
+		return fmt.Errorf("error moving folder %w", err)
+	}
+
+	lower, upper := subtreeBounds(oldPath)
+	rewritePathsQuery := `UPDATE folders SET path = ? || substr(path, ?) WHERE path >= ? AND path < ?`
+	if _, err := tx.ExecContext(ctx, rewritePathsQuery, newPath, len(oldPath)+1, lower, upper); err != nil {
+		return fmt.Errorf("error rewriting folder paths %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing folder move %w", err)
+	}
+
+	folder.ParentID = parentID
+	folder.Path = newPath
+	return nil
+}
+
+func (r *SQLiteFolderRepository) DeleteSubtree(ctx context.Context, folder *entity.Folder) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting folders transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	lower, upper := subtreeBounds(folder.Path)
+
+	detachDocumentsQuery := `UPDATE documents SET folder_id = NULL WHERE folder_id IN (SELECT id FROM folders WHERE path >= ? AND path < ?)`
+	if _, err := tx.ExecContext(ctx, detachDocumentsQuery, lower, upper); err != nil {
+		return fmt.Errorf("error detaching documents from folder %w", err)
+	}
+
+	deleteQuery := `DELETE FROM folders WHERE path >= ? AND path < ?`
+	if _, err := tx.ExecContext(ctx, deleteQuery, lower, upper); err != nil {
+		return fmt.Errorf("error deleting folders %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteFolderRepository) Stats(ctx context.Context, folder *entity.Folder) (*entity.FolderStats, error) {
+	lower, upper := subtreeBounds(folder.Path)
+	stats := &entity.FolderStats{}
+
+	foldersQuery := `SELECT COUNT(*) - 1 FROM folders WHERE path >= ? AND path < ?`
+	if err := r.db.QueryRowContext(ctx, foldersQuery, lower, upper).Scan(&stats.FolderCount); err != nil {
+		return nil, fmt.Errorf("error counting folders %w", err)
+	}
+
+	documentsQuery := `SELECT COUNT(*), COALESCE(SUM(file_size), 0) FROM documents
+		WHERE deleted_at IS NULL AND folder_id IN (SELECT id FROM folders WHERE path >= ? AND path < ?)`
+	if err := r.db.QueryRowContext(ctx, documentsQuery, lower, upper).Scan(&stats.DocumentCount, &stats.TotalSize); err != nil {
+		return nil, fmt.Errorf("error computing folder stats %w", err)
+	}
+
+	return stats, nil
+}
+
+// subtreeBounds returns the half-open range of materialized paths under path.
+// Paths always end in "/", so bumping that last byte to "0" bounds the subtree
+// without relying on LIKE, which SQLite cannot serve from the path index.
+func subtreeBounds(path string) (string, string) {
+	return path, strings.TrimSuffix(path, "/") + "0"
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+)
+
+const legalHoldColumns = `id, document_id, reason, created_by, created_at, released_by, released_at, release_reason`
+
+type SQLiteLegalHoldRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteLegalHoldRepository(db *sql.DB) LegalHoldRepository {
+	return &SQLiteLegalHoldRepository{db: db}
+}
+
+func scanLegalHold(row rowScanner) (*entity.LegalHold, error) {
+	hold := &entity.LegalHold{}
+	err := row.Scan(&hold.ID, &hold.DocumentID, &hold.Reason, &hold.CreatedBy, &hold.CreatedAt,
+		&hold.ReleasedBy, &hold.ReleasedAt, &hold.ReleaseReason)
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (r *SQLiteLegalHoldRepository) Save(ctx context.Context, hold *entity.LegalHold) error {
+	insertQuery := `INSERT INTO legal_holds (` + legalHoldColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, insertQuery, hold.ID, hold.DocumentID, hold.Reason, hold.CreatedBy, hold.CreatedAt,
+		hold.ReleasedBy, hold.ReleasedAt, hold.ReleaseReason)
+	if err != nil {
+		return fmt.Errorf("error inserting legal hold %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteLegalHoldRepository) FindById(ctx context.Context, id string) (*entity.LegalHold, error) {
+	findByIdQuery := `SELECT ` + legalHoldColumns + ` FROM legal_holds WHERE id = ?`
+
+	hold, err := scanLegalHold(r.db.QueryRowContext(ctx, findByIdQuery, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.ErrLegalHoldNotFound
+		}
+		return nil, fmt.Errorf("error fetching legal hold %w", err)
+	}
+
+	return hold, nil
+}
+
+func (r *SQLiteLegalHoldRepository) FindByDocument(ctx context.Context, documentID string, activeOnly bool) ([]*entity.LegalHold, error) {
+	findByDocumentQuery := `SELECT ` + legalHoldColumns + ` FROM legal_holds WHERE document_id = ?`
+	if activeOnly {
+		findByDocumentQuery += ` AND released_at IS NULL`
+	}
+	findByDocumentQuery += ` ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, findByDocumentQuery, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding legal holds %w", err)
+	}
+	defer rows.Close()
+
+	holds := []*entity.LegalHold{}
+	for rows.Next() {
+		hold, err := scanLegalHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning legal hold %w", err)
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+func (r *SQLiteLegalHoldRepository) Release(ctx context.Context, hold *entity.LegalHold) error {
+	releaseQuery := `UPDATE legal_holds SET released_by = ?, released_at = ?, release_reason = ? WHERE id = ? AND released_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, releaseQuery, hold.ReleasedBy, hold.ReleasedAt, hold.ReleaseReason, hold.ID)
+	if err != nil {
+		return fmt.Errorf("error releasing legal hold %w", err)
+	}
+
+	// Releasing is guarded on released_at so two concurrent releases cannot both succeed.
+	return requireAffected(result, entity.ErrLegalHoldReleased)
+}
+
+func (r *SQLiteLegalHoldRepository) CountActive(ctx context.Context, documentID string) (int64, error) {
+	countQuery := `SELECT COUNT(*) FROM legal_holds WHERE document_id = ? AND released_at IS NULL`
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, countQuery, documentID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting legal holds %w", err)
+	}
+
+	return count, nil
+}
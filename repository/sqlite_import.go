@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+)
+
+const importColumns = `id, tenant_id, file_name, folder_id, status, imported, skipped, failed, error, created_by, created_at, updated_at, completed_at`
+
+type SQLiteImportRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteImportRepository(db *sql.DB) ImportRepository {
+	return &SQLiteImportRepository{db: db}
+}
+
+func (r *SQLiteImportRepository) Save(ctx context.Context, imp *entity.Import) error {
+	saveQuery := `INSERT INTO imports (` + importColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, saveQuery, imp.ID, documentTenant(imp.TenantID), imp.FileName, imp.FolderID, imp.Status,
+		imp.Imported, imp.Skipped, imp.Failed, imp.Error, imp.CreatedBy, imp.CreatedAt, imp.UpdatedAt, imp.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("error saving import %w", err)
+	}
+
+	return r.Update(ctx, imp)
+}
+
+func (r *SQLiteImportRepository) Update(ctx context.Context, imp *entity.Import) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting imports transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	updateQuery := `UPDATE imports SET status = ?, imported = ?, skipped = ?, failed = ?, error = ?, updated_at = ?, completed_at = ? WHERE id = ?`
+	result, err := tx.ExecContext(ctx, updateQuery, imp.Status, imp.Imported, imp.Skipped, imp.Failed, imp.Error, imp.UpdatedAt, imp.CompletedAt, imp.ID)
+	if err != nil {
+		return fmt.Errorf("error updating import %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entity.ErrImportNotFound
+	}
+
+	var stored int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM import_results WHERE import_id = ?`, imp.ID).Scan(&stored); err != nil {
+		return fmt.Errorf("error counting import results %w", err)
+	}
+
+	insertResultQuery := `INSERT INTO import_results (import_id, position, path, status, document_id, error) VALUES (?, ?, ?, ?, ?, ?)`
+	for position := stored; position < len(imp.Results); position++ {
+		result := imp.Results[position]
+		if _, err := tx.ExecContext(ctx, insertResultQuery, imp.ID, position, result.Path, result.Status, result.DocumentID, result.Error); err != nil {
+			return fmt.Errorf("error saving import result %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing import %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteImportRepository) FindById(ctx context.Context, id string) (*entity.Import, error) {
+	findQuery := `SELECT ` + importColumns + ` FROM imports WHERE id = ?`
+
+	imp := &entity.Import{Results: []entity.ImportResult{}}
+	err := r.db.QueryRowContext(ctx, findQuery, id).Scan(&imp.ID, &imp.TenantID, &imp.FileName, &imp.FolderID, &imp.Status,
+		&imp.Imported, &imp.Skipped, &imp.Failed, &imp.Error, &imp.CreatedBy, &imp.CreatedAt, &imp.UpdatedAt, &imp.CompletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.ErrImportNotFound
+		}
+		return nil, fmt.Errorf("error fetching import %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT path, status, document_id, error FROM import_results WHERE import_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching import results %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result entity.ImportResult
+		if err := rows.Scan(&result.Path, &result.Status, &result.DocumentID, &result.Error); err != nil {
+			return nil, fmt.Errorf("error scanning import result %w", err)
+		}
+		imp.Results = append(imp.Results, result)
+	}
+
+	return imp, rows.Err()
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const jobColumns = `id, tenant_id, kind, status, params, principal, progress, total, result,
+	artifact_key, artifact_name, artifact_content_type, artifact_size, error, attempts, cancel_requested,
+	created_by, created_at, updated_at, started_at, completed_at`
+
+type SQLiteJobRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteJobRepository(db *sql.DB) JobRepository {
+	return &SQLiteJobRepository{db: db}
+}
+
+func scanJob(row rowScanner) (*entity.Job, error) {
+	job := &entity.Job{}
+	var principal sql.NullString
+	err := row.Scan(&job.ID, &job.TenantID, &job.Kind, &job.Status, &job.Params, &principal, &job.Progress, &job.Total, &job.Result,
+		&job.ArtifactKey, &job.ArtifactName, &job.ArtifactContentType, &job.ArtifactSize, &job.Error, &job.Attempts, &job.CancelRequested,
+		&job.CreatedBy, &job.CreatedAt, &job.UpdatedAt, &job.StartedAt, &job.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal.Valid {
+		job.Principal = &entity.Principal{}
+		if err := json.Unmarshal([]byte(principal.String), job.Principal); err != nil {
+			return nil, fmt.Errorf("error decoding job principal %w", err)
+		}
+	}
+
+	return job, nil
+}
+
+func (r *SQLiteJobRepository) Save(ctx context.Context, job *entity.Job) error {
+	var principal *string
+	if job.Principal != nil {
+		encoded, err := json.Marshal(job.Principal)
+		if err != nil {
+			return fmt.Errorf("error encoding job principal %w", err)
+		}
+		value := string(encoded)
+		principal = &value
+	}
+
+	saveQuery := `INSERT INTO jobs (` + jobColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, saveQuery, job.ID, documentTenant(job.TenantID), job.Kind, job.Status, job.Params, principal,
+		job.Progress, job.Total, job.Result, job.ArtifactKey, job.ArtifactName, job.ArtifactContentType, job.ArtifactSize, job.Error,
+		job.Attempts, job.CancelRequested, job.CreatedBy, job.CreatedAt, job.UpdatedAt, job.StartedAt, job.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("error saving job %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteJobRepository) FindById(ctx context.Context, id string) (*entity.Job, error) {
+	findQuery := `SELECT ` + jobColumns + ` FROM jobs WHERE id = ?`
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, findQuery, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("error fetching job %w", err)
+	}
+
+	return job, nil
+}
+
+func (r *SQLiteJobRepository) FindAll(ctx context.Context, filter entity.JobFilter) ([]*entity.Job, error) {
+	conditions := []string{"1 = 1"}
+	var args []any
+
+	for _, field := range []struct{ column, value string }{
+		{"tenant_id", filter.TenantID},
+		{"kind", filter.Kind},
+		{"status", filter.Status},
+	} {
+		if field.value != "" {
+			conditions = append(conditions, field.column+` = ?`)
+			args = append(args, field.value)
+		}
+	}
+
+	if filter.CreatedBy != nil {
+		conditions = append(conditions, `created_by = ?`)
+		args = append(args, *filter.CreatedBy)
+	}
+
+	findAllQuery := `SELECT ` + jobColumns + ` FROM jobs WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY created_at DESC`
+	if filter.Limit > 0 {
+		findAllQuery += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	return r.queryJobs(ctx, findAllQuery, args...)
+}
+
+func (r *SQLiteJobRepository) FindByStatus(ctx context.Context, status string) ([]*entity.Job, error) {
+	findByStatusQuery := `SELECT ` + jobColumns + ` FROM jobs WHERE status = ? ORDER BY created_at`
+
+	return r.queryJobs(ctx, findByStatusQuery, status)
+}
+
+func (r *SQLiteJobRepository) FindFinishedBefore(ctx context.Context, before time.Time) ([]*entity.Job, error) {
+	findFinishedQuery := `SELECT ` + jobColumns + ` FROM jobs WHERE completed_at IS NOT NULL AND completed_at < ? ORDER BY completed_at`
+
+	return r.queryJobs(ctx, findFinishedQuery, before)
+}
+
+func (r *SQLiteJobRepository) queryJobs(ctx context.Context, query string, args ...any) ([]*entity.Job, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding jobs %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*entity.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning job %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (r *SQLiteJobRepository) Claim(ctx context.Context, now time.Time) (*entity.Job, error) {
+	// A single statement, so that two workers cannot claim the same job.
+	claimQuery := `UPDATE jobs SET status = ?, attempts = attempts + 1, started_at = ?, updated_at = ?
+		WHERE id = (SELECT id FROM jobs WHERE status = ? ORDER BY created_at LIMIT 1) AND status = ?
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, claimQuery, entity.JobStatusRunning, now, now, entity.JobStatusQueued, entity.JobStatusQueued))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error claiming job %w", err)
+	}
+
+	return job, nil
+}
+
+func (r *SQLiteJobRepository) UpdateProgress(ctx context.Context, id string, progress, total int64, now time.Time) (bool, error) {
+	updateQuery := `UPDATE jobs SET progress = ?, total = ?, updated_at = ? WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, updateQuery, progress, total, now, id); err != nil {
+		return false, fmt.Errorf("error updating job progress %w", err)
+	}
+
+	var cancelRequested bool
+	if err := r.db.QueryRowContext(ctx, `SELECT cancel_requested FROM jobs WHERE id = ?`, id).Scan(&cancelRequested); err != nil {
+		return false, fmt.Errorf("error checking job cancellation %w", err)
+	}
+
+	return cancelRequested, nil
+}
+
+func (r *SQLiteJobRepository) Finish(ctx context.Context, job *entity.Job) error {
+	finishQuery := `UPDATE jobs SET status = ?, progress = ?, total = ?, result = ?, artifact_key = ?, artifact_name = ?,
+		artifact_content_type = ?, artifact_size = ?, error = ?, updated_at = ?, completed_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, finishQuery, job.Status, job.Progress, job.Total, job.Result, job.ArtifactKey, job.ArtifactName,
+		job.ArtifactContentType, job.ArtifactSize, job.Error, job.UpdatedAt, job.CompletedAt, job.ID)
+	if err != nil {
+		return fmt.Errorf("error finishing job %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entity.ErrJobNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLiteJobRepository) RequestCancel(ctx context.Context, id string, now time.Time) error {
+	cancelQueuedQuery := `UPDATE jobs SET status = ?, cancel_requested = 1, updated_at = ?, completed_at = ? WHERE id = ? AND status = ?`
+	result, err := r.db.ExecContext(ctx, cancelQueuedQuery, entity.JobStatusCanceled, now, now, id, entity.JobStatusQueued)
+	if err != nil {
+		return fmt.Errorf("error canceling job %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		return nil
+	}
+
+	cancelRunningQuery := `UPDATE jobs SET cancel_requested = 1, updated_at = ? WHERE id = ? AND status = ?`
+	result, err = r.db.ExecContext(ctx, cancelRunningQuery, now, id, entity.JobStatusRunning)
+	if err != nil {
+		return fmt.Errorf("error canceling job %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		return nil
+	}
+
+	if _, err := r.FindById(ctx, id); err != nil {
+		return err
+	}
+
+	return entity.ErrJobFinished
+}
+
+func (r *SQLiteJobRepository) Requeue(ctx context.Context, id string, now time.Time) error {
+	requeueQuery := `UPDATE jobs SET status = ?, updated_at = ? WHERE id = ? AND status = ?`
+
+	if _, err := r.db.ExecContext(ctx, requeueQuery, entity.JobStatusQueued, now, id, entity.JobStatusRunning); err != nil {
+		return fmt.Errorf("error requeueing job %w", err)
+	}
+
+	return nil
+}
+
+// Release puts a running job back in the queue without counting the attempt
+// it was on. A job asked to stop stays running for Recover to cancel.
+func (r *SQLiteJobRepository) Release(ctx context.Context, id string, now time.Time) error {
+	releaseQuery := `UPDATE jobs SET status = ?, attempts = MAX(attempts - 1, 0), updated_at = ? WHERE id = ? AND status = ? AND cancel_requested = 0`
+
+	if _, err := r.db.ExecContext(ctx, releaseQuery, entity.JobStatusQueued, now, id, entity.JobStatusRunning); err != nil {
+		return fmt.Errorf("error releasing job %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteJobRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM jobs WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("error deleting job %w", err)
+	}
+
+	return nil
+}
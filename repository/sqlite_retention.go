@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+	"time"
+)
+
+const retentionPolicyColumns = `id, name, content_type, folder_id, default_ttl_seconds, min_retention_seconds, max_retention_seconds, never_expire, priority, created_at, updated_at`
+
+type SQLiteRetentionPolicyRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRetentionPolicyRepository(db *sql.DB) RetentionPolicyRepository {
+	return &SQLiteRetentionPolicyRepository{db: db}
+}
+
+func scanRetentionPolicy(row rowScanner) (*entity.RetentionPolicy, error) {
+	policy := &entity.RetentionPolicy{}
+	var defaultTTL, minRetention, maxRetention sql.NullInt64
+	err := row.Scan(&policy.ID, &policy.Name, &policy.ContentType, &policy.FolderID, &defaultTTL, &minRetention, &maxRetention,
+		&policy.NeverExpire, &policy.Priority, &policy.CreatedAt, &policy.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.DefaultTTL = durationFromSeconds(defaultTTL)
+	policy.MinRetention = durationFromSeconds(minRetention)
+	policy.MaxRetention = durationFromSeconds(maxRetention)
+
+	return policy, nil
+}
+
+func (r *SQLiteRetentionPolicyRepository) Save(ctx context.Context, policy *entity.RetentionPolicy) error {
+	insertQuery := `INSERT INTO retention_policies (` + retentionPolicyColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, insertQuery, policy.ID, policy.Name, policy.ContentType, policy.FolderID,
+		secondsFromDuration(policy.DefaultTTL), secondsFromDuration(policy.MinRetention), secondsFromDuration(policy.MaxRetention),
+		policy.NeverExpire, policy.Priority, policy.CreatedAt, policy.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.ErrRetentionPolicyExists
+		}
+		return fmt.Errorf("error inserting retention policy %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteRetentionPolicyRepository) FindById(ctx context.Context, id string) (*entity.RetentionPolicy, error) {
+	findByIdQuery := `SELECT ` + retentionPolicyColumns + ` FROM retention_policies WHERE id = ?`
+
+	policy, err := scanRetentionPolicy(r.db.QueryRowContext(ctx, findByIdQuery, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.ErrRetentionPolicyNotFound
+		}
+		return nil, fmt.Errorf("error fetching retention policy %w", err)
+	}
+
+	return policy, nil
+}
+
+func (r *SQLiteRetentionPolicyRepository) FindAll(ctx context.Context) ([]*entity.RetentionPolicy, error) {
+	findAllQuery := `SELECT ` + retentionPolicyColumns + ` FROM retention_policies ORDER BY priority DESC, name`
+
+	rows, err := r.db.QueryContext(ctx, findAllQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error finding retention policies %w", err)
+	}
+	defer rows.Close()
+
+	policies := []*entity.RetentionPolicy{}
+	for rows.Next() {
+		policy, err := scanRetentionPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning retention policy %w", err)
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+func (r *SQLiteRetentionPolicyRepository) Update(ctx context.Context, policy *entity.RetentionPolicy) error {
+	updateQuery := `UPDATE retention_policies SET name = ?, content_type = ?, folder_id = ?, default_ttl_seconds = ?, min_retention_seconds = ?,
+		max_retention_seconds = ?, never_expire = ?, priority = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, updateQuery, policy.Name, policy.ContentType, policy.FolderID,
+		secondsFromDuration(policy.DefaultTTL), secondsFromDuration(policy.MinRetention), secondsFromDuration(policy.MaxRetention),
+		policy.NeverExpire, policy.Priority, policy.UpdatedAt, policy.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.ErrRetentionPolicyExists
+		}
+		return fmt.Errorf("error updating retention policy %w", err)
+	}
+
+	return requireAffected(result, entity.ErrRetentionPolicyNotFound)
+}
+
+func (r *SQLiteRetentionPolicyRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting retention policy transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE documents SET retention_policy_id = NULL WHERE retention_policy_id = ?`, id); err != nil {
+		return fmt.Errorf("error detaching retention policy %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM retention_policies WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting retention policy %w", err)
+	}
+	if err := requireAffected(result, entity.ErrRetentionPolicyNotFound); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func durationFromSeconds(seconds sql.NullInt64) *time.Duration {
+	if !seconds.Valid {
+		return nil
+	}
+
+	duration := time.Duration(seconds.Int64) * time.Second
+	return &duration
+}
+
+func secondsFromDuration(duration *time.Duration) *int64 {
+	if duration == nil {
+		return nil
+	}
+
+	seconds := int64(*duration / time.Second)
+	return &seconds
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+	"time"
+)
+
+const shareLinkColumns = `id, document_id, token_hash, password_hash, expires_at, max_downloads, download_count, created_by, created_at, revoked_at, last_used_at`
+
+type SQLiteShareLinkRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteShareLinkRepository(db *sql.DB) ShareLinkRepository {
+	return &SQLiteShareLinkRepository{db: db}
+}
+
+func scanShareLink(row rowScanner) (*entity.ShareLink, error) {
+	link := &entity.ShareLink{}
+	err := row.Scan(&link.ID, &link.DocumentID, &link.TokenHash, &link.PasswordHash, &link.ExpiresAt, &link.MaxDownloads, &link.DownloadCount,
+		&link.CreatedBy, &link.CreatedAt, &link.RevokedAt, &link.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (r *SQLiteShareLinkRepository) Save(ctx context.Context, link *entity.ShareLink) error {
+	insertQuery := `INSERT INTO share_links (` + shareLinkColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, insertQuery, link.ID, link.DocumentID, link.TokenHash, link.PasswordHash, link.ExpiresAt, link.MaxDownloads, link.DownloadCount,
+		link.CreatedBy, link.CreatedAt, link.RevokedAt, link.LastUsedAt)
+	if err != nil {
+		return fmt.Errorf("error inserting share link %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteShareLinkRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.ShareLink, error) {
+	findByTokenQuery := `SELECT ` + shareLinkColumns + ` FROM share_links WHERE token_hash = ?`
+
+	link, err := scanShareLink(r.db.QueryRowContext(ctx, findByTokenQuery, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.ErrShareLinkNotFound
+		}
+		return nil, fmt.Errorf("error fetching share link %w", err)
+	}
+
+	return link, nil
+}
+
+func (r *SQLiteShareLinkRepository) FindByDocument(ctx context.Context, documentID string) ([]*entity.ShareLink, error) {
+	findByDocumentQuery := `SELECT ` + shareLinkColumns + ` FROM share_links WHERE document_id = ? ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, findByDocumentQuery, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding share links %w", err)
+	}
+	defer rows.Close()
+
+	links := []*entity.ShareLink{}
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning share link %w", err)
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+func (r *SQLiteShareLinkRepository) Revoke(ctx context.Context, documentID, id string, revokedAt time.Time) error {
+	revokeQuery := `UPDATE share_links SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ? AND document_id = ?`
+
+	result, err := r.db.ExecContext(ctx, revokeQuery, revokedAt, id, documentID)
+	if err != nil {
+		return fmt.Errorf("error revoking share link %w", err)
+	}
+
+	return requireAffected(result, entity.ErrShareLinkNotFound)
+}
+
+func (r *SQLiteShareLinkRepository) ConsumeDownload(ctx context.Context, id string, now time.Time) error {
+	consumeQuery := `UPDATE share_links SET download_count = download_count + 1, last_used_at = ?
+		WHERE id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)
+		AND (max_downloads IS NULL OR download_count < max_downloads)`
+
+	result, err := r.db.ExecContext(ctx, consumeQuery, now, id, now)
+	if err != nil {
+		return fmt.Errorf("error counting share link download %w", err)
+	}
+
+	return requireAffected(result, entity.ErrShareLinkUnavailable)
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+)
+
+const storageMigrationColumns = `id, source, target, status, created_by, created_at, updated_at, completed_at`
+
+type SQLiteStorageMigrationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteStorageMigrationRepository(db *sql.DB) StorageMigrationRepository {
+	return &SQLiteStorageMigrationRepository{db: db}
+}
+
+func (r *SQLiteStorageMigrationRepository) Save(ctx context.Context, migration *entity.StorageMigration) error {
+	saveQuery := `INSERT INTO storage_migrations (` + storageMigrationColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, saveQuery, migration.ID, migration.Source, migration.Target, migration.Status, migration.CreatedBy,
+		migration.CreatedAt, migration.UpdatedAt, migration.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("error saving storage migration %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteStorageMigrationRepository) Update(ctx context.Context, migration *entity.StorageMigration) error {
+	updateQuery := `UPDATE storage_migrations SET status = ?, updated_at = ?, completed_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, updateQuery, migration.Status, migration.UpdatedAt, migration.CompletedAt, migration.ID)
+	if err != nil {
+		return fmt.Errorf("error updating storage migration %w", err)
+	}
+
+	return requireAffected(result, entity.ErrStorageMigrationNotFound)
+}
+
+func (r *SQLiteStorageMigrationRepository) FindLatest(ctx context.Context) (*entity.StorageMigration, error) {
+	findLatestQuery := `SELECT ` + storageMigrationColumns + ` FROM storage_migrations ORDER BY created_at DESC LIMIT 1`
+
+	migration := &entity.StorageMigration{}
+	err := r.db.QueryRowContext(ctx, findLatestQuery).Scan(&migration.ID, &migration.Source, &migration.Target, &migration.Status,
+		&migration.CreatedBy, &migration.CreatedAt, &migration.UpdatedAt, &migration.CompletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.ErrStorageMigrationNotFound
+		}
+		return nil, fmt.Errorf("error fetching storage migration %w", err)
+	}
+
+	return migration, nil
+}
+
+func (r *SQLiteStorageMigrationRepository) SaveItem(ctx context.Context, item *entity.StorageMigrationItem) error {
+	saveItemQuery := `INSERT INTO storage_migration_items (migration_id, object_key, size, status, checksum, error, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (migration_id, object_key) DO UPDATE SET
+			size = excluded.size, status = excluded.status, checksum = excluded.checksum, error = excluded.error, updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecContext(ctx, saveItemQuery, item.MigrationID, item.Key, item.Size, item.Status, item.Checksum, item.Error, item.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving storage migration item %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteStorageMigrationRepository) FindItems(ctx context.Context, migrationID string) ([]*entity.StorageMigrationItem, error) {
+	findItemsQuery := `SELECT migration_id, object_key, size, status, checksum, error, updated_at
+		FROM storage_migration_items WHERE migration_id = ? ORDER BY object_key`
+
+	rows, err := r.db.QueryContext(ctx, findItemsQuery, migrationID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding storage migration items %w", err)
+	}
+	defer rows.Close()
+
+	items := []*entity.StorageMigrationItem{}
+	for rows.Next() {
+		item := &entity.StorageMigrationItem{}
+		if err := rows.Scan(&item.MigrationID, &item.Key, &item.Size, &item.Status, &item.Checksum, &item.Error, &item.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning storage migration item %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+)
+
+const tenantColumns = `id, name, quota_bytes, quota_documents, user_quota_bytes, user_quota_documents, default_retention_seconds,
+	created_at, updated_at`
+
+type SQLiteTenantRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteTenantRepository(db *sql.DB) TenantRepository {
+	return &SQLiteTenantRepository{db: db}
+}
+
+func scanTenant(row rowScanner) (*entity.Tenant, error) {
+	tenant := &entity.Tenant{}
+	var defaultRetention sql.NullInt64
+	err := row.Scan(&tenant.ID, &tenant.Name, &tenant.QuotaBytes, &tenant.QuotaDocuments, &tenant.UserQuotaBytes, &tenant.UserQuotaDocuments,
+		&defaultRetention, &tenant.CreatedAt, &tenant.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant.DefaultRetention = durationFromSeconds(defaultRetention)
+
+	return tenant, nil
+}
+
+func (r *SQLiteTenantRepository) Save(ctx context.Context, tenant *entity.Tenant) error {
+	insertQuery := `INSERT INTO tenants (` + tenantColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, insertQuery, tenant.ID, tenant.Name, tenant.QuotaBytes, tenant.QuotaDocuments, tenant.UserQuotaBytes,
+		tenant.UserQuotaDocuments, secondsFromDuration(tenant.DefaultRetention), tenant.CreatedAt, tenant.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.ErrTenantExists
+		}
+		return fmt.Errorf("error inserting tenant %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteTenantRepository) FindById(ctx context.Context, id string) (*entity.Tenant, error) {
+	findByIdQuery := `SELECT ` + tenantColumns + ` FROM tenants WHERE id = ?`
+
+	tenant, err := scanTenant(r.db.QueryRowContext(ctx, findByIdQuery, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("error fetching tenant %w", err)
+	}
+
+	return tenant, nil
+}
+
+func (r *SQLiteTenantRepository) FindAll(ctx context.Context) ([]*entity.Tenant, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+tenantColumns+` FROM tenants ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("error finding tenants %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []*entity.Tenant{}
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning tenant %w", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, rows.Err()
+}
+
+func (r *SQLiteTenantRepository) Update(ctx context.Context, tenant *entity.Tenant) error {
+	updateQuery := `UPDATE tenants SET name = ?, quota_bytes = ?, quota_documents = ?, user_quota_bytes = ?, user_quota_documents = ?,
+		default_retention_seconds = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, updateQuery, tenant.Name, tenant.QuotaBytes, tenant.QuotaDocuments, tenant.UserQuotaBytes,
+		tenant.UserQuotaDocuments, secondsFromDuration(tenant.DefaultRetention), tenant.UpdatedAt, tenant.ID)
+	if err != nil {
+		return fmt.Errorf("error updating tenant %w", err)
+	}
+
+	return requireAffected(result, entity.ErrTenantNotFound)
+}
+
+func (r *SQLiteTenantRepository) Delete(ctx context.Context, id string) error {
+	deleteQuery := `DELETE FROM tenants WHERE id = ?
+		AND NOT EXISTS (SELECT 1 FROM documents WHERE tenant_id = tenants.id)
+		AND NOT EXISTS (SELECT 1 FROM folders WHERE tenant_id = tenants.id)`
+
+	result, err := r.db.ExecContext(ctx, deleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting tenant %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error reading affected rows %w", err)
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	if _, err := r.FindById(ctx, id); err != nil {
+		return err
+	}
+
+	return entity.ErrTenantNotEmpty
+}
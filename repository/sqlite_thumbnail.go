@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+)
+
+const thumbnailColumns = `document_id, size, storage_key, content_type, width, height, file_size, created_at`
+
+type SQLiteThumbnailRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteThumbnailRepository(db *sql.DB) ThumbnailRepository {
+	return &SQLiteThumbnailRepository{db: db}
+}
+
+func scanThumbnail(row rowScanner) (*entity.Thumbnail, error) {
+	thumbnail := &entity.Thumbnail{}
+	err := row.Scan(&thumbnail.DocumentID, &thumbnail.Size, &thumbnail.StorageKey, &thumbnail.ContentType,
+		&thumbnail.Width, &thumbnail.Height, &thumbnail.FileSize, &thumbnail.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return thumbnail, nil
+}
+
+func (r *SQLiteThumbnailRepository) Save(ctx context.Context, thumbnail *entity.Thumbnail) error {
+	saveQuery := `INSERT OR REPLACE INTO document_thumbnails (` + thumbnailColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, saveQuery, thumbnail.DocumentID, thumbnail.Size, thumbnail.StorageKey, thumbnail.ContentType,
+		thumbnail.Width, thumbnail.Height, thumbnail.FileSize, thumbnail.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving thumbnail %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteThumbnailRepository) Find(ctx context.Context, documentID string, size int) (*entity.Thumbnail, error) {
+	findQuery := `SELECT ` + thumbnailColumns + ` FROM document_thumbnails WHERE document_id = ? AND size = ?`
+
+	thumbnail, err := scanThumbnail(r.db.QueryRowContext(ctx, findQuery, documentID, size))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entity.ErrThumbnailNotFound
+		}
+		return nil, fmt.Errorf("error fetching thumbnail %w", err)
+	}
+
+	return thumbnail, nil
+}
+
+func (r *SQLiteThumbnailRepository) FindByDocument(ctx context.Context, documentID string) ([]*entity.Thumbnail, error) {
+	findByDocumentQuery := `SELECT ` + thumbnailColumns + ` FROM document_thumbnails WHERE document_id = ? ORDER BY size`
+
+	rows, err := r.db.QueryContext(ctx, findByDocumentQuery, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding thumbnails %w", err)
+	}
+	defer rows.Close()
+
+	thumbnails := []*entity.Thumbnail{}
+	for rows.Next() {
+		thumbnail, err := scanThumbnail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning thumbnail %w", err)
+		}
+		thumbnails = append(thumbnails, thumbnail)
+	}
+
+	return thumbnails, rows.Err()
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"docvault/entity"
+	"fmt"
+	"time"
+)
+
+type SQLiteUsageRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteUsageRepository(db *sql.DB) UsageRepository {
+	return &SQLiteUsageRepository{db: db}
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// usageSubjects lists the usage rows a document counts towards.
+func usageSubjects(tenantID string, ownerID *string) [][2]string {
+	subjects := [][2]string{{entity.UsageScopeTenant, documentTenant(tenantID)}}
+	if ownerID != nil {
+		subjects = append(subjects, [2]string{entity.UsageScopeUser, *ownerID})
+	}
+
+	return subjects
+}
+
+func findUsage(ctx context.Context, q rowQuerier, scope, subjectID string) (*entity.Usage, error) {
+	usage := &entity.Usage{Scope: scope, SubjectID: subjectID}
+
+	findQuery := `SELECT bytes, documents, updated_at FROM storage_usage WHERE scope = ? AND subject_id = ?`
+	err := q.QueryRowContext(ctx, findQuery, scope, subjectID).Scan(&usage.Bytes, &usage.Documents, &usage.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error fetching storage usage %w", err)
+	}
+
+	return usage, nil
+}
+
+// adjustUsage adds bytes and documents to every total the document counts
+// towards; negative values release them.
+func adjustUsage(ctx context.Context, tx *sql.Tx, tenantID string, ownerID *string, bytes, documents int64) error {
+	adjustQuery := `INSERT INTO storage_usage (scope, subject_id, bytes, documents, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (scope, subject_id) DO UPDATE SET bytes = bytes + excluded.bytes, documents = documents + excluded.documents,
+		updated_at = excluded.updated_at`
+
+	now := time.Now()
+	for _, subject := range usageSubjects(tenantID, ownerID) {
+		if _, err := tx.ExecContext(ctx, adjustQuery, subject[0], subject[1], bytes, documents, now); err != nil {
+			return fmt.Errorf("error updating storage usage %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLiteUsageRepository) Find(ctx context.Context, scope, subjectID string) (*entity.Usage, error) {
+	return findUsage(ctx, r.db, scope, subjectID)
+}
+
+// Reconcile recomputes every total from the documents table and returns the
+// totals that had drifted, with their corrected values.
+func (r *SQLiteUsageRepository) Reconcile(ctx context.Context) ([]*entity.Usage, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting storage usage transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	// Touching the table first takes the write lock, so uploads cannot change
+	// the documents table between the two reads below.
+	if _, err := tx.ExecContext(ctx, `UPDATE storage_usage SET bytes = bytes WHERE 0`); err != nil {
+		return nil, fmt.Errorf("error locking storage usage %w", err)
+	}
+
+	actualQuery := `SELECT 'tenant', tenant_id, COALESCE(SUM(file_size), 0), COUNT(*) FROM documents GROUP BY tenant_id
+		UNION ALL
+		SELECT 'user', owner_id, COALESCE(SUM(file_size), 0), COUNT(*) FROM documents WHERE owner_id IS NOT NULL GROUP BY owner_id`
+	actual, err := scanUsageTotals(ctx, tx, actualQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := scanUsageTotals(ctx, tx, `SELECT scope, subject_id, bytes, documents FROM storage_usage`)
+	if err != nil {
+		return nil, err
+	}
+
+	// Totals with no documents left are corrected to zero.
+	for key, usage := range stored {
+		if _, ok := actual[key]; !ok {
+			actual[key] = &entity.Usage{Scope: usage.Scope, SubjectID: usage.SubjectID}
+		}
+	}
+
+	upsertQuery := `INSERT INTO storage_usage (scope, subject_id, bytes, documents, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (scope, subject_id) DO UPDATE SET bytes = excluded.bytes, documents = excluded.documents, updated_at = excluded.updated_at`
+
+	now := time.Now()
+	corrected := []*entity.Usage{}
+	for key, usage := range actual {
+		if previous, ok := stored[key]; ok && previous.Bytes == usage.Bytes && previous.Documents == usage.Documents {
+			continue
+		}
+
+		usage.UpdatedAt = now
+		if _, err := tx.ExecContext(ctx, upsertQuery, usage.Scope, usage.SubjectID, usage.Bytes, usage.Documents, now); err != nil {
+			return nil, fmt.Errorf("error correcting storage usage %w", err)
+		}
+		corrected = append(corrected, usage)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing storage usage %w", err)
+	}
+
+	return corrected, nil
+}
+
+func scanUsageTotals(ctx context.Context, tx *sql.Tx, query string) (map[[2]string]*entity.Usage, error) {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error reading storage usage %w", err)
+	}
+	defer rows.Close()
+
+	totals := map[[2]string]*entity.Usage{}
+	for rows.Next() {
+		usage := &entity.Usage{}
+		if err := rows.Scan(&usage.Scope, &usage.SubjectID, &usage.Bytes, &usage.Documents); err != nil {
+			return nil, fmt.Errorf("error scanning storage usage %w", err)
+		}
+		totals[[2]string{usage.Scope, usage.SubjectID}] = usage
+	}
+
+	return totals, rows.Err()
+}
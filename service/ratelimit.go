@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimiter holds token buckets and concurrent-transfer slots keyed by
+// client. The in-memory implementation serves a single instance; a shared
+// store can implement the same interface for several.
+type RateLimiter interface {
+	// Allow takes one token from the bucket for key.
+	Allow(ctx context.Context, key string, limit RateLimit) (RateLimitDecision, error)
+	// Acquire takes one of max concurrent slots for key. The returned release
+	// func must be called once the transfer ends; it is nil when ok is false.
+	Acquire(ctx context.Context, key string, max int) (release func(), ok bool, err error)
+}
+
+// RateLimit is a token bucket refilled at PerMinute tokens a minute that holds
+// at most Burst tokens.
+type RateLimit struct {
+	PerMinute int
+	Burst     int
+}
+
+func (l RateLimit) Enabled() bool {
+	return l.PerMinute > 0 && l.Burst > 0
+}
+
+type RateLimitDecision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next token, set when not allowed.
+	RetryAfter time.Duration
+}
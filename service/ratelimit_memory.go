@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// idleBucketSweep is how often buckets that have refilled completely are
+// dropped, which keeps memory bounded by the number of recent clients.
+const idleBucketSweep = time.Minute
+
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	transfers map[string]int
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	limit   RateLimit
+}
+
+func NewMemoryRateLimiter() RateLimiter {
+	return &MemoryRateLimiter{
+		buckets:   map[string]*tokenBucket{},
+		transfers: map[string]int{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	perSecond := float64(b.limit.PerMinute) / 60
+	b.tokens = min(float64(b.limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*perSecond)
+	b.updated = now
+}
+
+func (b *tokenBucket) wait(tokens float64) time.Duration {
+	perSecond := float64(b.limit.PerMinute) / 60
+	return time.Duration(tokens / perSecond * float64(time.Second))
+}
+
+func (l *MemoryRateLimiter) Allow(ctx context.Context, key string, limit RateLimit) (RateLimitDecision, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok || bucket.limit != limit {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), updated: now, limit: limit}
+		l.buckets[key] = bucket
+	}
+	bucket.refill(now)
+
+	decision := RateLimitDecision{Limit: limit.Burst}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = bucket.wait(1 - bucket.tokens)
+	}
+	decision.Remaining = int(bucket.tokens)
+	decision.Reset = bucket.wait(float64(limit.Burst) - bucket.tokens)
+
+	return decision, nil
+}
+
+func (l *MemoryRateLimiter) Acquire(ctx context.Context, key string, max int) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.transfers[key] >= max {
+		return nil, false, nil
+	}
+	l.transfers[key]++
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			l.transfers[key]--
+			if l.transfers[key] <= 0 {
+				delete(l.transfers, key)
+			}
+		})
+	}
+
+	return release, true, nil
+}
+
+func (l *MemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketSweep {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= float64(bucket.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"io"
+)
+
+// Scanner inspects content for malware. It reads r to EOF unless it fails.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (ScanResult, error)
+}
+
+type ScanResult struct {
+	Infected bool
+	// Signature names what an infected file matched.
+	Signature string
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const clamdChunkSize = 64 * 1024
+
+// ClamdScanner streams content to a clamd daemon with the INSTREAM command.
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner connects to address, either host:port or unix:/path/to/socket.
+// timeout bounds the connect and every read or write on the connection.
+func NewClamdScanner(address string, timeout time.Duration) Scanner {
+	network := "tcp"
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		network, address = "unix", path
+	}
+
+	return &ClamdScanner{network: network, address: address, timeout: timeout}
+}
+
+func (s *ClamdScanner) Scan(ctx context.Context, r io.Reader) (ScanResult, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return ScanResult{}, fmt.Errorf("error connecting to clamd %w", err)
+	}
+	defer conn.Close()
+
+	// Closing the connection unblocks any pending read or write on cancel.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := s.stream(conn, r); err != nil {
+		// clamd hangs up early when the stream is too large, explaining why
+		// in its reply.
+		if reply, replyErr := s.reply(conn); replyErr == nil && strings.HasSuffix(reply, "ERROR") {
+			return ScanResult{}, fmt.Errorf("clamd rejected the stream: %s", reply)
+		}
+		return ScanResult{}, fmt.Errorf("error streaming to clamd %w", err)
+	}
+
+	reply, err := s.reply(conn)
+	if err != nil {
+		return ScanResult{}, fmt.Errorf("error reading clamd reply %w", err)
+	}
+
+	return parseClamdReply(reply)
+}
+
+func (s *ClamdScanner) stream(conn net.Conn, r io.Reader) error {
+	conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 4+clamdChunkSize)
+	for {
+		n, readErr := r.Read(chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk[:4], uint32(n))
+			conn.SetWriteDeadline(time.Now().Add(s.timeout))
+			if _, err := conn.Write(chunk[:4+n]); err != nil {
+				return err
+			}
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	_, err := conn.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+func (s *ClamdScanner) reply(conn net.Conn) (string, error) {
+	conn.SetReadDeadline(time.Now().Add(s.timeout))
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return "", err
+	}
+
+	return strings.TrimSpace(strings.TrimRight(reply, "\x00")), nil
+}
+
+// parseClamdReply reads "stream: OK", "stream: <signature> FOUND" or
+// "<message> ERROR".
+func parseClamdReply(reply string) (ScanResult, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+
+	switch {
+	case result == "OK":
+		return ScanResult{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return ScanResult{Infected: true, Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	default:
+		return ScanResult{}, fmt.Errorf("unexpected clamd reply %q", reply)
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// eicarSignature is the EICAR anti-malware test string, which every scanner
+// is expected to report as infected.
+const eicarSignature = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+const eicarSignatureName = "EICAR-Test-File"
+
+// SignatureScanner matches content against a fixed set of byte signatures.
+// It only knows EICAR, which makes it useful for exercising quarantine
+// without a clamd daemon.
+type SignatureScanner struct {
+	signatures map[string][]byte
+}
+
+func NewSignatureScanner() Scanner {
+	return &SignatureScanner{signatures: map[string][]byte{eicarSignatureName: []byte(eicarSignature)}}
+}
+
+func (s *SignatureScanner) Scan(ctx context.Context, r io.Reader) (ScanResult, error) {
+	overlap := 0
+	for _, signature := range s.signatures {
+		overlap = max(overlap, len(signature)-1)
+	}
+
+	// Each chunk is searched together with the tail of the previous one so
+	// signatures split across reads are still found.
+	window := make([]byte, 0, overlap+32*1024)
+	chunk := make([]byte, 32*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return ScanResult{}, err
+		}
+
+		n, err := r.Read(chunk)
+		window = append(window, chunk[:n]...)
+		for name, signature := range s.signatures {
+			if bytes.Contains(window, signature) {
+				io.Copy(io.Discard, r)
+				return ScanResult{Infected: true, Signature: name}, nil
+			}
+		}
+		if len(window) > overlap {
+			window = append(window[:0], window[len(window)-overlap:]...)
+		}
+
+		if err == io.EOF {
+			return ScanResult{}, nil
+		}
+		if err != nil {
+			return ScanResult{}, err
+		}
+	}
+}
@@ -2,12 +2,26 @@ package service
 
 import (
 	"context"
+	"errors"
 	"io"
+	"time"
 )
 
+// ErrObjectNotFound is returned, possibly wrapped, by Download for a key that
+// has no object.
+var ErrObjectNotFound = errors.New("object not found")
+
+type StorageObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
 type StorageService interface {
 	Upload(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error
 	Download(ctx context.Context, filename string) (io.ReadCloser, error)
 	Delete(ctx context.Context, filename string) error
+	// List returns every object whose key starts with prefix; "" lists all.
+	List(ctx context.Context, prefix string) ([]StorageObject, error)
 	Health(ctx context.Context) error
 }
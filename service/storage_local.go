@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localTempPrefix marks files still being written, which List leaves out.
+const localTempPrefix = ".upload-"
+
+// LocalStorage keeps objects as files below a root directory, with the
+// slashes in keys as subdirectories.
+type LocalStorage struct {
+	root string
+}
+
+func NewLocalStorage(root string) (StorageService, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("Error resolving local storage path %w", err)
+	}
+
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("Error creating local storage directory %w", err)
+	}
+
+	return &LocalStorage{root: root}, nil
+}
+
+func (l *LocalStorage) path(key string) (string, error) {
+	path := filepath.Join(l.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, l.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("Error resolving local object %q: key escapes the storage directory", key)
+	}
+
+	return path, nil
+}
+
+// Upload writes to a temporary file first, so that readers never see a
+// partly written object.
+func (l *LocalStorage) Upload(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+	path, err := l.path(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("Error creating local object directory %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), localTempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("Error creating local object %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := io.Copy(temp, file); err != nil {
+		temp.Close()
+		return fmt.Errorf("Error writing local object %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("Error writing local object %w", err)
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("Error writing local object %w", err)
+	}
+
+	return nil
+}
+
+func (l *LocalStorage) Download(ctx context.Context, filename string) (io.ReadCloser, error) {
+	path, err := l.path(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("Error opening local object %w", ErrObjectNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error opening local object %w", err)
+	}
+
+	return file, nil
+}
+
+func (l *LocalStorage) Delete(ctx context.Context, filename string) error {
+	path, err := l.path(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Error deleting local object %w", err)
+	}
+
+	return nil
+}
+
+func (l *LocalStorage) List(ctx context.Context, prefix string) ([]StorageObject, error) {
+	objects := []StorageObject{}
+	err := filepath.WalkDir(l.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), localTempPrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, StorageObject{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing local objects %w", err)
+	}
+
+	return objects, nil
+}
+
+func (l *LocalStorage) Health(ctx context.Context) error {
+	info, err := os.Stat(l.root)
+	if err != nil {
+		return fmt.Errorf("Error checking local storage directory %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("Local storage path is not a directory")
+	}
+
+	return nil
+}
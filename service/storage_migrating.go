@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MigratingStorage is the active backend, which can be moved to another one
+// while the application keeps running. During a move new objects go to the
+// target, reads fall back to the active backend for objects not copied yet
+// and deletes reach both; Finish then makes the target the active backend.
+type MigratingStorage struct {
+	mu     sync.RWMutex
+	active StorageService
+	target StorageService
+
+	// uploads counts uploads to the active backend while there is no move,
+	// so that Drain can wait for the ones Begin caught on their way.
+	uploads sync.WaitGroup
+}
+
+func NewMigratingStorage(active StorageService) *MigratingStorage {
+	return &MigratingStorage{active: active}
+}
+
+// Begin starts moving to target. Uploads that had already picked the active
+// backend still go there; Drain waits for them.
+func (s *MigratingStorage) Begin(target StorageService) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.target = target
+}
+
+// Drain waits for uploads to the active backend that started before Begin,
+// so that listing it afterwards finds their objects.
+func (s *MigratingStorage) Drain(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		s.uploads.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Finish makes the target of the move the active backend.
+func (s *MigratingStorage) Finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.target != nil {
+		s.active, s.target = s.target, nil
+	}
+}
+
+// Backends returns the active backend and the target of a move, which is nil
+// when there is none.
+func (s *MigratingStorage) Backends() (StorageService, StorageService) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active, s.target
+}
+
+func (s *MigratingStorage) Upload(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+	s.mu.RLock()
+	active, target := s.active, s.target
+	if target == nil {
+		s.uploads.Add(1)
+		defer s.uploads.Done()
+	}
+	s.mu.RUnlock()
+
+	if target != nil {
+		return target.Upload(ctx, filename, fileSize, contentType, file)
+	}
+
+	return active.Upload(ctx, filename, fileSize, contentType, file)
+}
+
+func (s *MigratingStorage) Download(ctx context.Context, filename string) (io.ReadCloser, error) {
+	active, target := s.Backends()
+	if target == nil {
+		return active.Download(ctx, filename)
+	}
+
+	object, err := target.Download(ctx, filename)
+	if err == nil {
+		return object, nil
+	}
+
+	object, fallbackErr := active.Download(ctx, filename)
+	if fallbackErr != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func (s *MigratingStorage) Delete(ctx context.Context, filename string) error {
+	active, target := s.Backends()
+	if target != nil {
+		if err := target.Delete(ctx, filename); err != nil {
+			return err
+		}
+	}
+
+	return active.Delete(ctx, filename)
+}
+
+// List merges both backends during a move, preferring the target's copy.
+func (s *MigratingStorage) List(ctx context.Context, prefix string) ([]StorageObject, error) {
+	active, target := s.Backends()
+	objects, err := active.List(ctx, prefix)
+	if err != nil || target == nil {
+		return objects, err
+	}
+
+	copied, err := target.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, object := range copied {
+		seen[object.Key] = true
+	}
+	for _, object := range objects {
+		if !seen[object.Key] {
+			copied = append(copied, object)
+		}
+	}
+
+	return copied, nil
+}
+
+func (s *MigratingStorage) Health(ctx context.Context) error {
+	active, target := s.Backends()
+	if err := active.Health(ctx); err != nil {
+		return err
+	}
+
+	if target != nil {
+		if err := target.Health(ctx); err != nil {
+			return fmt.Errorf("Error checking migration target %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Replicas reports the replicas of the backends that have them.
+func (s *MigratingStorage) Replicas(ctx context.Context) []ReplicaStatus {
+	active, target := s.Backends()
+
+	var statuses []ReplicaStatus
+	for _, backend := range []StorageService{active, target} {
+		if replicated, ok := backend.(ReplicaReporter); ok {
+			statuses = append(statuses, replicated.Replicas(ctx)...)
+		}
+	}
+
+	return statuses
+}
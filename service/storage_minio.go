@@ -37,6 +37,16 @@ func (m *MinIOStorage) Download(ctx context.Context, filename string) (io.ReadCl
 		return nil, fmt.Errorf("Error initialize minio download %w", err)
 	}
 
+	// GetObject is lazy; Stat surfaces a missing object before the caller
+	// starts streaming it.
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("Error initialize minio download %w", ErrObjectNotFound)
+		}
+		return nil, fmt.Errorf("Error initialize minio download %w", err)
+	}
+
 	return object, nil
 }
 
@@ -48,6 +58,19 @@ func (m *MinIOStorage) Delete(ctx context.Context, filename string) error {
 	return nil
 }
 
+func (m *MinIOStorage) List(ctx context.Context, prefix string) ([]StorageObject, error) {
+	objects := []StorageObject{}
+	for object := range m.client.ListObjects(ctx, m.bucketName, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("Error listing minio objects %w", object.Err)
+		}
+
+		objects = append(objects, StorageObject{Key: object.Key, Size: object.Size, LastModified: object.LastModified})
+	}
+
+	return objects, nil
+}
+
 func (m *MinIOStorage) Health(ctx context.Context) error {
 	exists, err := m.client.BucketExists(ctx, m.bucketName)
 	if err != nil {
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	ReplicationSync  = "sync"
+	ReplicationAsync = "async"
+)
+
+// replicaResyncGrace keeps Resync away from objects whose upload may still be
+// reaching one of the replicas.
+const replicaResyncGrace = time.Hour
+
+const (
+	replicaPrimary = iota
+	replicaSecondary
+)
+
+var replicaNames = [2]string{"primary", "secondary"}
+
+// ReplicaStatus is the health of one replica of a ReplicatedStorage, how
+// many repairs are waiting to bring it in line and how the latest one went.
+type ReplicaStatus struct {
+	Name           string
+	Err            error
+	PendingRepairs int
+	LastRepairErr  error
+}
+
+// ReplicaReporter is implemented by storage that keeps several replicas.
+type ReplicaReporter interface {
+	Replicas(ctx context.Context) []ReplicaStatus
+}
+
+// replicaRepair brings one object on one replica in line with the other:
+// copying it there, or deleting it when delete is set.
+type replicaRepair struct {
+	replica     int
+	key         string
+	contentType string
+	delete      bool
+}
+
+// ReplicatedStorage writes every object to a primary and a secondary backend.
+// With sync replication writes reach both before returning; with async ones
+// the secondary is written in the background. Reads fail over to the
+// secondary when the primary errors. Writes a replica missed are queued and
+// retried by Repair, and Resync queues whatever else the two disagree on.
+type ReplicatedStorage struct {
+	replicas [2]StorageService
+	async    bool
+
+	mu      sync.Mutex
+	repairs map[string]*replicaRepair
+	lastErr [2]error
+	// queued is signalled when a repair is queued so that async replication
+	// does not wait for the next retry.
+	queued chan struct{}
+}
+
+func NewReplicatedStorage(primary, secondary StorageService, mode string) (*ReplicatedStorage, error) {
+	switch mode {
+	case "", ReplicationSync, ReplicationAsync:
+	default:
+		return nil, fmt.Errorf("unknown replication mode %q, want sync or async", mode)
+	}
+
+	return &ReplicatedStorage{
+		replicas: [2]StorageService{primary, secondary},
+		async:    mode == ReplicationAsync,
+		repairs:  map[string]*replicaRepair{},
+		queued:   make(chan struct{}, 1),
+	}, nil
+}
+
+// Queued is signalled whenever a repair is queued.
+func (s *ReplicatedStorage) Queued() <-chan struct{} {
+	return s.queued
+}
+
+func (s *ReplicatedStorage) Upload(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+	primary, secondary := s.replicas[replicaPrimary], s.replicas[replicaSecondary]
+	if s.async {
+		if err := primary.Upload(ctx, filename, fileSize, contentType, file); err != nil {
+			return err
+		}
+		s.enqueue(&replicaRepair{replica: replicaSecondary, key: filename, contentType: contentType})
+		return nil
+	}
+
+	// The secondary reads what the primary's upload reads, so the content is
+	// streamed once.
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := secondary.Upload(ctx, filename, fileSize, contentType, pr)
+		// Keep draining after a failure so the primary never blocks.
+		io.Copy(io.Discard, pr)
+		done <- err
+	}()
+
+	err := primary.Upload(ctx, filename, fileSize, contentType, io.TeeReader(file, pw))
+	if err != nil {
+		pw.CloseWithError(err)
+	} else {
+		pw.Close()
+	}
+	secondaryErr := <-done
+
+	if err != nil {
+		if secondaryErr == nil {
+			s.removeReplica(ctx, replicaSecondary, filename)
+		}
+		return err
+	}
+
+	if secondaryErr != nil {
+		fmt.Printf("Failed to replicate %s to the secondary: %v\n", filename, secondaryErr)
+		s.enqueue(&replicaRepair{replica: replicaSecondary, key: filename, contentType: contentType})
+	}
+
+	return nil
+}
+
+func (s *ReplicatedStorage) Download(ctx context.Context, filename string) (io.ReadCloser, error) {
+	object, err := s.replicas[replicaPrimary].Download(ctx, filename)
+	if err == nil {
+		return object, nil
+	}
+
+	object, secondaryErr := s.replicas[replicaSecondary].Download(ctx, filename)
+	if secondaryErr != nil {
+		return nil, err
+	}
+
+	// The primary lost an object the secondary still has.
+	if errors.Is(err, ErrObjectNotFound) {
+		s.enqueue(&replicaRepair{replica: replicaPrimary, key: filename, contentType: "application/octet-stream"})
+	}
+
+	return object, nil
+}
+
+func (s *ReplicatedStorage) Delete(ctx context.Context, filename string) error {
+	if err := s.replicas[replicaPrimary].Delete(ctx, filename); err != nil {
+		return err
+	}
+
+	if s.async {
+		s.enqueue(&replicaRepair{replica: replicaSecondary, key: filename, delete: true})
+		return nil
+	}
+
+	s.removeReplica(ctx, replicaSecondary, filename)
+	return nil
+}
+
+func (s *ReplicatedStorage) List(ctx context.Context, prefix string) ([]StorageObject, error) {
+	objects, err := s.replicas[replicaPrimary].List(ctx, prefix)
+	if err == nil {
+		return objects, nil
+	}
+
+	objects, secondaryErr := s.replicas[replicaSecondary].List(ctx, prefix)
+	if secondaryErr != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// Health fails only when neither replica can be reached; Replicas tells
+// which one is down.
+func (s *ReplicatedStorage) Health(ctx context.Context) error {
+	err := s.replicas[replicaPrimary].Health(ctx)
+	if err == nil {
+		return nil
+	}
+
+	if secondaryErr := s.replicas[replicaSecondary].Health(ctx); secondaryErr != nil {
+		return fmt.Errorf("Error checking replicas, primary: %v, secondary: %w", err, secondaryErr)
+	}
+
+	return nil
+}
+
+func (s *ReplicatedStorage) Replicas(ctx context.Context) []ReplicaStatus {
+	statuses := make([]ReplicaStatus, len(s.replicas))
+	for i, replica := range s.replicas {
+		statuses[i] = ReplicaStatus{Name: replicaNames[i], Err: replica.Health(ctx)}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, repair := range s.repairs {
+		statuses[repair.replica].PendingRepairs++
+	}
+	for i := range statuses {
+		statuses[i].LastRepairErr = s.lastErr[i]
+	}
+
+	return statuses
+}
+
+// Repair retries every queued repair once. Those that fail again stay
+// queued for the next call.
+func (s *ReplicatedStorage) Repair(ctx context.Context) {
+	s.mu.Lock()
+	pending := make([]*replicaRepair, 0, len(s.repairs))
+	for _, repair := range s.repairs {
+		pending = append(pending, repair)
+	}
+	s.mu.Unlock()
+
+	for _, repair := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err := s.repair(ctx, repair)
+
+		s.mu.Lock()
+		s.lastErr[repair.replica] = err
+		id := repairID(repair.replica, repair.key)
+		// A newer repair of the same object may have replaced this one.
+		if err == nil && s.repairs[id] == repair {
+			delete(s.repairs, id)
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			fmt.Printf("Failed to repair %s on the %s: %v\n", repair.key, replicaNames[repair.replica], err)
+		}
+	}
+}
+
+// Resync compares the listings of both replicas and queues copies of objects
+// missing from the secondary or differing in size there. Objects only the
+// secondary has are copied back to the primary when referenced reports them
+// in use, as they may be the last copy, and deleted from the secondary
+// otherwise, as a restart may have lost their queued delete. Restarts lose
+// the queue and Resync finds what it held again.
+func (s *ReplicatedStorage) Resync(ctx context.Context, referenced func(key string) bool) error {
+	primaryObjects, err := s.replicas[replicaPrimary].List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("Error listing primary replica %w", err)
+	}
+	secondaryObjects, err := s.replicas[replicaSecondary].List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("Error listing secondary replica %w", err)
+	}
+
+	replicated := make(map[string]StorageObject, len(secondaryObjects))
+	for _, object := range secondaryObjects {
+		replicated[object.Key] = object
+	}
+
+	cutoff := time.Now().Add(-replicaResyncGrace)
+	for _, object := range primaryObjects {
+		replica, ok := replicated[object.Key]
+		delete(replicated, object.Key)
+		if (!ok || replica.Size != object.Size) && object.LastModified.Before(cutoff) {
+			s.enqueueIfAbsent(&replicaRepair{replica: replicaSecondary, key: object.Key, contentType: "application/octet-stream"})
+		}
+	}
+	for _, object := range replicated {
+		if !object.LastModified.Before(cutoff) {
+			continue
+		}
+		if referenced(object.Key) {
+			s.enqueueIfAbsent(&replicaRepair{replica: replicaPrimary, key: object.Key, contentType: "application/octet-stream"})
+		} else {
+			s.enqueueIfAbsent(&replicaRepair{replica: replicaSecondary, key: object.Key, delete: true})
+		}
+	}
+
+	return nil
+}
+
+// repair copies the object from the other replica, or deletes it.
+func (s *ReplicatedStorage) repair(ctx context.Context, repair *replicaRepair) error {
+	target := s.replicas[repair.replica]
+	if repair.delete {
+		return target.Delete(ctx, repair.key)
+	}
+
+	source := s.replicas[1-repair.replica]
+	object, err := source.Download(ctx, repair.key)
+	if errors.Is(err, ErrObjectNotFound) {
+		// Deleted since; the delete is queued or done on its own.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	size := int64(-1)
+	if listed, err := source.List(ctx, repair.key); err == nil {
+		for _, candidate := range listed {
+			if candidate.Key == repair.key {
+				size = candidate.Size
+			}
+		}
+	}
+
+	return target.Upload(ctx, repair.key, size, repair.contentType, object)
+}
+
+func (s *ReplicatedStorage) removeReplica(ctx context.Context, replica int, key string) {
+	if err := s.replicas[replica].Delete(context.WithoutCancel(ctx), key); err != nil {
+		fmt.Printf("Failed to delete %s from the %s: %v\n", key, replicaNames[replica], err)
+		s.enqueue(&replicaRepair{replica: replica, key: key, delete: true})
+	}
+}
+
+// enqueue queues repair, replacing an earlier one of the same object.
+func (s *ReplicatedStorage) enqueue(repair *replicaRepair) {
+	s.mu.Lock()
+	s.repairs[repairID(repair.replica, repair.key)] = repair
+	s.mu.Unlock()
+
+	select {
+	case s.queued <- struct{}{}:
+	default:
+	}
+}
+
+func (s *ReplicatedStorage) enqueueIfAbsent(repair *replicaRepair) {
+	s.mu.Lock()
+	_, queued := s.repairs[repairID(repair.replica, repair.key)]
+	s.mu.Unlock()
+
+	if !queued {
+		s.enqueue(repair)
+	}
+}
+
+func repairID(replica int, key string) string {
+	return replicaNames[replica] + "/" + key
+}
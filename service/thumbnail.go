@@ -0,0 +1,17 @@
+package service
+
+import "io"
+
+type Thumbnailer interface {
+	// Thumbnails decodes an image once and renders it to fit within each of
+	// sizes, never enlarging it.
+	Thumbnails(r io.Reader, sizes []int) ([]RenderedThumbnail, error)
+}
+
+type RenderedThumbnail struct {
+	Size        int
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
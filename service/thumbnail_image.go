@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// maxThumbnailSourcePixels refuses to decode images that would need more than
+// about 160 MB of memory, whatever their file size.
+const maxThumbnailSourcePixels = 40_000_000
+
+// ImageThumbnailer renders PNG, JPEG and GIF thumbnails with the standard
+// library. JPEG sources produce JPEG thumbnails; PNG and GIF sources, which
+// may be transparent, produce PNG.
+type ImageThumbnailer struct{}
+
+func NewImageThumbnailer() Thumbnailer {
+	return &ImageThumbnailer{}
+}
+
+func (t *ImageThumbnailer) Thumbnails(r io.Reader, sizes []int) ([]RenderedThumbnail, error) {
+	var header bytes.Buffer
+	config, format, err := image.DecodeConfig(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, fmt.Errorf("error reading image header %w", err)
+	}
+	if config.Width*config.Height > maxThumbnailSourcePixels {
+		return nil, fmt.Errorf("image of %dx%d pixels is too large to thumbnail", config.Width, config.Height)
+	}
+
+	var decoded image.Image
+	body := io.MultiReader(&header, r)
+	switch format {
+	case "png":
+		decoded, err = png.Decode(body)
+	case "jpeg":
+		decoded, err = jpeg.Decode(body)
+	case "gif":
+		decoded, err = gif.Decode(body)
+	default:
+		return nil, fmt.Errorf("unsupported image format %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding image %w", err)
+	}
+
+	source := image.NewRGBA(image.Rect(0, 0, decoded.Bounds().Dx(), decoded.Bounds().Dy()))
+	draw.Draw(source, source.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+
+	thumbnails := make([]RenderedThumbnail, 0, len(sizes))
+	for _, size := range sizes {
+		scaled := downscale(source, size)
+
+		var buf bytes.Buffer
+		contentType := "image/png"
+		if format == "jpeg" {
+			contentType = "image/jpeg"
+			err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: 85})
+		} else {
+			err = png.Encode(&buf, scaled)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error encoding thumbnail %w", err)
+		}
+
+		thumbnails = append(thumbnails, RenderedThumbnail{
+			Size:        size,
+			Width:       scaled.Bounds().Dx(),
+			Height:      scaled.Bounds().Dy(),
+			ContentType: contentType,
+			Data:        buf.Bytes(),
+		})
+	}
+
+	return thumbnails, nil
+}
+
+// downscale fits src within a size x size box, averaging every source pixel
+// that falls into each destination pixel.
+func downscale(src *image.RGBA, size int) *image.RGBA {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	if srcW <= size && srcH <= size {
+		return src
+	}
+
+	dstW, dstH := size, size
+	if srcW >= srcH {
+		dstH = max(1, srcH*size/srcW)
+	} else {
+		dstW = max(1, srcW*size/srcH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			count := (y1 - y0) * (x1 - x0)
+			offset := y*dst.Stride + x*4
+			for i := range sum {
+				dst.Pix[offset+i] = uint8(sum[i] / count)
+			}
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,7 @@
+package service
+
+// TokenVerifier checks a bearer token's signature and standard claims and
+// returns its claims.
+type TokenVerifier interface {
+	Verify(token string) (map[string]any, error)
+}
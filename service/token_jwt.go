@@ -0,0 +1,302 @@
+package service
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	algHS256 = "HS256"
+	algRS256 = "RS256"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type JWTOptions struct {
+	HMACSecret       []byte
+	RSAPublicKeyFile string
+	JWKSFile         string
+	Issuer           string
+	Audience         string
+	Leeway           time.Duration
+}
+
+type jwtKey struct {
+	id     string
+	alg    string
+	secret []byte
+	public *rsa.PublicKey
+}
+
+// JWTVerifier validates HS256 and RS256 tokens. Each key is bound to one
+// algorithm, so an RSA public key can never be used as an HMAC secret.
+type JWTVerifier struct {
+	keys     []jwtKey
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+func NewJWTVerifier(opts JWTOptions) (*JWTVerifier, error) {
+	verifier := &JWTVerifier{issuer: opts.Issuer, audience: opts.Audience, leeway: opts.Leeway}
+
+	if len(opts.HMACSecret) > 0 {
+		verifier.keys = append(verifier.keys, jwtKey{alg: algHS256, secret: opts.HMACSecret})
+	}
+
+	if opts.RSAPublicKeyFile != "" {
+		public, err := loadRSAPublicKey(opts.RSAPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = append(verifier.keys, jwtKey{alg: algRS256, public: public})
+	}
+
+	if opts.JWKSFile != "" {
+		keys, err := loadJWKS(opts.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = append(verifier.keys, keys...)
+	}
+
+	if len(verifier.keys) == 0 {
+		return nil, fmt.Errorf("no JWT verification keys configured")
+	}
+
+	return verifier, nil
+}
+
+func (v *JWTVerifier) Verify(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	if !v.verifySignature(header.Alg, header.Kid, parts[0]+"."+parts[1], signature) {
+		return nil, fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+	}
+
+	claims := map[string]any{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (v *JWTVerifier) verifySignature(alg, kid, signingInput string, signature []byte) bool {
+	if alg != algHS256 && alg != algRS256 {
+		return false
+	}
+
+	for _, key := range v.keys {
+		if key.alg != alg || (kid != "" && key.id != "" && key.id != kid) {
+			continue
+		}
+
+		switch alg {
+		case algHS256:
+			mac := hmac.New(sha256.New, key.secret)
+			mac.Write([]byte(signingInput))
+			if hmac.Equal(mac.Sum(nil), signature) {
+				return true
+			}
+		case algRS256:
+			digest := sha256.Sum256([]byte(signingInput))
+			if rsa.VerifyPKCS1v15(key.public, crypto.SHA256, digest[:], signature) == nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (v *JWTVerifier) validateClaims(claims map[string]any) error {
+	now := time.Now()
+
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+	if !now.Before(exp.Add(v.leeway)) {
+		return fmt.Errorf("%w: token is expired", ErrInvalidToken)
+	}
+
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(v.leeway).Before(nbf) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+
+	if v.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.issuer {
+			return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+		}
+	}
+
+	if v.audience != "" && !hasAudience(claims["aud"], v.audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+func numericClaim(claims map[string]any, name string) (time.Time, bool) {
+	value, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(value), 0), true
+}
+
+// hasAudience accepts aud as a single string or an array of strings.
+func hasAudience(aud any, expected string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == expected
+	case []any:
+		for _, item := range value {
+			if item == expected {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(segment string, target any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RSA public key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("RSA public key file %s is not PEM encoded", path)
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RSA public key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key in %s is not an RSA key", path)
+	}
+
+	return key, nil
+}
+
+func loadJWKS(path string) ([]jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file: %w", err)
+	}
+
+	var keys []jwtKey
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		switch jwk.Kty {
+		case "RSA":
+			if jwk.Alg != "" && jwk.Alg != algRS256 {
+				continue
+			}
+			public, err := rsaKeyFromJWK(jwk.N, jwk.E)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RSA key %q in JWKS: %w", jwk.Kid, err)
+			}
+			keys = append(keys, jwtKey{id: jwk.Kid, alg: algRS256, public: public})
+		case "oct":
+			if jwk.Alg != "" && jwk.Alg != algHS256 {
+				continue
+			}
+			secret, err := base64.RawURLEncoding.DecodeString(jwk.K)
+			if err != nil {
+				return nil, fmt.Errorf("invalid symmetric key %q in JWKS: %w", jwk.Kid, err)
+			}
+			keys = append(keys, jwtKey{id: jwk.Kid, alg: algHS256, secret: secret})
+		}
+	}
+
+	return keys, nil
+}
+
+func rsaKeyFromJWK(n, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
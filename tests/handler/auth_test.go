@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/middleware"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type staticAuthenticator map[string]*entity.Principal
+
+func (a staticAuthenticator) Authenticate(ctx context.Context, credential string) (*entity.Principal, error) {
+	if credential == "bad" {
+		return nil, fmt.Errorf("%w: invalid api key", entity.ErrUnauthorized)
+	}
+
+	return a[credential], nil
+}
+
+func authRouter() *gin.Engine {
+	authenticator := staticAuthenticator{
+		"reader": {ID: "api_key:reader", Permissions: []string{entity.PermissionDocumentsRead}},
+		"admin":  {ID: "api_key:admin", Permissions: []string{entity.PermissionAdmin}},
+	}
+
+	router := gin.New()
+	api := router.Group("/api", middleware.AuthMiddleware(authenticator))
+	api.DELETE("/documents/:id", middleware.RequirePermission(entity.PermissionDocumentsDelete), func(c *gin.Context) {
+		principal, _ := entity.PrincipalFromContext(c.Request.Context())
+		c.String(http.StatusOK, principal.ID)
+	})
+
+	return router
+}
+
+func TestAuthMiddlewareStatuses(t *testing.T) {
+	router := authRouter()
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"missing credential", "", "", http.StatusUnauthorized},
+		{"rejected credential", "X-API-Key", "bad", http.StatusUnauthorized},
+		{"unknown credential", "Authorization", "Bearer nobody", http.StatusUnauthorized},
+		{"missing permission", "X-API-Key", "reader", http.StatusForbidden},
+		{"admin via bearer", "Authorization", "Bearer admin", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/api/documents/doc-1", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -29,7 +29,7 @@ func createDefaultMocks(errSaveFunc error, errUploadFunc error, errPublishFunc e
 	mockStorage := &mock_test.MockServiceStorage{}
 	mockQueue := &mock_test.MockServiceQueue{}
 
-	mockRepo.SaveFunc = func(ctx context.Context, doc *entity.Document) error {
+	mockRepo.SaveFunc = func(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error {
 		return errSaveFunc
 	}
 
@@ -128,7 +128,7 @@ func TestUploadHandlerBadRequest(t *testing.T) {
 func TestListHandlerSuccess(t *testing.T) {
 	mockRepo, mockStorage, mockQueue := createDefaultMocks(nil, nil, nil)
 
-	mockRepo.FindAllFunc = func(ctx context.Context) ([]*entity.Document, error) {
+	mockRepo.FindAllFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
 		return []*entity.Document{
 			{
 				ID:       "test-id-1",
@@ -172,6 +172,96 @@ func TestListHandlerSuccess(t *testing.T) {
 	}
 }
 
+func TestListHandlerParsesFilter(t *testing.T) {
+	mockRepo, mockStorage, mockQueue := createDefaultMocks(nil, nil, nil)
+
+	var received entity.DocumentFilter
+	mockRepo.FindAllFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+		received = filter
+		return nil, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
+	h := handler.NewDocumentHandler(uc)
+
+	router := gin.New()
+	router.GET("/api/documents", h.List)
+
+	req := httptest.NewRequest("GET", "/api/documents?tag=invoice&meta.customer=acme", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("List() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if len(received.Tags) != 1 || received.Tags[0] != "invoice" {
+		t.Errorf("List() filter tags = %v, want [invoice]", received.Tags)
+	}
+	if received.Metadata["customer"] != "acme" {
+		t.Errorf("List() filter metadata = %v, want customer=acme", received.Metadata)
+	}
+}
+
+func TestUpdateHandlerReplacesTags(t *testing.T) {
+	mockRepo, mockStorage, mockQueue := createDefaultMocks(nil, nil, nil)
+
+	mockRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: "doc1.pdf", Tags: []string{"old"}}, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
+	h := handler.NewDocumentHandler(uc)
+
+	router := gin.New()
+	router.PATCH("/api/documents/:id", h.Update)
+
+	req := httptest.NewRequest("PATCH", "/api/documents/test-id-123", bytes.NewBufferString(`{"tags":["Invoice"],"metadata":{"customer":"acme"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Update() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var response dto.DocumentResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(response.Tags) != 1 || response.Tags[0] != "invoice" {
+		t.Errorf("Update() Tags = %v, want [invoice]", response.Tags)
+	}
+	if response.Metadata["customer"] != "acme" {
+		t.Errorf("Update() Metadata = %v, want customer=acme", response.Metadata)
+	}
+}
+
+func TestUpdateHandlerStaleIfMatch(t *testing.T) {
+	mockRepo, mockStorage, mockQueue := createDefaultMocks(nil, nil, nil)
+
+	mockRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: "doc1.pdf", Version: 2}, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
+	h := handler.NewDocumentHandler(uc)
+
+	router := gin.New()
+	router.PATCH("/api/documents/:id", h.Update)
+
+	req := httptest.NewRequest("PATCH", "/api/documents/test-id-123", bytes.NewBufferString(`{"file_name":"renamed.pdf"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("Update() status = %d, want %d", rec.Code, http.StatusPreconditionFailed)
+	}
+}
+
 func TestDeleteHandlerSuccess(t *testing.T) {
 	mockRepo, mockStorage, mockQueue := createDefaultMocks(nil, nil, nil)
 
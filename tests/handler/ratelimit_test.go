@@ -0,0 +1,111 @@
+package handler_test
+
+import (
+	"docvault/middleware"
+	"docvault/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitMiddlewareRejectsOverBurst(t *testing.T) {
+	limiter := service.NewMemoryRateLimiter()
+
+	router := gin.New()
+	router.GET("/meta", middleware.RateLimitMiddleware(limiter, "metadata", service.RateLimit{PerMinute: 60, Burst: 2}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	statuses := []int{}
+	var last *httptest.ResponseRecorder
+	for range 3 {
+		last = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/meta", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		router.ServeHTTP(last, req)
+		statuses = append(statuses, last.Code)
+	}
+
+	if statuses[0] != http.StatusOK || statuses[1] != http.StatusOK || statuses[2] != http.StatusTooManyRequests {
+		t.Fatalf("statuses = %v, want [200 200 429]", statuses)
+	}
+	if last.Header().Get("Retry-After") != "1" || last.Header().Get("RateLimit-Remaining") != "0" || last.Header().Get("RateLimit-Limit") != "2" {
+		t.Errorf("429 headers = %v, want Retry-After 1, RateLimit-Limit 2 and RateLimit-Remaining 0", last.Header())
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/meta", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("other client status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitMiddlewareIgnoresSpoofedForwardedFor(t *testing.T) {
+	limiter := service.NewMemoryRateLimiter()
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("SetTrustedProxies() error = %v, want nil", err)
+	}
+	router.GET("/s/:token", middleware.RateLimitMiddleware(limiter, "downloads", service.RateLimit{PerMinute: 60, Burst: 2}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	statuses := []int{}
+	for _, forwarded := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/s/token", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwarded)
+		router.ServeHTTP(w, req)
+		statuses = append(statuses, w.Code)
+	}
+
+	if statuses[2] != http.StatusTooManyRequests {
+		t.Errorf("statuses = %v, want the third request limited despite a new X-Forwarded-For", statuses)
+	}
+}
+
+func TestTransferLimitMiddlewareCapsConcurrentTransfers(t *testing.T) {
+	limiter := service.NewMemoryRateLimiter()
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	router := gin.New()
+	router.GET("/download", middleware.TransferLimitMiddleware(limiter, 1), func(c *gin.Context) {
+		if c.Query("block") != "" {
+			close(started)
+			<-finish
+		}
+		c.Status(http.StatusOK)
+	})
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download?block=1", nil))
+		done <- w.Code
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download", nil))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("second transfer status = %d, Retry-After = %q, want 429 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(finish)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("first transfer status = %d, want %d", code, http.StatusOK)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("transfer after release status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
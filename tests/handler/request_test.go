@@ -0,0 +1,47 @@
+package handler_test
+
+import (
+	"docvault/entity"
+	"docvault/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestContextIgnoresForwardedForFromUntrustedClients(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		want    string
+	}{
+		{name: "no trusted proxies", proxies: nil, want: "203.0.113.7"},
+		{name: "request from a trusted proxy", proxies: []string{"203.0.113.0/24"}, want: "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			if err := router.SetTrustedProxies(tt.proxies); err != nil {
+				t.Fatalf("SetTrustedProxies() error = %v, want nil", err)
+			}
+
+			var clientIP string
+			router.Use(middleware.RequestContextMiddleware())
+			router.GET("/", func(c *gin.Context) {
+				info, _ := entity.RequestInfoFromContext(c.Request.Context())
+				clientIP = info.ClientIP
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "203.0.113.7:4321"
+			req.Header.Set("X-Forwarded-For", "198.51.100.1")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if clientIP != tt.want {
+				t.Errorf("client IP = %s, want %s", clientIP, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package handler_test
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/handler"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestShareDownloadTakesPasswordOnlyFromHeader(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: TestPDFFileName, StorageKey: "default/" + id, ContentType: "application/pdf"}, nil
+	}
+	storage := &mock_test.MockServiceStorage{}
+	storage.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(TestPDFContent)), nil
+	}
+
+	var saved *entity.ShareLink
+	shareRepo := &mock_test.MockShareLinkRepository{}
+	shareRepo.SaveFunc = func(ctx context.Context, link *entity.ShareLink) error {
+		saved = link
+		return nil
+	}
+	shareRepo.FindByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.ShareLink, error) {
+		return saved, nil
+	}
+
+	uc := usecase.NewShareUsecase(shareRepo, usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{}))
+	_, token, err := uc.Create(context.Background(), "doc-1", usecase.ShareInput{Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("Create() error = %v, want nil", err)
+	}
+
+	router := gin.New()
+	router.GET("/s/:token", handler.NewShareHandler(uc).Download)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/s/"+token+"?password=hunter2", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Download() with ?password= status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s/"+token, nil)
+	req.Header.Set("X-Share-Password", "hunter2")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != TestPDFContent {
+		t.Errorf("Download() with header status = %d, body = %q, want %d with the document", w.Code, w.Body.String(), http.StatusOK)
+	}
+}
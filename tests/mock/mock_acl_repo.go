@@ -0,0 +1,45 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+)
+
+type MockDocumentACLRepository struct {
+	SaveFunc            func(ctx context.Context, entry *entity.ACLEntry) error
+	FindByDocumentFunc  func(ctx context.Context, documentID string) ([]*entity.ACLEntry, error)
+	FindPermissionsFunc func(ctx context.Context, documentID string, subjects []string) ([]string, error)
+	DeleteFunc          func(ctx context.Context, documentID, id string) error
+}
+
+func (m *MockDocumentACLRepository) Save(ctx context.Context, entry *entity.ACLEntry) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, entry)
+	}
+
+	return nil
+}
+
+func (m *MockDocumentACLRepository) FindByDocument(ctx context.Context, documentID string) ([]*entity.ACLEntry, error) {
+	if m.FindByDocumentFunc != nil {
+		return m.FindByDocumentFunc(ctx, documentID)
+	}
+
+	return nil, nil
+}
+
+func (m *MockDocumentACLRepository) FindPermissions(ctx context.Context, documentID string, subjects []string) ([]string, error) {
+	if m.FindPermissionsFunc != nil {
+		return m.FindPermissionsFunc(ctx, documentID, subjects)
+	}
+
+	return nil, nil
+}
+
+func (m *MockDocumentACLRepository) Delete(ctx context.Context, documentID, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, documentID, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+	"time"
+)
+
+type MockAPIKeyRepository struct {
+	SaveFunc          func(ctx context.Context, key *entity.APIKey) error
+	FindByIdFunc      func(ctx context.Context, id string) (*entity.APIKey, error)
+	FindByPrefixFunc  func(ctx context.Context, prefix string) (*entity.APIKey, error)
+	FindAllFunc       func(ctx context.Context) ([]*entity.APIKey, error)
+	RevokeFunc        func(ctx context.Context, id string, revokedAt time.Time) error
+	TouchLastUsedFunc func(ctx context.Context, id string, usedAt time.Time) error
+}
+
+func (m *MockAPIKeyRepository) Save(ctx context.Context, key *entity.APIKey) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, key)
+	}
+
+	return nil
+}
+
+func (m *MockAPIKeyRepository) FindById(ctx context.Context, id string) (*entity.APIKey, error) {
+	if m.FindByIdFunc != nil {
+		return m.FindByIdFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockAPIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	if m.FindByPrefixFunc != nil {
+		return m.FindByPrefixFunc(ctx, prefix)
+	}
+
+	return nil, nil
+}
+
+func (m *MockAPIKeyRepository) FindAll(ctx context.Context) ([]*entity.APIKey, error) {
+	if m.FindAllFunc != nil {
+		return m.FindAllFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	if m.RevokeFunc != nil {
+		return m.RevokeFunc(ctx, id, revokedAt)
+	}
+
+	return nil
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	if m.TouchLastUsedFunc != nil {
+		return m.TouchLastUsedFunc(ctx, id, usedAt)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+)
+
+type MockAuditRepository struct {
+	AppendFunc    func(ctx context.Context, entry *entity.AuditEntry) error
+	LastFunc      func(ctx context.Context) (*entity.AuditEntry, error)
+	FindAllFunc   func(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error)
+	CountFunc     func(ctx context.Context, filter entity.AuditFilter) (int64, error)
+	FindAfterFunc func(ctx context.Context, afterSeq int64, limit int) ([]*entity.AuditEntry, error)
+}
+
+func (m *MockAuditRepository) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	if m.AppendFunc != nil {
+		return m.AppendFunc(ctx, entry)
+	}
+
+	return nil
+}
+
+func (m *MockAuditRepository) Last(ctx context.Context) (*entity.AuditEntry, error) {
+	if m.LastFunc != nil {
+		return m.LastFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockAuditRepository) FindAll(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	if m.FindAllFunc != nil {
+		return m.FindAllFunc(ctx, filter)
+	}
+
+	return nil, nil
+}
+
+func (m *MockAuditRepository) Count(ctx context.Context, filter entity.AuditFilter) (int64, error) {
+	if m.CountFunc != nil {
+		return m.CountFunc(ctx, filter)
+	}
+
+	return 0, nil
+}
+
+func (m *MockAuditRepository) FindAfter(ctx context.Context, afterSeq int64, limit int) ([]*entity.AuditEntry, error) {
+	if m.FindAfterFunc != nil {
+		return m.FindAfterFunc(ctx, afterSeq, limit)
+	}
+
+	return nil, nil
+}
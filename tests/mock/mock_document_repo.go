@@ -7,17 +7,33 @@ import (
 )
 
 type MockDocumentRepository struct {
-	SaveFunc        func(ctx context.Context, doc *entity.Document) error
+	SaveFunc        func(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error
 	FindByIdFunc    func(ctx context.Context, id string) (*entity.Document, error)
-	FindAllFunc     func(ctx context.Context) ([]*entity.Document, error)
+	FindAllFunc     func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error)
 	DeleteFunc      func(ctx context.Context, id string) error
 	FindExpiredFunc func(ctx context.Context, now time.Time) ([]*entity.Document, error)
-	PingFunc        func(ctx context.Context) error
+
+	UpdateFunc          func(ctx context.Context, doc *entity.Document, expectedVersion int64) error
+	CountFunc           func(ctx context.Context, filter entity.DocumentFilter) (int64, error)
+	UpdateRetentionFunc func(ctx context.Context, doc *entity.Document) error
+	UpdateScanFunc      func(ctx context.Context, doc *entity.Document) error
+
+	FindByScanStatusFunc  func(ctx context.Context, status string, attemptedBefore time.Time, limit int) ([]*entity.Document, error)
+	RecordScanFailureFunc func(ctx context.Context, id, failure string, attemptedAt time.Time, maxAttempts int) error
+
+	TrashFunc             func(ctx context.Context, id string, deletedAt time.Time) error
+	RestoreFunc           func(ctx context.Context, id string) error
+	FindTrashedFunc       func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error)
+	FindTrashedBeforeFunc func(ctx context.Context, before time.Time) ([]*entity.Document, error)
+	FindStoredFunc        func(ctx context.Context) ([]*entity.Document, error)
+	UpdateMissingFunc     func(ctx context.Context, id string, missingAt *time.Time) error
+
+	PingFunc func(ctx context.Context) error
 }
 
-func (m *MockDocumentRepository) Save(ctx context.Context, doc *entity.Document) error {
+func (m *MockDocumentRepository) Save(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error {
 	if m.SaveFunc != nil {
-		return m.SaveFunc(ctx, doc)
+		return m.SaveFunc(ctx, doc, quotas)
 	}
 
 	return nil
@@ -31,9 +47,9 @@ func (m *MockDocumentRepository) FindById(ctx context.Context, id string) (*enti
 	return nil, nil
 }
 
-func (m *MockDocumentRepository) FindAll(ctx context.Context) ([]*entity.Document, error) {
+func (m *MockDocumentRepository) FindAll(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
 	if m.FindAllFunc != nil {
-		return m.FindAllFunc(ctx)
+		return m.FindAllFunc(ctx, filter)
 	}
 
 	return nil, nil
@@ -55,6 +71,102 @@ func (m *MockDocumentRepository) FindExpired(ctx context.Context, now time.Time)
 	return nil, nil
 }
 
+func (m *MockDocumentRepository) Update(ctx context.Context, doc *entity.Document, expectedVersion int64) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, doc, expectedVersion)
+	}
+
+	return nil
+}
+
+func (m *MockDocumentRepository) UpdateRetention(ctx context.Context, doc *entity.Document) error {
+	if m.UpdateRetentionFunc != nil {
+		return m.UpdateRetentionFunc(ctx, doc)
+	}
+
+	return nil
+}
+
+func (m *MockDocumentRepository) UpdateScan(ctx context.Context, doc *entity.Document) error {
+	if m.UpdateScanFunc != nil {
+		return m.UpdateScanFunc(ctx, doc)
+	}
+
+	return nil
+}
+
+func (m *MockDocumentRepository) RecordScanFailure(ctx context.Context, id, failure string, attemptedAt time.Time, maxAttempts int) error {
+	if m.RecordScanFailureFunc != nil {
+		return m.RecordScanFailureFunc(ctx, id, failure, attemptedAt, maxAttempts)
+	}
+
+	return nil
+}
+
+func (m *MockDocumentRepository) FindByScanStatus(ctx context.Context, status string, attemptedBefore time.Time, limit int) ([]*entity.Document, error) {
+	if m.FindByScanStatusFunc != nil {
+		return m.FindByScanStatusFunc(ctx, status, attemptedBefore, limit)
+	}
+
+	return nil, nil
+}
+
+func (m *MockDocumentRepository) Count(ctx context.Context, filter entity.DocumentFilter) (int64, error) {
+	if m.CountFunc != nil {
+		return m.CountFunc(ctx, filter)
+	}
+
+	return 0, nil
+}
+
+func (m *MockDocumentRepository) Trash(ctx context.Context, id string, deletedAt time.Time) error {
+	if m.TrashFunc != nil {
+		return m.TrashFunc(ctx, id, deletedAt)
+	}
+
+	return nil
+}
+
+func (m *MockDocumentRepository) Restore(ctx context.Context, id string) error {
+	if m.RestoreFunc != nil {
+		return m.RestoreFunc(ctx, id)
+	}
+
+	return nil
+}
+
+func (m *MockDocumentRepository) FindTrashed(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+	if m.FindTrashedFunc != nil {
+		return m.FindTrashedFunc(ctx, filter)
+	}
+
+	return nil, nil
+}
+
+func (m *MockDocumentRepository) FindTrashedBefore(ctx context.Context, before time.Time) ([]*entity.Document, error) {
+	if m.FindTrashedBeforeFunc != nil {
+		return m.FindTrashedBeforeFunc(ctx, before)
+	}
+
+	return nil, nil
+}
+
+func (m *MockDocumentRepository) FindStored(ctx context.Context) ([]*entity.Document, error) {
+	if m.FindStoredFunc != nil {
+		return m.FindStoredFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockDocumentRepository) UpdateMissing(ctx context.Context, id string, missingAt *time.Time) error {
+	if m.UpdateMissingFunc != nil {
+		return m.UpdateMissingFunc(ctx, id, missingAt)
+	}
+
+	return nil
+}
+
 func (m *MockDocumentRepository) Ping(ctx context.Context) error {
 	if m.PingFunc != nil {
 		return m.PingFunc(ctx)
@@ -0,0 +1,72 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+)
+
+type MockFolderRepository struct {
+	SaveFunc          func(ctx context.Context, folder *entity.Folder) error
+	FindByIdFunc      func(ctx context.Context, id string) (*entity.Folder, error)
+	FindChildrenFunc  func(ctx context.Context, tenantID string, parentID *string) ([]*entity.Folder, error)
+	UpdateFunc        func(ctx context.Context, folder *entity.Folder) error
+	MoveFunc          func(ctx context.Context, folder *entity.Folder, parent *entity.Folder) error
+	DeleteSubtreeFunc func(ctx context.Context, folder *entity.Folder) error
+	StatsFunc         func(ctx context.Context, folder *entity.Folder) (*entity.FolderStats, error)
+}
+
+func (m *MockFolderRepository) Save(ctx context.Context, folder *entity.Folder) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, folder)
+	}
+
+	return nil
+}
+
+func (m *MockFolderRepository) FindById(ctx context.Context, id string) (*entity.Folder, error) {
+	if m.FindByIdFunc != nil {
+		return m.FindByIdFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockFolderRepository) FindChildren(ctx context.Context, tenantID string, parentID *string) ([]*entity.Folder, error) {
+	if m.FindChildrenFunc != nil {
+		return m.FindChildrenFunc(ctx, tenantID, parentID)
+	}
+
+	return nil, nil
+}
+
+func (m *MockFolderRepository) Update(ctx context.Context, folder *entity.Folder) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, folder)
+	}
+
+	return nil
+}
+
+func (m *MockFolderRepository) Move(ctx context.Context, folder *entity.Folder, parent *entity.Folder) error {
+	if m.MoveFunc != nil {
+		return m.MoveFunc(ctx, folder, parent)
+	}
+
+	return nil
+}
+
+func (m *MockFolderRepository) DeleteSubtree(ctx context.Context, folder *entity.Folder) error {
+	if m.DeleteSubtreeFunc != nil {
+		return m.DeleteSubtreeFunc(ctx, folder)
+	}
+
+	return nil
+}
+
+func (m *MockFolderRepository) Stats(ctx context.Context, folder *entity.Folder) (*entity.FolderStats, error) {
+	if m.StatsFunc != nil {
+		return m.StatsFunc(ctx, folder)
+	}
+
+	return &entity.FolderStats{}, nil
+}
@@ -0,0 +1,54 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+)
+
+type MockLegalHoldRepository struct {
+	SaveFunc           func(ctx context.Context, hold *entity.LegalHold) error
+	FindByIdFunc       func(ctx context.Context, id string) (*entity.LegalHold, error)
+	FindByDocumentFunc func(ctx context.Context, documentID string, activeOnly bool) ([]*entity.LegalHold, error)
+	ReleaseFunc        func(ctx context.Context, hold *entity.LegalHold) error
+	CountActiveFunc    func(ctx context.Context, documentID string) (int64, error)
+}
+
+func (m *MockLegalHoldRepository) Save(ctx context.Context, hold *entity.LegalHold) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, hold)
+	}
+
+	return nil
+}
+
+func (m *MockLegalHoldRepository) FindById(ctx context.Context, id string) (*entity.LegalHold, error) {
+	if m.FindByIdFunc != nil {
+		return m.FindByIdFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockLegalHoldRepository) FindByDocument(ctx context.Context, documentID string, activeOnly bool) ([]*entity.LegalHold, error) {
+	if m.FindByDocumentFunc != nil {
+		return m.FindByDocumentFunc(ctx, documentID, activeOnly)
+	}
+
+	return nil, nil
+}
+
+func (m *MockLegalHoldRepository) Release(ctx context.Context, hold *entity.LegalHold) error {
+	if m.ReleaseFunc != nil {
+		return m.ReleaseFunc(ctx, hold)
+	}
+
+	return nil
+}
+
+func (m *MockLegalHoldRepository) CountActive(ctx context.Context, documentID string) (int64, error) {
+	if m.CountActiveFunc != nil {
+		return m.CountActiveFunc(ctx, documentID)
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,36 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+)
+
+type MockImportRepository struct {
+	SaveFunc     func(ctx context.Context, imp *entity.Import) error
+	UpdateFunc   func(ctx context.Context, imp *entity.Import) error
+	FindByIdFunc func(ctx context.Context, id string) (*entity.Import, error)
+}
+
+func (m *MockImportRepository) Save(ctx context.Context, imp *entity.Import) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, imp)
+	}
+
+	return nil
+}
+
+func (m *MockImportRepository) Update(ctx context.Context, imp *entity.Import) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, imp)
+	}
+
+	return nil
+}
+
+func (m *MockImportRepository) FindById(ctx context.Context, id string) (*entity.Import, error) {
+	if m.FindByIdFunc != nil {
+		return m.FindByIdFunc(ctx, id)
+	}
+
+	return nil, entity.ErrImportNotFound
+}
@@ -0,0 +1,118 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+	"time"
+)
+
+type MockJobRepository struct {
+	SaveFunc               func(ctx context.Context, job *entity.Job) error
+	FindByIdFunc           func(ctx context.Context, id string) (*entity.Job, error)
+	FindAllFunc            func(ctx context.Context, filter entity.JobFilter) ([]*entity.Job, error)
+	FindByStatusFunc       func(ctx context.Context, status string) ([]*entity.Job, error)
+	ClaimFunc              func(ctx context.Context, now time.Time) (*entity.Job, error)
+	UpdateProgressFunc     func(ctx context.Context, id string, progress, total int64, now time.Time) (bool, error)
+	FinishFunc             func(ctx context.Context, job *entity.Job) error
+	RequestCancelFunc      func(ctx context.Context, id string, now time.Time) error
+	RequeueFunc            func(ctx context.Context, id string, now time.Time) error
+	ReleaseFunc            func(ctx context.Context, id string, now time.Time) error
+	FindFinishedBeforeFunc func(ctx context.Context, before time.Time) ([]*entity.Job, error)
+	DeleteFunc             func(ctx context.Context, id string) error
+}
+
+func (m *MockJobRepository) Save(ctx context.Context, job *entity.Job) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, job)
+	}
+
+	return nil
+}
+
+func (m *MockJobRepository) FindById(ctx context.Context, id string) (*entity.Job, error) {
+	if m.FindByIdFunc != nil {
+		return m.FindByIdFunc(ctx, id)
+	}
+
+	return nil, entity.ErrJobNotFound
+}
+
+func (m *MockJobRepository) FindAll(ctx context.Context, filter entity.JobFilter) ([]*entity.Job, error) {
+	if m.FindAllFunc != nil {
+		return m.FindAllFunc(ctx, filter)
+	}
+
+	return []*entity.Job{}, nil
+}
+
+func (m *MockJobRepository) FindByStatus(ctx context.Context, status string) ([]*entity.Job, error) {
+	if m.FindByStatusFunc != nil {
+		return m.FindByStatusFunc(ctx, status)
+	}
+
+	return []*entity.Job{}, nil
+}
+
+func (m *MockJobRepository) Claim(ctx context.Context, now time.Time) (*entity.Job, error) {
+	if m.ClaimFunc != nil {
+		return m.ClaimFunc(ctx, now)
+	}
+
+	return nil, nil
+}
+
+func (m *MockJobRepository) UpdateProgress(ctx context.Context, id string, progress, total int64, now time.Time) (bool, error) {
+	if m.UpdateProgressFunc != nil {
+		return m.UpdateProgressFunc(ctx, id, progress, total, now)
+	}
+
+	return false, nil
+}
+
+func (m *MockJobRepository) Finish(ctx context.Context, job *entity.Job) error {
+	if m.FinishFunc != nil {
+		return m.FinishFunc(ctx, job)
+	}
+
+	return nil
+}
+
+func (m *MockJobRepository) RequestCancel(ctx context.Context, id string, now time.Time) error {
+	if m.RequestCancelFunc != nil {
+		return m.RequestCancelFunc(ctx, id, now)
+	}
+
+	return nil
+}
+
+func (m *MockJobRepository) Requeue(ctx context.Context, id string, now time.Time) error {
+	if m.RequeueFunc != nil {
+		return m.RequeueFunc(ctx, id, now)
+	}
+
+	return nil
+}
+
+func (m *MockJobRepository) Release(ctx context.Context, id string, now time.Time) error {
+	if m.ReleaseFunc != nil {
+		return m.ReleaseFunc(ctx, id, now)
+	}
+
+	return nil
+}
+
+func (m *MockJobRepository) FindFinishedBefore(ctx context.Context, before time.Time) ([]*entity.Job, error) {
+	if m.FindFinishedBeforeFunc != nil {
+		return m.FindFinishedBeforeFunc(ctx, before)
+	}
+
+	return []*entity.Job{}, nil
+}
+
+func (m *MockJobRepository) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+
+	return nil
+}
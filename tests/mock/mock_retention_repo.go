@@ -0,0 +1,54 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+)
+
+type MockRetentionPolicyRepository struct {
+	SaveFunc     func(ctx context.Context, policy *entity.RetentionPolicy) error
+	FindByIdFunc func(ctx context.Context, id string) (*entity.RetentionPolicy, error)
+	FindAllFunc  func(ctx context.Context) ([]*entity.RetentionPolicy, error)
+	UpdateFunc   func(ctx context.Context, policy *entity.RetentionPolicy) error
+	DeleteFunc   func(ctx context.Context, id string) error
+}
+
+func (m *MockRetentionPolicyRepository) Save(ctx context.Context, policy *entity.RetentionPolicy) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, policy)
+	}
+
+	return nil
+}
+
+func (m *MockRetentionPolicyRepository) FindById(ctx context.Context, id string) (*entity.RetentionPolicy, error) {
+	if m.FindByIdFunc != nil {
+		return m.FindByIdFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockRetentionPolicyRepository) FindAll(ctx context.Context) ([]*entity.RetentionPolicy, error) {
+	if m.FindAllFunc != nil {
+		return m.FindAllFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockRetentionPolicyRepository) Update(ctx context.Context, policy *entity.RetentionPolicy) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, policy)
+	}
+
+	return nil
+}
+
+func (m *MockRetentionPolicyRepository) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+
+	return nil
+}
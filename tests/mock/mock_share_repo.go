@@ -0,0 +1,55 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+	"time"
+)
+
+type MockShareLinkRepository struct {
+	SaveFunc            func(ctx context.Context, link *entity.ShareLink) error
+	FindByTokenHashFunc func(ctx context.Context, tokenHash string) (*entity.ShareLink, error)
+	FindByDocumentFunc  func(ctx context.Context, documentID string) ([]*entity.ShareLink, error)
+	RevokeFunc          func(ctx context.Context, documentID, id string, revokedAt time.Time) error
+	ConsumeDownloadFunc func(ctx context.Context, id string, now time.Time) error
+}
+
+func (m *MockShareLinkRepository) Save(ctx context.Context, link *entity.ShareLink) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, link)
+	}
+
+	return nil
+}
+
+func (m *MockShareLinkRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.ShareLink, error) {
+	if m.FindByTokenHashFunc != nil {
+		return m.FindByTokenHashFunc(ctx, tokenHash)
+	}
+
+	return nil, nil
+}
+
+func (m *MockShareLinkRepository) FindByDocument(ctx context.Context, documentID string) ([]*entity.ShareLink, error) {
+	if m.FindByDocumentFunc != nil {
+		return m.FindByDocumentFunc(ctx, documentID)
+	}
+
+	return nil, nil
+}
+
+func (m *MockShareLinkRepository) Revoke(ctx context.Context, documentID, id string, revokedAt time.Time) error {
+	if m.RevokeFunc != nil {
+		return m.RevokeFunc(ctx, documentID, id, revokedAt)
+	}
+
+	return nil
+}
+
+func (m *MockShareLinkRepository) ConsumeDownload(ctx context.Context, id string, now time.Time) error {
+	if m.ConsumeDownloadFunc != nil {
+		return m.ConsumeDownloadFunc(ctx, id, now)
+	}
+
+	return nil
+}
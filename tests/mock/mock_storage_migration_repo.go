@@ -0,0 +1,54 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+)
+
+type MockStorageMigrationRepository struct {
+	SaveFunc       func(ctx context.Context, migration *entity.StorageMigration) error
+	UpdateFunc     func(ctx context.Context, migration *entity.StorageMigration) error
+	FindLatestFunc func(ctx context.Context) (*entity.StorageMigration, error)
+	SaveItemFunc   func(ctx context.Context, item *entity.StorageMigrationItem) error
+	FindItemsFunc  func(ctx context.Context, migrationID string) ([]*entity.StorageMigrationItem, error)
+}
+
+func (m *MockStorageMigrationRepository) Save(ctx context.Context, migration *entity.StorageMigration) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, migration)
+	}
+
+	return nil
+}
+
+func (m *MockStorageMigrationRepository) Update(ctx context.Context, migration *entity.StorageMigration) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, migration)
+	}
+
+	return nil
+}
+
+func (m *MockStorageMigrationRepository) FindLatest(ctx context.Context) (*entity.StorageMigration, error) {
+	if m.FindLatestFunc != nil {
+		return m.FindLatestFunc(ctx)
+	}
+
+	return nil, entity.ErrStorageMigrationNotFound
+}
+
+func (m *MockStorageMigrationRepository) SaveItem(ctx context.Context, item *entity.StorageMigrationItem) error {
+	if m.SaveItemFunc != nil {
+		return m.SaveItemFunc(ctx, item)
+	}
+
+	return nil
+}
+
+func (m *MockStorageMigrationRepository) FindItems(ctx context.Context, migrationID string) ([]*entity.StorageMigrationItem, error) {
+	if m.FindItemsFunc != nil {
+		return m.FindItemsFunc(ctx, migrationID)
+	}
+
+	return nil, nil
+}
@@ -2,6 +2,7 @@ package mock_test
 
 import (
 	"context"
+	"docvault/service"
 	"io"
 )
 
@@ -9,6 +10,7 @@ type MockServiceStorage struct {
 	UploadFunc   func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error
 	DownloadFunc func(ctx context.Context, filename string) (io.ReadCloser, error)
 	DeleteFunc   func(ctx context.Context, filename string) error
+	ListFunc     func(ctx context.Context, prefix string) ([]service.StorageObject, error)
 	HealthFunc   func(ctx context.Context) error
 }
 
@@ -36,6 +38,14 @@ func (s *MockServiceStorage) Delete(ctx context.Context, filename string) error
 	return nil
 }
 
+func (s *MockServiceStorage) List(ctx context.Context, prefix string) ([]service.StorageObject, error) {
+	if s.ListFunc != nil {
+		return s.ListFunc(ctx, prefix)
+	}
+
+	return []service.StorageObject{}, nil
+}
+
 func (s *MockServiceStorage) Health(ctx context.Context) error {
 	if s.HealthFunc != nil {
 		return s.HealthFunc(ctx)
@@ -0,0 +1,54 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+)
+
+type MockTenantRepository struct {
+	SaveFunc     func(ctx context.Context, tenant *entity.Tenant) error
+	FindByIdFunc func(ctx context.Context, id string) (*entity.Tenant, error)
+	FindAllFunc  func(ctx context.Context) ([]*entity.Tenant, error)
+	UpdateFunc   func(ctx context.Context, tenant *entity.Tenant) error
+	DeleteFunc   func(ctx context.Context, id string) error
+}
+
+func (m *MockTenantRepository) Save(ctx context.Context, tenant *entity.Tenant) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, tenant)
+	}
+
+	return nil
+}
+
+func (m *MockTenantRepository) FindById(ctx context.Context, id string) (*entity.Tenant, error) {
+	if m.FindByIdFunc != nil {
+		return m.FindByIdFunc(ctx, id)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTenantRepository) FindAll(ctx context.Context) ([]*entity.Tenant, error) {
+	if m.FindAllFunc != nil {
+		return m.FindAllFunc(ctx)
+	}
+
+	return nil, nil
+}
+
+func (m *MockTenantRepository) Update(ctx context.Context, tenant *entity.Tenant) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, tenant)
+	}
+
+	return nil
+}
+
+func (m *MockTenantRepository) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+)
+
+type MockThumbnailRepository struct {
+	SaveFunc           func(ctx context.Context, thumbnail *entity.Thumbnail) error
+	FindFunc           func(ctx context.Context, documentID string, size int) (*entity.Thumbnail, error)
+	FindByDocumentFunc func(ctx context.Context, documentID string) ([]*entity.Thumbnail, error)
+}
+
+func (m *MockThumbnailRepository) Save(ctx context.Context, thumbnail *entity.Thumbnail) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, thumbnail)
+	}
+
+	return nil
+}
+
+func (m *MockThumbnailRepository) Find(ctx context.Context, documentID string, size int) (*entity.Thumbnail, error) {
+	if m.FindFunc != nil {
+		return m.FindFunc(ctx, documentID, size)
+	}
+
+	return nil, entity.ErrThumbnailNotFound
+}
+
+func (m *MockThumbnailRepository) FindByDocument(ctx context.Context, documentID string) ([]*entity.Thumbnail, error) {
+	if m.FindByDocumentFunc != nil {
+		return m.FindByDocumentFunc(ctx, documentID)
+	}
+
+	return []*entity.Thumbnail{}, nil
+}
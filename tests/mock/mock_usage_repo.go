@@ -0,0 +1,27 @@
+package mock_test
+
+import (
+	"context"
+	"docvault/entity"
+)
+
+type MockUsageRepository struct {
+	FindFunc      func(ctx context.Context, scope, subjectID string) (*entity.Usage, error)
+	ReconcileFunc func(ctx context.Context) ([]*entity.Usage, error)
+}
+
+func (m *MockUsageRepository) Find(ctx context.Context, scope, subjectID string) (*entity.Usage, error) {
+	if m.FindFunc != nil {
+		return m.FindFunc(ctx, scope, subjectID)
+	}
+
+	return &entity.Usage{Scope: scope, SubjectID: subjectID}, nil
+}
+
+func (m *MockUsageRepository) Reconcile(ctx context.Context) ([]*entity.Usage, error) {
+	if m.ReconcileFunc != nil {
+		return m.ReconcileFunc(ctx)
+	}
+
+	return nil, nil
+}
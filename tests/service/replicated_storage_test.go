@@ -0,0 +1,272 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"docvault/service"
+	mock_test "docvault/tests/mock"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// replica is an in-memory backend whose operations can be made to fail.
+type replica struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	fail    error
+}
+
+func newReplica(objects map[string]string) *replica {
+	r := &replica{objects: map[string][]byte{}}
+	for key, content := range objects {
+		r.objects[key] = []byte(content)
+	}
+	return r
+}
+
+func (r *replica) get(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	content, ok := r.objects[key]
+	return string(content), ok
+}
+
+func (r *replica) storage() *mock_test.MockServiceStorage {
+	storage := &mock_test.MockServiceStorage{}
+	storage.UploadFunc = func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+		if r.fail != nil {
+			return r.fail
+		}
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		r.mu.Lock()
+		r.objects[filename] = content
+		r.mu.Unlock()
+		return nil
+	}
+	storage.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		if r.fail != nil {
+			return nil, r.fail
+		}
+		content, ok := r.get(filename)
+		if !ok {
+			return nil, service.ErrObjectNotFound
+		}
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+	storage.DeleteFunc = func(ctx context.Context, filename string) error {
+		if r.fail != nil {
+			return r.fail
+		}
+		r.mu.Lock()
+		delete(r.objects, filename)
+		r.mu.Unlock()
+		return nil
+	}
+	storage.ListFunc = func(ctx context.Context, prefix string) ([]service.StorageObject, error) {
+		if r.fail != nil {
+			return nil, r.fail
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		var listed []service.StorageObject
+		for key, content := range r.objects {
+			if strings.HasPrefix(key, prefix) {
+				listed = append(listed, service.StorageObject{Key: key, Size: int64(len(content))})
+			}
+		}
+		return listed, nil
+	}
+	storage.HealthFunc = func(ctx context.Context) error {
+		return r.fail
+	}
+
+	return storage
+}
+
+func replicatedStorage(t *testing.T, primary, secondary *replica, mode string) *service.ReplicatedStorage {
+	t.Helper()
+
+	storage, err := service.NewReplicatedStorage(primary.storage(), secondary.storage(), mode)
+	if err != nil {
+		t.Fatalf("NewReplicatedStorage() error = %v, want nil", err)
+	}
+	return storage
+}
+
+func pendingRepairs(storage *service.ReplicatedStorage) map[string]int {
+	pending := map[string]int{}
+	for _, status := range storage.Replicas(context.Background()) {
+		pending[status.Name] = status.PendingRepairs
+	}
+	return pending
+}
+
+func TestReplicatedStorageSyncWritesBothReplicas(t *testing.T) {
+	primary, secondary := newReplica(nil), newReplica(nil)
+	storage := replicatedStorage(t, primary, secondary, service.ReplicationSync)
+	ctx := context.Background()
+
+	if err := storage.Upload(ctx, "default/doc-1", 5, "text/plain", strings.NewReader("hello")); err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+	for name, r := range map[string]*replica{"primary": primary, "secondary": secondary} {
+		if content, _ := r.get("default/doc-1"); content != "hello" {
+			t.Errorf("%s holds %q, want hello", name, content)
+		}
+	}
+
+	if err := storage.Delete(ctx, "default/doc-1"); err != nil {
+		t.Fatalf("Delete() error = %v, want nil", err)
+	}
+	if _, ok := secondary.get("default/doc-1"); ok {
+		t.Error("secondary kept the deleted object")
+	}
+}
+
+func TestReplicatedStorageSyncQueuesFailedReplicaWrites(t *testing.T) {
+	primary, secondary := newReplica(nil), newReplica(nil)
+	secondary.fail = errors.New("secondary down")
+	storage := replicatedStorage(t, primary, secondary, service.ReplicationSync)
+	ctx := context.Background()
+
+	if err := storage.Upload(ctx, "default/doc-1", 5, "text/plain", strings.NewReader("hello")); err != nil {
+		t.Fatalf("Upload() error = %v, want nil while the primary is up", err)
+	}
+	if pending := pendingRepairs(storage); pending["secondary"] != 1 {
+		t.Fatalf("pending repairs = %v, want 1 on the secondary", pending)
+	}
+
+	storage.Repair(ctx)
+	if pending := pendingRepairs(storage); pending["secondary"] != 1 {
+		t.Errorf("pending repairs after failed retry = %v, want it kept", pending)
+	}
+
+	secondary.fail = nil
+	storage.Repair(ctx)
+	if content, _ := secondary.get("default/doc-1"); content != "hello" {
+		t.Errorf("secondary holds %q after repair, want hello", content)
+	}
+	if pending := pendingRepairs(storage); pending["secondary"] != 0 {
+		t.Errorf("pending repairs = %v, want none", pending)
+	}
+}
+
+func TestReplicatedStorageSyncUndoesReplicaWhenPrimaryFails(t *testing.T) {
+	primary, secondary := newReplica(nil), newReplica(nil)
+	primary.fail = errors.New("primary down")
+	storage := replicatedStorage(t, primary, secondary, service.ReplicationSync)
+
+	if err := storage.Upload(context.Background(), "default/doc-1", 5, "text/plain", strings.NewReader("hello")); err == nil {
+		t.Fatal("Upload() error = nil, want the primary's error")
+	}
+	if _, ok := secondary.get("default/doc-1"); ok {
+		t.Error("secondary kept an object the primary never stored")
+	}
+}
+
+func TestReplicatedStorageAsyncReplicatesInBackground(t *testing.T) {
+	primary, secondary := newReplica(nil), newReplica(nil)
+	storage := replicatedStorage(t, primary, secondary, service.ReplicationAsync)
+	ctx := context.Background()
+
+	if err := storage.Upload(ctx, "default/doc-1", 5, "text/plain", strings.NewReader("hello")); err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+	if _, ok := secondary.get("default/doc-1"); ok {
+		t.Fatal("secondary written before the repair queue ran")
+	}
+	select {
+	case <-storage.Queued():
+	default:
+		t.Fatal("Upload() did not signal the repair queue")
+	}
+
+	storage.Repair(ctx)
+	if content, _ := secondary.get("default/doc-1"); content != "hello" {
+		t.Errorf("secondary holds %q, want hello", content)
+	}
+
+	storage.Delete(ctx, "default/doc-1")
+	storage.Repair(ctx)
+	if _, ok := secondary.get("default/doc-1"); ok {
+		t.Error("secondary kept the deleted object")
+	}
+}
+
+func TestReplicatedStorageFailsOverReads(t *testing.T) {
+	primary := newReplica(map[string]string{"default/doc-1": "one"})
+	secondary := newReplica(map[string]string{"default/doc-1": "one", "default/doc-2": "two"})
+	storage := replicatedStorage(t, primary, secondary, service.ReplicationSync)
+	ctx := context.Background()
+
+	// doc-2 is gone from the primary; it is served from the secondary and
+	// copied back.
+	object, err := storage.Download(ctx, "default/doc-2")
+	if err != nil {
+		t.Fatalf("Download(doc-2) error = %v, want nil", err)
+	}
+	if content, _ := io.ReadAll(object); string(content) != "two" {
+		t.Errorf("Download(doc-2) = %q, want two", content)
+	}
+	storage.Repair(ctx)
+	if content, _ := primary.get("default/doc-2"); content != "two" {
+		t.Errorf("primary holds %q after read repair, want two", content)
+	}
+
+	primary.fail = errors.New("primary down")
+	object, err = storage.Download(ctx, "default/doc-1")
+	if err != nil {
+		t.Fatalf("Download() with the primary down error = %v, want nil", err)
+	}
+	if content, _ := io.ReadAll(object); !bytes.Equal(content, []byte("one")) {
+		t.Errorf("Download() with the primary down = %q, want one", content)
+	}
+
+	if err := storage.Health(ctx); err != nil {
+		t.Errorf("Health() error = %v, want nil while the secondary is up", err)
+	}
+	for _, status := range storage.Replicas(ctx) {
+		if (status.Name == "primary") != (status.Err != nil) {
+			t.Errorf("Replicas() %s error = %v, want only the primary down", status.Name, status.Err)
+		}
+	}
+}
+
+func TestReplicatedStorageResyncQueuesDifferences(t *testing.T) {
+	primary := newReplica(map[string]string{"default/doc-1": "one", "default/doc-2": "two"})
+	// doc-4 was purged, but the restart lost the delete queued for the
+	// secondary.
+	secondary := newReplica(map[string]string{"default/doc-2": "tw", "default/doc-3": "three", "default/doc-4": "four"})
+	storage := replicatedStorage(t, primary, secondary, service.ReplicationSync)
+	ctx := context.Background()
+
+	referenced := func(key string) bool { return key != "default/doc-4" }
+	if err := storage.Resync(ctx, referenced); err != nil {
+		t.Fatalf("Resync() error = %v, want nil", err)
+	}
+	if pending := pendingRepairs(storage); pending["secondary"] != 3 || pending["primary"] != 1 {
+		t.Fatalf("pending repairs = %v, want 3 on the secondary and 1 on the primary", pending)
+	}
+
+	storage.Repair(ctx)
+	for key, want := range map[string]string{"default/doc-1": "one", "default/doc-2": "two"} {
+		if content, _ := secondary.get(key); content != want {
+			t.Errorf("secondary %s = %q, want %q", key, content, want)
+		}
+	}
+	if content, _ := primary.get("default/doc-3"); content != "three" {
+		t.Errorf("primary doc-3 = %q, want it copied back", content)
+	}
+	if _, ok := primary.get("default/doc-4"); ok {
+		t.Error("primary doc-4 exists, want the purged object not copied back")
+	}
+	if _, ok := secondary.get("default/doc-4"); ok {
+		t.Error("secondary doc-4 exists, want the purged object deleted")
+	}
+}
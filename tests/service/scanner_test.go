@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"bufio"
+	"context"
+	"docvault/service"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd accepts one INSTREAM session and replies with reply(content).
+func fakeClamd(t *testing.T, reply func(content string) string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		command, err := r.ReadString(0)
+		if err != nil || command != "zINSTREAM\x00" {
+			conn.Write([]byte("UNKNOWN COMMAND\x00"))
+			return
+		}
+
+		var content strings.Builder
+		for {
+			var size uint32
+			if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if _, err := io.CopyN(&content, r, int64(size)); err != nil {
+				return
+			}
+		}
+
+		conn.Write([]byte(reply(content.String()) + "\x00"))
+	}()
+
+	return listener.Addr().String()
+}
+
+func clamdReply(content string) string {
+	if strings.Contains(content, eicar) {
+		return "stream: Win.Test.EICAR_HDB-1 FOUND"
+	}
+	return "stream: OK"
+}
+
+func TestClamdScannerReportsCleanStream(t *testing.T) {
+	scanner := service.NewClamdScanner(fakeClamd(t, clamdReply), time.Second)
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("harmless ", 20000)))
+	if err != nil {
+		t.Fatalf("Scan() error = %v, want nil", err)
+	}
+	if result.Infected {
+		t.Errorf("Scan() Infected = true, want false")
+	}
+}
+
+func TestClamdScannerReportsSignature(t *testing.T) {
+	scanner := service.NewClamdScanner(fakeClamd(t, clamdReply), time.Second)
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader("prefix "+eicar))
+	if err != nil {
+		t.Fatalf("Scan() error = %v, want nil", err)
+	}
+	if !result.Infected || result.Signature != "Win.Test.EICAR_HDB-1" {
+		t.Errorf("Scan() = %+v, want infected with Win.Test.EICAR_HDB-1", result)
+	}
+}
+
+func TestClamdScannerReturnsErrorReply(t *testing.T) {
+	scanner := service.NewClamdScanner(fakeClamd(t, func(string) string {
+		return "INSTREAM size limit exceeded. ERROR"
+	}), time.Second)
+
+	if _, err := scanner.Scan(context.Background(), strings.NewReader("data")); err == nil {
+		t.Errorf("Scan() error = nil, want error for ERROR reply")
+	}
+}
+
+func TestSignatureScannerFindsEICARAcrossReads(t *testing.T) {
+	scanner := service.NewSignatureScanner()
+
+	// oneByteReader splits the signature over many reads.
+	content := strings.Repeat("a", 40000) + eicar
+	result, err := scanner.Scan(context.Background(), &oneByteReader{r: strings.NewReader(content)})
+	if err != nil {
+		t.Fatalf("Scan() error = %v, want nil", err)
+	}
+	if !result.Infected || result.Signature != "EICAR-Test-File" {
+		t.Errorf("Scan() = %+v, want infected with EICAR-Test-File", result)
+	}
+
+	result, err = scanner.Scan(context.Background(), strings.NewReader(content[:len(content)-1]))
+	if err != nil || result.Infected {
+		t.Errorf("Scan() of truncated signature = %+v, %v, want clean", result, err)
+	}
+}
+
+type oneByteReader struct {
+	r io.Reader
+}
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return o.r.Read(p[:1])
+}
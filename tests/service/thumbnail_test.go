@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"bytes"
+	"docvault/service"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+
+	return img
+}
+
+func TestImageThumbnailerFitsWithoutEnlarging(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, testImage(400, 200))
+
+	thumbnails, err := service.NewImageThumbnailer().Thumbnails(&buf, []int{128, 512})
+	if err != nil {
+		t.Fatalf("Thumbnails() error = %v, want nil", err)
+	}
+
+	want := [][2]int{{128, 64}, {400, 200}}
+	for i, thumbnail := range thumbnails {
+		if thumbnail.Width != want[i][0] || thumbnail.Height != want[i][1] || thumbnail.ContentType != "image/png" {
+			t.Errorf("Thumbnails()[%d] = %dx%d %s, want %dx%d image/png", i, thumbnail.Width, thumbnail.Height, thumbnail.ContentType, want[i][0], want[i][1])
+		}
+
+		decoded, err := png.Decode(bytes.NewReader(thumbnail.Data))
+		if err != nil || decoded.Bounds().Dx() != thumbnail.Width {
+			t.Errorf("Thumbnails()[%d] data does not decode to a %d pixel wide PNG: %v", i, thumbnail.Width, err)
+		}
+	}
+}
+
+func TestImageThumbnailerKeepsJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, testImage(300, 600), nil)
+
+	thumbnails, err := service.NewImageThumbnailer().Thumbnails(&buf, []int{100})
+	if err != nil {
+		t.Fatalf("Thumbnails() error = %v, want nil", err)
+	}
+
+	if thumbnails[0].ContentType != "image/jpeg" || thumbnails[0].Width != 50 || thumbnails[0].Height != 100 {
+		t.Errorf("Thumbnails() = %dx%d %s, want 50x100 image/jpeg", thumbnails[0].Width, thumbnails[0].Height, thumbnails[0].ContentType)
+	}
+}
+
+func TestImageThumbnailerRejectsNonImage(t *testing.T) {
+	if _, err := service.NewImageThumbnailer().Thumbnails(strings.NewReader("%PDF-1.7"), []int{128}); err == nil {
+		t.Errorf("Thumbnails() error = nil, want error for non-image content")
+	}
+}
@@ -0,0 +1,168 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withPrincipal(id string, groups ...string) context.Context {
+	return entity.WithPrincipal(context.Background(), &entity.Principal{
+		ID:          id,
+		Groups:      groups,
+		Permissions: []string{entity.PermissionDocumentsRead, entity.PermissionDocumentsWrite, entity.PermissionDocumentsDelete},
+	})
+}
+
+func ownedDocumentRepo(owner string) *mock_test.MockDocumentRepository {
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: "report.pdf", OwnerID: &owner}, nil
+	}
+
+	return docRepo
+}
+
+func TestUploadRecordsOwner(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+
+	doc, err := uc.Upload(withPrincipal("user:alice"), usecase.UploadInput{FileName: "a.txt", FileSize: 4, File: strings.NewReader("data")})
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+	if doc.OwnerID == nil || *doc.OwnerID != "user:alice" {
+		t.Errorf("Upload() OwnerID = %v, want user:alice", doc.OwnerID)
+	}
+}
+
+func TestGetMetadataHidesDocumentWithoutGrant(t *testing.T) {
+	aclRepo := &mock_test.MockDocumentACLRepository{}
+	uc := usecase.NewDocumentUsecase(ownedDocumentRepo("user:alice"), &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithACL(aclRepo))
+
+	if _, err := uc.GetMetadata(withPrincipal("user:alice"), "doc-1"); err != nil {
+		t.Errorf("GetMetadata() as owner error = %v, want nil", err)
+	}
+
+	if _, err := uc.GetMetadata(withPrincipal("user:bob"), "doc-1"); !errors.Is(err, entity.ErrDocumentNotFound) {
+		t.Errorf("GetMetadata() without grant error = %v, want %v", err, entity.ErrDocumentNotFound)
+	}
+
+	admin := entity.WithPrincipal(context.Background(), &entity.Principal{ID: "user:root", Permissions: []string{entity.PermissionAdmin}})
+	if _, err := uc.GetMetadata(admin, "doc-1"); err != nil {
+		t.Errorf("GetMetadata() as admin error = %v, want nil", err)
+	}
+}
+
+func TestDeleteRequiresDeleteGrant(t *testing.T) {
+	docRepo := ownedDocumentRepo("user:alice")
+	docRepo.TrashFunc = func(ctx context.Context, id string, deletedAt time.Time) error {
+		t.Errorf("Delete() trashed a document the caller may only read")
+		return nil
+	}
+
+	aclRepo := &mock_test.MockDocumentACLRepository{}
+	aclRepo.FindPermissionsFunc = func(ctx context.Context, documentID string, subjects []string) ([]string, error) {
+		if slices.Contains(subjects, "group:legal") {
+			return []string{entity.ACLPermissionRead}, nil
+		}
+		return []string{}, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithACL(aclRepo))
+	ctx := withPrincipal("user:bob", "legal")
+
+	if _, err := uc.GetMetadata(ctx, "doc-1"); err != nil {
+		t.Errorf("GetMetadata() with group grant error = %v, want nil", err)
+	}
+
+	if err := uc.Delete(ctx, "doc-1"); !errors.Is(err, entity.ErrForbidden) {
+		t.Errorf("Delete() error = %v, want %v", err, entity.ErrForbidden)
+	}
+}
+
+func TestListRestrictsToCallerSubjects(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	var subjects []string
+	docRepo.FindAllFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+		subjects = filter.Subjects
+		return nil, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+
+	if _, err := uc.List(withPrincipal("user:bob", "legal"), entity.DocumentFilter{}); err != nil {
+		t.Fatalf("List() error = %v, want nil", err)
+	}
+	if !slices.Equal(subjects, []string{"user:bob", "group:legal"}) {
+		t.Errorf("List() Subjects = %v, want user:bob and group:legal", subjects)
+	}
+
+	if _, err := uc.List(context.Background(), entity.DocumentFilter{}); err != nil {
+		t.Fatalf("List() error = %v, want nil", err)
+	}
+	if subjects != nil {
+		t.Errorf("List() without principal Subjects = %v, want nil", subjects)
+	}
+}
+
+func TestListTrashFiltersInTheRepository(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	var got entity.DocumentFilter
+	docRepo.FindTrashedFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+		got = filter
+		return nil, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+
+	ctx := entity.WithTenant(withPrincipal("user:bob", "legal"), "acme")
+	if _, err := uc.ListTrash(ctx, entity.DocumentFilter{TenantID: "globex", Limit: 20, Offset: 40}); err != nil {
+		t.Fatalf("ListTrash() error = %v, want nil", err)
+	}
+	if got.TenantID != "acme" || !slices.Equal(got.Subjects, []string{"user:bob", "group:legal"}) || got.Limit != 20 || got.Offset != 40 {
+		t.Errorf("ListTrash() filter = %+v, want acme, user:bob and group:legal, limit 20 offset 40", got)
+	}
+}
+
+func TestGrantRequiresOwner(t *testing.T) {
+	aclRepo := &mock_test.MockDocumentACLRepository{}
+	aclRepo.FindPermissionsFunc = func(ctx context.Context, documentID string, subjects []string) ([]string, error) {
+		return []string{entity.ACLPermissionWrite}, nil
+	}
+	saved := 0
+	aclRepo.SaveFunc = func(ctx context.Context, entry *entity.ACLEntry) error {
+		saved++
+		return nil
+	}
+
+	docs := usecase.NewDocumentUsecase(ownedDocumentRepo("user:alice"), &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithACL(aclRepo))
+	uc := usecase.NewACLUsecase(aclRepo, docs)
+
+	if _, err := uc.Grant(withPrincipal("user:bob"), "doc-1", "user:carol", "read"); !errors.Is(err, entity.ErrForbidden) {
+		t.Errorf("Grant() by non-owner error = %v, want %v", err, entity.ErrForbidden)
+	}
+
+	entry, err := uc.Grant(withPrincipal("user:alice"), "doc-1", "group:legal", "WRITE")
+	if err != nil {
+		t.Fatalf("Grant() by owner error = %v, want nil", err)
+	}
+	if entry.Permission != entity.ACLPermissionWrite || entry.CreatedBy != "user:alice" || saved != 1 {
+		t.Errorf("Grant() entry = %+v, saved = %d, want write entry by user:alice", entry, saved)
+	}
+
+	for _, tt := range []struct{ subject, permission string }{
+		{"carol", "read"},
+		{"user:carol", "admin"},
+	} {
+		if _, err := uc.Grant(withPrincipal("user:alice"), "doc-1", tt.subject, tt.permission); !errors.Is(err, entity.ErrInvalidInput) {
+			t.Errorf("Grant(%q, %q) error = %v, want %v", tt.subject, tt.permission, err, entity.ErrInvalidInput)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func storedKeyRepo() (*mock_test.MockAPIKeyRepository, *[]*entity.APIKey) {
+	repo := &mock_test.MockAPIKeyRepository{}
+	keys := &[]*entity.APIKey{}
+	repo.SaveFunc = func(ctx context.Context, key *entity.APIKey) error {
+		*keys = append(*keys, key)
+		return nil
+	}
+	repo.FindByPrefixFunc = func(ctx context.Context, prefix string) (*entity.APIKey, error) {
+		for _, key := range *keys {
+			if key.Prefix == prefix {
+				return key, nil
+			}
+		}
+		return nil, entity.ErrAPIKeyNotFound
+	}
+
+	return repo, keys
+}
+
+func TestCreateAPIKeyStoresOnlyHash(t *testing.T) {
+	repo, keys := storedKeyRepo()
+	uc := usecase.NewAPIKeyUsecase(repo)
+
+	key, secret, err := uc.Create(context.Background(), "ci", []string{"documents:write", "documents:read"}, time.Hour, "")
+	if err != nil {
+		t.Fatalf("Create() error = %v, want nil", err)
+	}
+
+	if !strings.HasPrefix(secret, "dv_"+key.Prefix+"_") {
+		t.Errorf("Create() secret = %s, want dv_%s_ prefix", secret, key.Prefix)
+	}
+	if (*keys)[0].SecretHash == strings.TrimPrefix(secret, "dv_"+key.Prefix+"_") {
+		t.Errorf("Create() stored the secret in clear")
+	}
+	if strings.Join(key.Permissions, ",") != "documents:read,documents:write" {
+		t.Errorf("Create() Permissions = %v, want sorted read,write", key.Permissions)
+	}
+
+	principal, err := uc.Authenticate(context.Background(), secret)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v, want nil", err)
+	}
+	if principal.ID != "api_key:"+key.ID || !principal.Can(entity.PermissionDocumentsWrite) || principal.Can(entity.PermissionDocumentsDelete) {
+		t.Errorf("Authenticate() principal = %+v, want key principal with read/write", principal)
+	}
+}
+
+func TestAuthenticateRejectsRevokedAndWrongSecret(t *testing.T) {
+	repo, keys := storedKeyRepo()
+	uc := usecase.NewAPIKeyUsecase(repo)
+
+	_, secret, err := uc.Create(context.Background(), "ci", []string{"admin"}, 0, "")
+	if err != nil {
+		t.Fatalf("Create() error = %v, want nil", err)
+	}
+
+	tampered := secret[:len(secret)-1] + "x"
+	if _, err := uc.Authenticate(context.Background(), tampered); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("Authenticate() wrong secret error = %v, want %v", err, entity.ErrUnauthorized)
+	}
+
+	revokedAt := time.Now()
+	(*keys)[0].RevokedAt = &revokedAt
+	if _, err := uc.Authenticate(context.Background(), secret); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("Authenticate() revoked error = %v, want %v", err, entity.ErrUnauthorized)
+	}
+}
+
+func TestAuthenticateIgnoresForeignCredentials(t *testing.T) {
+	uc := usecase.NewAPIKeyUsecase(&mock_test.MockAPIKeyRepository{})
+
+	principal, err := uc.Authenticate(context.Background(), "eyJhbGciOiJIUzI1NiJ9.e30.sig")
+	if principal != nil || err != nil {
+		t.Errorf("Authenticate() = %v, %v, want nil, nil", principal, err)
+	}
+}
+
+func TestCreateAPIKeyRejectsUnknownPermission(t *testing.T) {
+	uc := usecase.NewAPIKeyUsecase(&mock_test.MockAPIKeyRepository{})
+
+	_, _, err := uc.Create(context.Background(), "ci", []string{"documents:everything"}, 0, "")
+	if !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Create() error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+}
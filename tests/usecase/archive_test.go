@@ -0,0 +1,194 @@
+package usecase_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"docvault/entity"
+	"docvault/service"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func archiveDocumentRepo(docs ...*entity.Document) *mock_test.MockDocumentRepository {
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		for _, doc := range docs {
+			if doc.ID == id {
+				return doc, nil
+			}
+		}
+		return nil, entity.ErrDocumentNotFound
+	}
+	docRepo.FindAllFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+		if filter.Limit > 0 && len(docs) > filter.Limit {
+			return docs[:filter.Limit], nil
+		}
+		return docs, nil
+	}
+
+	return docRepo
+}
+
+func readArchive(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+
+	files := map[string]string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[file.Name] = string(content)
+	}
+
+	return files
+}
+
+func TestArchiveNumbersCollidingNames(t *testing.T) {
+	docs := []*entity.Document{
+		{ID: "doc-1", FileName: "report.pdf", StorageKey: "default/doc-1", FileSize: 3, ScanStatus: entity.ScanStatusClean},
+		{ID: "doc-2", FileName: "Report.pdf", StorageKey: "default/doc-2", FileSize: 3, ScanStatus: entity.ScanStatusClean},
+		{ID: "doc-3", FileName: "../../etc/manifest.json", StorageKey: "default/doc-3", FileSize: 3, ScanStatus: entity.ScanStatusClean},
+		{ID: "doc-4", FileName: "virus.exe", StorageKey: "quarantine/default/doc-4", FileSize: 3, ScanStatus: entity.ScanStatusInfected},
+	}
+	objects := map[string][]byte{"default/doc-1": []byte("one"), "default/doc-2": []byte("two"), "default/doc-3": []byte("six")}
+
+	uc := usecase.NewDocumentUsecase(archiveDocumentRepo(docs...), memoryStorage(objects), &mock_test.MockServiceQueue{},
+		usecase.WithScanner(service.NewSignatureScanner()))
+
+	archive, err := uc.PrepareArchive(context.Background(), usecase.ArchiveInput{IDs: []string{"doc-1", "doc-2", "doc-3", "doc-4", "doc-1"}})
+	if err != nil {
+		t.Fatalf("PrepareArchive() error = %v, want nil", err)
+	}
+	if archive.TotalBytes != 9 || len(archive.Skipped) != 1 || archive.Skipped[0].Document.ID != "doc-4" {
+		t.Errorf("PrepareArchive() total = %d, skipped = %v, want 9 bytes and doc-4 skipped", archive.TotalBytes, archive.Skipped)
+	}
+
+	var buf bytes.Buffer
+	if err := uc.WriteArchive(context.Background(), archive, &buf); err != nil {
+		t.Fatalf("WriteArchive() error = %v, want nil", err)
+	}
+
+	files := readArchive(t, buf.Bytes())
+	want := map[string]string{"report.pdf": "one", "Report (1).pdf": "two", "manifest (1).json": "six"}
+	for name, content := range want {
+		if files[name] != content {
+			t.Errorf("archive %s = %q, want %q", name, files[name], content)
+		}
+	}
+	if len(files) != len(want)+1 {
+		t.Errorf("archive has %d files, want %d", len(files), len(want)+1)
+	}
+
+	var manifest struct {
+		Count     int `json:"count"`
+		Documents []struct {
+			ID   string `json:"id"`
+			Path string `json:"path"`
+		} `json:"documents"`
+		Skipped []struct {
+			ID string `json:"id"`
+		} `json:"skipped"`
+	}
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("manifest.json error = %v", err)
+	}
+	if manifest.Count != 3 || manifest.Documents[1].Path != "Report (1).pdf" || len(manifest.Skipped) != 1 || manifest.Skipped[0].ID != "doc-4" {
+		t.Errorf("manifest.json = %+v, want 3 documents and doc-4 skipped", manifest)
+	}
+}
+
+func TestArchiveRejectsOversizedSelection(t *testing.T) {
+	docs := []*entity.Document{
+		{ID: "doc-1", FileName: "a.bin", FileSize: 600},
+		{ID: "doc-2", FileName: "b.bin", FileSize: 600},
+	}
+	storage := &mock_test.MockServiceStorage{}
+	storage.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		t.Errorf("PrepareArchive() downloaded %s", filename)
+		return nil, errors.New("unexpected download")
+	}
+
+	uc := usecase.NewDocumentUsecase(archiveDocumentRepo(docs...), storage, &mock_test.MockServiceQueue{}, usecase.WithArchiveLimits(1000, 0))
+	if _, err := uc.PrepareArchive(context.Background(), usecase.ArchiveInput{IDs: []string{"doc-1", "doc-2"}}); !errors.Is(err, entity.ErrArchiveTooLarge) {
+		t.Errorf("PrepareArchive() over bytes error = %v, want %v", err, entity.ErrArchiveTooLarge)
+	}
+
+	docRepo := archiveDocumentRepo(append(docs, &entity.Document{ID: "doc-3", FileName: "c.bin"})...)
+	findAll := docRepo.FindAllFunc
+	docRepo.FindAllFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+		if filter.Limit != 2 {
+			t.Errorf("PrepareArchive() listed with limit %d, want 2, one past the count limit", filter.Limit)
+		}
+		return findAll(ctx, filter)
+	}
+	uc = usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{}, usecase.WithArchiveLimits(0, 1))
+	if _, err := uc.PrepareArchive(context.Background(), usecase.ArchiveInput{IDs: []string{"doc-1", "doc-2"}}); !errors.Is(err, entity.ErrArchiveTooLarge) {
+		t.Errorf("PrepareArchive() over count error = %v, want %v", err, entity.ErrArchiveTooLarge)
+	}
+	if _, err := uc.PrepareArchive(context.Background(), usecase.ArchiveInput{Filter: entity.DocumentFilter{Tags: []string{"old"}}}); !errors.Is(err, entity.ErrArchiveTooLarge) {
+		t.Errorf("PrepareArchive() by filter over count error = %v, want %v", err, entity.ErrArchiveTooLarge)
+	}
+
+	for _, input := range []usecase.ArchiveInput{
+		{},
+		{IDs: []string{"doc-1"}, Filter: entity.DocumentFilter{Tags: []string{"x"}}},
+	} {
+		if _, err := uc.PrepareArchive(context.Background(), input); !errors.Is(err, entity.ErrInvalidInput) {
+			t.Errorf("PrepareArchive(%+v) error = %v, want %v", input, err, entity.ErrInvalidInput)
+		}
+	}
+}
+
+func TestArchiveMirrorsSubfolders(t *testing.T) {
+	root, child := "folder-root", "folder-child"
+	folders := map[string]*entity.Folder{
+		root:  {ID: root, Name: "Contracts", Path: "/" + root + "/"},
+		child: {ID: child, Name: "2024", ParentID: &root, Path: "/" + root + "/" + child + "/"},
+	}
+	folderRepo := &mock_test.MockFolderRepository{}
+	folderRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Folder, error) {
+		return folders[id], nil
+	}
+
+	docs := []*entity.Document{
+		{ID: "doc-1", FileName: "a.txt", FolderID: &root, StorageKey: "default/doc-1"},
+		{ID: "doc-2", FileName: "a.txt", FolderID: &child, StorageKey: "default/doc-2"},
+	}
+	docRepo := archiveDocumentRepo(docs...)
+	var filter entity.DocumentFilter
+	docRepo.FindAllFunc = func(ctx context.Context, f entity.DocumentFilter) ([]*entity.Document, error) {
+		filter = f
+		return docs, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithFolders(folderRepo))
+	archive, err := uc.PrepareArchive(context.Background(), usecase.ArchiveInput{Filter: entity.DocumentFilter{FolderID: &root}, Recursive: true})
+	if err != nil {
+		t.Fatalf("PrepareArchive() error = %v, want nil", err)
+	}
+
+	if filter.FolderID != nil || filter.FolderPath != "/"+root+"/" {
+		t.Errorf("PrepareArchive() filter = %+v, want the subtree of %s", filter, root)
+	}
+	var paths []string
+	for _, entry := range archive.Entries {
+		paths = append(paths, entry.Path)
+	}
+	if !slices.Equal(paths, []string{"a.txt", "2024/a.txt"}) {
+		t.Errorf("PrepareArchive() paths = %v, want a.txt and 2024/a.txt", paths)
+	}
+}
@@ -0,0 +1,121 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"testing"
+	"time"
+)
+
+// memoryAuditRepo backs the audit mock with a slice so chains can be verified.
+func memoryAuditRepo(entries *[]*entity.AuditEntry) *mock_test.MockAuditRepository {
+	repo := &mock_test.MockAuditRepository{}
+	repo.AppendFunc = func(ctx context.Context, entry *entity.AuditEntry) error {
+		entry.Seq = int64(len(*entries) + 1)
+		*entries = append(*entries, entry)
+		return nil
+	}
+	repo.LastFunc = func(ctx context.Context) (*entity.AuditEntry, error) {
+		if len(*entries) == 0 {
+			return nil, nil
+		}
+		return (*entries)[len(*entries)-1], nil
+	}
+	repo.FindAfterFunc = func(ctx context.Context, afterSeq int64, limit int) ([]*entity.AuditEntry, error) {
+		var result []*entity.AuditEntry
+		for _, entry := range *entries {
+			if entry.Seq > afterSeq && len(result) < limit {
+				result = append(result, entry)
+			}
+		}
+		return result, nil
+	}
+
+	return repo
+}
+
+func TestAuditRecordsChainAndVerifies(t *testing.T) {
+	var entries []*entity.AuditEntry
+	uc := usecase.NewAuditUsecase(memoryAuditRepo(&entries))
+
+	ctx := context.Background()
+	uc.Record(ctx, entity.AuditActionUpload, "doc-1", "a.pdf", nil)
+	uc.Record(ctx, entity.AuditActionDownload, "doc-1", "", nil)
+
+	if len(entries) != 2 {
+		t.Fatalf("Record() wrote %d entries, want 2", len(entries))
+	}
+	if entries[1].PrevHash != entries[0].Hash {
+		t.Errorf("Record() PrevHash = %s, want %s", entries[1].PrevHash, entries[0].Hash)
+	}
+	if entries[0].Actor != "system" {
+		t.Errorf("Record() Actor = %s, want system", entries[0].Actor)
+	}
+
+	result, err := uc.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v, want nil", err)
+	}
+	if !result.Valid || result.Checked != 2 {
+		t.Errorf("Verify() = %+v, want valid with 2 entries checked", result)
+	}
+}
+
+func TestAuditVerifyDetectsTampering(t *testing.T) {
+	var entries []*entity.AuditEntry
+	uc := usecase.NewAuditUsecase(memoryAuditRepo(&entries))
+
+	ctx := context.Background()
+	uc.Record(ctx, entity.AuditActionUpload, "doc-1", "a.pdf", nil)
+	uc.Record(ctx, entity.AuditActionDelete, "doc-1", "", nil)
+	uc.Record(ctx, entity.AuditActionRestore, "doc-1", "", nil)
+
+	entries[1].Action = entity.AuditActionRead
+
+	result, err := uc.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v, want nil", err)
+	}
+	if result.Valid || result.BrokenAt == nil || *result.BrokenAt != 2 {
+		t.Errorf("Verify() = %+v, want broken at entry 2", result)
+	}
+}
+
+func TestDeleteAuditsFailureWithRequestInfo(t *testing.T) {
+	var entries []*entity.AuditEntry
+	audit := usecase.NewAuditUsecase(memoryAuditRepo(&entries))
+
+	docRepo := &mock_test.MockDocumentRepository{}
+	holdRepo := &mock_test.MockLegalHoldRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, CreatedAt: time.Now()}, nil
+	}
+	holdRepo.CountActiveFunc = func(ctx context.Context, documentID string) (int64, error) {
+		return 1, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{},
+		usecase.WithLegalHolds(holdRepo), usecase.WithAudit(audit))
+
+	ctx := entity.WithRequestInfo(context.Background(), entity.RequestInfo{
+		RequestID: "req-1",
+		ClientIP:  "10.0.0.1",
+		UserAgent: "curl/8",
+	})
+	if err := uc.Delete(ctx, "doc-1"); err == nil {
+		t.Fatalf("Delete() error = nil, want legal hold error")
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("Delete() wrote %d audit entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Action != entity.AuditActionDelete || entry.Outcome != entity.AuditOutcomeFailure {
+		t.Errorf("Delete() audit = %s/%s, want delete/failure", entry.Action, entry.Outcome)
+	}
+	if entry.RequestID != "req-1" || entry.ClientIP != "10.0.0.1" || entry.UserAgent != "curl/8" || entry.Actor != "anonymous" {
+		t.Errorf("Delete() audit = %+v, want request info copied", entry)
+	}
+}
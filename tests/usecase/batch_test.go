@@ -0,0 +1,126 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBatchTagsKeepsGoingAfterFailures(t *testing.T) {
+	docRepo := archiveDocumentRepo(
+		&entity.Document{ID: "doc-1", Tags: []string{"draft", "legal"}, Version: 3},
+		&entity.Document{ID: "doc-2", Tags: []string{"final"}, Version: 1},
+	)
+	var updated []string
+	docRepo.UpdateFunc = func(ctx context.Context, doc *entity.Document, expectedVersion int64) error {
+		updated = append(updated, doc.ID)
+		if doc.ID == "doc-1" && (expectedVersion != 3 || !slices.Equal(doc.Tags, []string{"final", "legal"})) {
+			t.Errorf("Update(doc-1) tags = %v, version = %d, want [final legal] at version 3", doc.Tags, expectedVersion)
+		}
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+	report, err := uc.Batch(context.Background(), usecase.BatchInput{
+		Action:     usecase.BatchActionTags,
+		IDs:        []string{"doc-1", "missing", "doc-2"},
+		AddTags:    []string{"Final"},
+		RemoveTags: []string{"draft"},
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v, want nil", err)
+	}
+
+	if report.Changed != 1 || report.Failed != 1 || report.Unchanged != 1 {
+		t.Errorf("Batch() changed/failed/unchanged = %d/%d/%d, want 1/1/1", report.Changed, report.Failed, report.Unchanged)
+	}
+	if !errors.Is(report.Results[1].Err, entity.ErrDocumentNotFound) {
+		t.Errorf("Batch() missing document error = %v, want %v", report.Results[1].Err, entity.ErrDocumentNotFound)
+	}
+	if !slices.Equal(updated, []string{"doc-1"}) {
+		t.Errorf("Batch() updated %v, want only doc-1", updated)
+	}
+}
+
+func TestBatchDeleteDryRunChangesNothing(t *testing.T) {
+	docRepo := archiveDocumentRepo(&entity.Document{ID: "doc-1"}, &entity.Document{ID: "doc-2"})
+	docRepo.TrashFunc = func(ctx context.Context, id string, deletedAt time.Time) error {
+		t.Errorf("Batch() dry run trashed %s", id)
+		return nil
+	}
+	holdRepo := &mock_test.MockLegalHoldRepository{}
+	holdRepo.CountActiveFunc = func(ctx context.Context, documentID string) (int64, error) {
+		if documentID == "doc-2" {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithLegalHolds(holdRepo))
+	report, err := uc.Batch(context.Background(), usecase.BatchInput{
+		Action: usecase.BatchActionDelete,
+		Filter: entity.DocumentFilter{Tags: []string{"old"}},
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v, want nil", err)
+	}
+
+	if !report.DryRun || report.Changed != 1 || report.Results[0].Status != usecase.BatchResultChanged {
+		t.Errorf("Batch() report = %+v, want doc-1 reported as deleted", report)
+	}
+	if !errors.Is(report.Results[1].Err, entity.ErrDocumentOnHold) {
+		t.Errorf("Batch() held document error = %v, want %v", report.Results[1].Err, entity.ErrDocumentOnHold)
+	}
+}
+
+func TestBatchValidatesAction(t *testing.T) {
+	uc := usecase.NewDocumentUsecase(archiveDocumentRepo(), &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+	past := time.Now().Add(-time.Hour)
+	tooMany := make([]string, 1001)
+	for i := range tooMany {
+		tooMany[i] = "doc-1"
+	}
+
+	for _, input := range []usecase.BatchInput{
+		{Action: "rename", IDs: []string{"doc-1"}},
+		{Action: usecase.BatchActionMove, IDs: []string{"doc-1"}},
+		{Action: usecase.BatchActionExpiry, IDs: []string{"doc-1"}},
+		{Action: usecase.BatchActionExpiry, IDs: []string{"doc-1"}, ExpiresAt: &past, SetExpiresAt: true},
+		{Action: usecase.BatchActionTags, IDs: []string{"doc-1"}},
+		{Action: usecase.BatchActionDelete},
+		{Action: usecase.BatchActionDelete, IDs: []string{"doc-1"}, Filter: entity.DocumentFilter{Tags: []string{"old"}}},
+		{Action: usecase.BatchActionDelete, IDs: tooMany, DryRun: true},
+	} {
+		if _, err := uc.Batch(context.Background(), input); !errors.Is(err, entity.ErrInvalidInput) {
+			t.Errorf("Batch(%s, %d ids) error = %v, want %v", input.Action, len(input.IDs), err, entity.ErrInvalidInput)
+		}
+	}
+}
+
+func TestBatchStopsListingPastTheLimit(t *testing.T) {
+	var docs []*entity.Document
+	for i := range 1500 {
+		docs = append(docs, &entity.Document{ID: fmt.Sprintf("doc-%d", i)})
+	}
+	docRepo := archiveDocumentRepo(docs...)
+	findAll := docRepo.FindAllFunc
+	docRepo.FindAllFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+		if filter.Limit != 1001 {
+			t.Errorf("Batch() listed with limit %d, want 1001, one past the batch limit", filter.Limit)
+		}
+		return findAll(ctx, filter)
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+	_, err := uc.Batch(context.Background(), usecase.BatchInput{Action: usecase.BatchActionDelete, Filter: entity.DocumentFilter{Tags: []string{"old"}}, DryRun: true})
+	if !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Batch() over the limit error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+}
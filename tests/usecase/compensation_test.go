@@ -0,0 +1,146 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/service"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUploadCompensatesFailedSave(t *testing.T) {
+	storageErr := errors.New("storage failed")
+	saveErr := errors.New("database is locked")
+
+	for _, tc := range []struct {
+		name      string
+		uploadErr error
+		saveErr   error
+		deleteErr error
+		content   string
+		scanner   service.Scanner
+		wantErr   error
+		// wantRemoved lists the key prefixes removed, in order.
+		wantRemoved []string
+	}{
+		{name: "upload fails", uploadErr: storageErr, wantErr: storageErr},
+		{name: "save fails", saveErr: saveErr, wantErr: saveErr, wantRemoved: []string{"default/"}},
+		{name: "quota exceeded", saveErr: entity.ErrQuotaExceeded, wantErr: entity.ErrQuotaExceeded, wantRemoved: []string{"default/"}},
+		{name: "save and removal fail", saveErr: saveErr, deleteErr: storageErr, wantErr: saveErr, wantRemoved: []string{"default/"}},
+		{name: "quarantined save fails", saveErr: saveErr, content: eicar, scanner: service.NewSignatureScanner(), wantErr: saveErr,
+			wantRemoved: []string{"quarantine/default/", "default/"}},
+		{name: "save succeeds"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := map[string][]byte{}
+			storage := memoryStorage(objects)
+			upload := storage.UploadFunc
+			storage.UploadFunc = func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+				if tc.uploadErr != nil {
+					return tc.uploadErr
+				}
+				return upload(ctx, filename, fileSize, contentType, file)
+			}
+			var removed []string
+			storage.DeleteFunc = func(ctx context.Context, filename string) error {
+				if ctx.Err() != nil {
+					t.Errorf("Delete(%s) ran with a canceled context", filename)
+				}
+				removed = append(removed, filename)
+				return tc.deleteErr
+			}
+
+			docRepo := &mock_test.MockDocumentRepository{}
+			ctx, cancel := context.WithCancel(context.Background())
+			docRepo.SaveFunc = func(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error {
+				if tc.saveErr != nil {
+					// The request is gone by the time the save fails.
+					cancel()
+				}
+				return tc.saveErr
+			}
+			var opts []usecase.DocumentOption
+			if tc.scanner != nil {
+				opts = append(opts, usecase.WithScanner(tc.scanner))
+			}
+
+			content := tc.content
+			if content == "" {
+				content = "hello"
+			}
+			uc := usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{}, opts...)
+			_, err := uc.Upload(ctx, usecase.UploadInput{FileName: "a.txt", FileSize: int64(len(content)), File: strings.NewReader(content)})
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Upload() error = %v, want %v", err, tc.wantErr)
+			}
+			if len(removed) != len(tc.wantRemoved) {
+				t.Fatalf("Upload() removed %v, want keys starting with %v", removed, tc.wantRemoved)
+			}
+			for i, prefix := range tc.wantRemoved {
+				if !strings.HasPrefix(removed[i], prefix) {
+					t.Errorf("Upload() removed %v, want keys starting with %v", removed, tc.wantRemoved)
+				}
+			}
+		})
+	}
+}
+
+func TestPurgeCompensatesFailedRowDelete(t *testing.T) {
+	storageErr := errors.New("storage failed")
+	deleteErr := errors.New("database is locked")
+
+	for _, tc := range []struct {
+		name       string
+		storageErr error
+		deleteErr  error
+		markErr    error
+		wantErr    error
+		wantSteps  []string
+	}{
+		{name: "object delete fails", storageErr: storageErr, wantErr: storageErr, wantSteps: []string{"object"}},
+		{name: "row delete fails", deleteErr: deleteErr, wantErr: deleteErr, wantSteps: []string{"object", "row", "mark"}},
+		{name: "row delete and mark fail", deleteErr: deleteErr, markErr: errors.New("disk full"), wantErr: deleteErr,
+			wantSteps: []string{"object", "row", "mark"}},
+		{name: "purge succeeds", wantSteps: []string{"object", "row"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deletedAt := time.Now().Add(-time.Hour)
+			docRepo := archiveDocumentRepo(&entity.Document{ID: "doc-1", TenantID: entity.DefaultTenantID, StorageKey: "default/doc-1", DeletedAt: &deletedAt})
+
+			var steps []string
+			storage := &mock_test.MockServiceStorage{}
+			storage.DeleteFunc = func(ctx context.Context, filename string) error {
+				steps = append(steps, "object")
+				return tc.storageErr
+			}
+			docRepo.DeleteFunc = func(ctx context.Context, id string) error {
+				steps = append(steps, "row")
+				return tc.deleteErr
+			}
+			docRepo.UpdateMissingFunc = func(ctx context.Context, id string, missingAt *time.Time) error {
+				if missingAt == nil {
+					t.Errorf("UpdateMissing(%s) cleared the mark, want it set", id)
+				}
+				steps = append(steps, "mark")
+				return tc.markErr
+			}
+
+			uc := usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{})
+			err := uc.Purge(context.Background(), "doc-1")
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Purge() error = %v, want %v", err, tc.wantErr)
+			}
+			if !slices.Equal(steps, tc.wantSteps) {
+				t.Errorf("Purge() steps = %v, want %v", steps, tc.wantSteps)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package usecase_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func policyUsecase(policy entity.UploadPolicy) *usecase.DocumentUsecase {
+	storage := &mock_test.MockServiceStorage{}
+	storage.UploadFunc = func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+		_, err := io.Copy(io.Discard, file)
+		return err
+	}
+
+	return usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, storage, &mock_test.MockServiceQueue{}, usecase.WithUploadPolicy(policy))
+}
+
+func upload(uc *usecase.DocumentUsecase, fileName, contentType string, content []byte) (*entity.Document, error) {
+	return uc.Upload(context.Background(), usecase.UploadInput{
+		FileName:    fileName,
+		FileSize:    int64(len(content)),
+		ContentType: contentType,
+		File:        bytes.NewReader(content),
+	})
+}
+
+func TestUploadRejectsExecutableLabelledAsPDF(t *testing.T) {
+	uc := policyUsecase(entity.UploadPolicy{OnMismatch: entity.ContentMismatchReject})
+
+	_, err := upload(uc, "invoice.pdf", "application/pdf", []byte("MZ\x90\x00\x03\x00\x00\x00"))
+	if !errors.Is(err, entity.ErrUnsupportedContent) {
+		t.Errorf("Upload() error = %v, want %v", err, entity.ErrUnsupportedContent)
+	}
+}
+
+func TestUploadCorrectsMismatchedType(t *testing.T) {
+	uc := policyUsecase(entity.UploadPolicy{OnMismatch: entity.ContentMismatchCorrect})
+
+	doc, err := upload(uc, "photo.jpg", "image/jpeg", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+
+	if doc.ContentType != "image/png" || doc.DeclaredContentType != "image/jpeg" || doc.DetectedContentType != "image/png" {
+		t.Errorf("Upload() types = %s declared %s detected %s, want image/png declared image/jpeg detected image/png",
+			doc.ContentType, doc.DeclaredContentType, doc.DetectedContentType)
+	}
+}
+
+func TestUploadAcceptsZipBasedOfficeDocument(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	entry, _ := archive.Create("[Content_Types].xml")
+	entry.Write([]byte("<Types/>"))
+	archive.Close()
+
+	uc := policyUsecase(entity.UploadPolicy{OnMismatch: entity.ContentMismatchReject})
+	docx := "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+	doc, err := upload(uc, "report.docx", docx, buf.Bytes())
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+	if doc.ContentType != docx || doc.DetectedContentType != "application/zip" {
+		t.Errorf("Upload() ContentType = %s, DetectedContentType = %s, want %s and application/zip", doc.ContentType, doc.DetectedContentType, docx)
+	}
+}
+
+func TestUploadDeniesDetectedTypeUnderTextLabel(t *testing.T) {
+	uc := policyUsecase(entity.UploadPolicy{OnMismatch: entity.ContentMismatchReject, DeniedTypes: []string{"text/x-shellscript"}})
+
+	_, err := upload(uc, "notes.txt", "text/plain", []byte("#!/bin/sh\nrm -rf /\n"))
+	if !errors.Is(err, entity.ErrUnsupportedContent) {
+		t.Errorf("Upload() error = %v, want %v", err, entity.ErrUnsupportedContent)
+	}
+}
+
+func TestUploadEnforcesAllowListsAndSizeLimits(t *testing.T) {
+	uc := policyUsecase(entity.UploadPolicy{
+		AllowedTypes:     []string{"text/*", "image/*"},
+		DeniedExtensions: []string{".bat"},
+		SizeLimits:       map[string]int64{"image/*": 16, "*/*": 1024},
+	})
+
+	cases := []struct {
+		name, fileName, contentType string
+		content                     []byte
+		want                        error
+	}{
+		{"allowed text", "a.txt", "text/plain", []byte("hello"), nil},
+		{"denied extension", "run.bat", "text/plain", []byte("echo"), entity.ErrUnsupportedContent},
+		{"type not allowed", "doc.pdf", "application/pdf", []byte("%PDF-1.7\n"), entity.ErrUnsupportedContent},
+		{"image over its limit", "a.gif", "image/gif", []byte("GIF89a" + strings.Repeat("x", 20)), entity.ErrFileTooLarge},
+		{"text within default limit", "b.txt", "text/plain", []byte(strings.Repeat("x", 20)), nil},
+	}
+
+	for _, tc := range cases {
+		if _, err := upload(uc, tc.fileName, tc.contentType, tc.content); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Upload() error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestUpdateCannotRelabelAroundPolicy(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, TenantID: entity.DefaultTenantID, FileName: "tool.bin", ContentType: "application/octet-stream",
+			DetectedContentType: "application/vnd.microsoft.portable-executable"}, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{},
+		usecase.WithUploadPolicy(entity.UploadPolicy{OnMismatch: entity.ContentMismatchReject}))
+
+	contentType := "application/pdf"
+	if _, err := uc.Update(context.Background(), "doc-1", usecase.UpdateInput{ContentType: &contentType}); !errors.Is(err, entity.ErrUnsupportedContent) {
+		t.Errorf("Update() error = %v, want %v", err, entity.ErrUnsupportedContent)
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"docvault/entity"
+	"docvault/service"
 	mock_test "docvault/tests/mock"
 	"docvault/usecase"
 	"errors"
 	"io"
+	"strings"
 	"testing"
+	"time"
 )
 
 const TestPDF = "test.pdf"
@@ -18,7 +21,7 @@ func TestUploadSuccess(t *testing.T) {
 	mockStorage := &mock_test.MockServiceStorage{}
 	mockQueue := &mock_test.MockServiceQueue{}
 
-	mockRepo.SaveFunc = func(ctx context.Context, doc *entity.Document) error {
+	mockRepo.SaveFunc = func(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error {
 		return nil
 	}
 
@@ -32,7 +35,13 @@ func TestUploadSuccess(t *testing.T) {
 
 	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
 
-	doc, err := uc.Upload(context.Background(), TestPDF, 100, "application/pdf", bytes.NewReader([]byte("test content")), 60)
+	doc, err := uc.Upload(context.Background(), usecase.UploadInput{
+		FileName:    TestPDF,
+		FileSize:    100,
+		ContentType: "application/pdf",
+		File:        bytes.NewReader([]byte("test content")),
+		ExpiresIn:   60,
+	})
 	if err != nil {
 		t.Errorf("Upload() error = %v, want nil", err)
 	}
@@ -60,7 +69,13 @@ func TestUploadStorageFailure(t *testing.T) {
 	}
 
 	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
-	doc, err := uc.Upload(context.Background(), TestPDF, 100, "application/pdf", bytes.NewReader([]byte("test")), 60)
+	doc, err := uc.Upload(context.Background(), usecase.UploadInput{
+		FileName:    TestPDF,
+		FileSize:    100,
+		ContentType: "application/pdf",
+		File:        bytes.NewReader([]byte("test")),
+		ExpiresIn:   60,
+	})
 
 	if err == nil {
 		t.Errorf("Upload() error = nil, want non-nil")
@@ -70,6 +85,62 @@ func TestUploadStorageFailure(t *testing.T) {
 	}
 }
 
+func TestUploadNormalizesTagsAndMetadata(t *testing.T) {
+	mockRepo := &mock_test.MockDocumentRepository{}
+	mockStorage := &mock_test.MockServiceStorage{}
+	mockQueue := &mock_test.MockServiceQueue{}
+
+	var saved *entity.Document
+	mockRepo.SaveFunc = func(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error {
+		saved = doc
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
+
+	_, err := uc.Upload(context.Background(), usecase.UploadInput{
+		FileName:    TestPDF,
+		FileSize:    100,
+		ContentType: "application/pdf",
+		File:        bytes.NewReader([]byte("test")),
+		Tags:        []string{" Invoice", "invoice", "2024"},
+		Metadata:    map[string]string{"customer": "acme"},
+	})
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+
+	if len(saved.Tags) != 2 || saved.Tags[0] != "2024" || saved.Tags[1] != "invoice" {
+		t.Errorf("Upload() Tags = %v, want [2024 invoice]", saved.Tags)
+	}
+	if saved.Metadata["customer"] != "acme" {
+		t.Errorf("Upload() Metadata = %v, want customer=acme", saved.Metadata)
+	}
+}
+
+func TestUploadInvalidMetadataKey(t *testing.T) {
+	mockRepo := &mock_test.MockDocumentRepository{}
+	mockStorage := &mock_test.MockServiceStorage{}
+	mockQueue := &mock_test.MockServiceQueue{}
+
+	mockStorage.UploadFunc = func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+		t.Errorf("Upload() stored object for invalid metadata")
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
+
+	_, err := uc.Upload(context.Background(), usecase.UploadInput{
+		FileName: TestPDF,
+		FileSize: 100,
+		File:     bytes.NewReader([]byte("test")),
+		Metadata: map[string]string{"bad key!": "x"},
+	})
+	if !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Upload() error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+}
+
 func TestDeleteSuccess(t *testing.T) {
 	mockRepo := &mock_test.MockDocumentRepository{}
 	mockStorage := &mock_test.MockServiceStorage{}
@@ -105,6 +176,178 @@ func TestDeleteSuccess(t *testing.T) {
 	}
 }
 
+func TestDeleteMovesDocumentToTrash(t *testing.T) {
+	mockRepo := &mock_test.MockDocumentRepository{}
+	mockStorage := &mock_test.MockServiceStorage{}
+	mockQueue := &mock_test.MockServiceQueue{}
+
+	mockRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: TestPDF}, nil
+	}
+
+	trashed := false
+	mockRepo.TrashFunc = func(ctx context.Context, id string, deletedAt time.Time) error {
+		trashed = true
+		return nil
+	}
+
+	mockStorage.DeleteFunc = func(ctx context.Context, filename string) error {
+		t.Errorf("Delete() removed object %s from storage, want it kept until purge", filename)
+		return nil
+	}
+
+	var published string
+	mockQueue.PublishFunc = func(ctx context.Context, message string) error {
+		published = message
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
+
+	if err := uc.Delete(context.Background(), "1"); err != nil {
+		t.Fatalf("Delete() error = %v, want nil", err)
+	}
+
+	if !trashed {
+		t.Errorf("Delete() did not move document to trash")
+	}
+
+	if !strings.Contains(published, "file.trashed") {
+		t.Errorf("Delete() published %s, want file.trashed event", published)
+	}
+}
+
+func TestRestoreDocumentNotInTrash(t *testing.T) {
+	mockRepo := &mock_test.MockDocumentRepository{}
+	mockStorage := &mock_test.MockServiceStorage{}
+	mockQueue := &mock_test.MockServiceQueue{}
+
+	mockRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: TestPDF}, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
+
+	_, err := uc.Restore(context.Background(), "1")
+	if !errors.Is(err, entity.ErrDocumentNotTrashed) {
+		t.Errorf("Restore() error = %v, want %v", err, entity.ErrDocumentNotTrashed)
+	}
+}
+
+func TestPurgeTrashedDocument(t *testing.T) {
+	mockRepo := &mock_test.MockDocumentRepository{}
+	mockStorage := &mock_test.MockServiceStorage{}
+	mockQueue := &mock_test.MockServiceQueue{}
+
+	deletedAt := time.Now().Add(-time.Hour)
+	mockRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: TestPDF, StorageKey: TestPDF, DeletedAt: &deletedAt}, nil
+	}
+
+	var removedObject, removedRow string
+	mockStorage.DeleteFunc = func(ctx context.Context, filename string) error {
+		removedObject = filename
+		return nil
+	}
+	mockRepo.DeleteFunc = func(ctx context.Context, id string) error {
+		removedRow = id
+		return nil
+	}
+
+	var published string
+	mockQueue.PublishFunc = func(ctx context.Context, message string) error {
+		published = message
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
+
+	if err := uc.Purge(context.Background(), "1"); err != nil {
+		t.Fatalf("Purge() error = %v, want nil", err)
+	}
+
+	if removedObject != TestPDF {
+		t.Errorf("Purge() removed object %s, want %s", removedObject, TestPDF)
+	}
+	if removedRow != "1" {
+		t.Errorf("Purge() removed row %s, want 1", removedRow)
+	}
+	if !strings.Contains(published, "file.purged") {
+		t.Errorf("Purge() published %s, want file.purged event", published)
+	}
+}
+
+func TestUpdateClearsExpiryAndPublishesEvent(t *testing.T) {
+	mockRepo := &mock_test.MockDocumentRepository{}
+	mockStorage := &mock_test.MockServiceStorage{}
+	mockQueue := &mock_test.MockServiceQueue{}
+
+	expiresAt := time.Now().Add(time.Hour)
+	mockRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: TestPDF, ExpiresAt: &expiresAt, Version: 3}, nil
+	}
+
+	var expectedVersion int64
+	mockRepo.UpdateFunc = func(ctx context.Context, doc *entity.Document, version int64) error {
+		expectedVersion = version
+		doc.Version = version + 1
+		return nil
+	}
+
+	var published string
+	mockQueue.PublishFunc = func(ctx context.Context, message string) error {
+		published = message
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
+
+	newName := "renamed.pdf"
+	doc, err := uc.Update(context.Background(), "1", usecase.UpdateInput{
+		FileName:     &newName,
+		SetExpiresAt: true,
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v, want nil", err)
+	}
+
+	if doc.ExpiresAt != nil {
+		t.Errorf("Update() ExpiresAt = %v, want nil", doc.ExpiresAt)
+	}
+	if doc.FileName != newName {
+		t.Errorf("Update() FileName = %s, want %s", doc.FileName, newName)
+	}
+	if expectedVersion != 3 {
+		t.Errorf("Update() expected version = %d, want 3", expectedVersion)
+	}
+	if !strings.Contains(published, "file.updated") || !strings.Contains(published, "expires_at") {
+		t.Errorf("Update() published %s, want file.updated event listing expires_at", published)
+	}
+}
+
+func TestUpdateVersionMismatch(t *testing.T) {
+	mockRepo := &mock_test.MockDocumentRepository{}
+	mockStorage := &mock_test.MockServiceStorage{}
+	mockQueue := &mock_test.MockServiceQueue{}
+
+	mockRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: TestPDF, Version: 4}, nil
+	}
+	mockRepo.UpdateFunc = func(ctx context.Context, doc *entity.Document, version int64) error {
+		t.Errorf("Update() wrote a stale document")
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(mockRepo, mockStorage, mockQueue)
+
+	staleVersion := int64(3)
+	newName := "renamed.pdf"
+	_, err := uc.Update(context.Background(), "1", usecase.UpdateInput{FileName: &newName, ExpectedVersion: &staleVersion})
+	if !errors.Is(err, entity.ErrVersionConflict) {
+		t.Errorf("Update() error = %v, want %v", err, entity.ErrVersionConflict)
+	}
+}
+
 func TestHealthAllServicesHealthy(t *testing.T) {
 	mockRepo := &mock_test.MockDocumentRepository{}
 	mockStorage := &mock_test.MockServiceStorage{}
@@ -133,3 +376,33 @@ func TestHealthAllServicesHealthy(t *testing.T) {
 		t.Errorf("queue status = %s, want ok", status["queue"])
 	}
 }
+
+func TestHealthReportsEachReplica(t *testing.T) {
+	primary := &mock_test.MockServiceStorage{}
+	primary.HealthFunc = func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}
+	secondary := &mock_test.MockServiceStorage{}
+	secondary.UploadFunc = func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+		return errors.New("disk full")
+	}
+
+	replicated, err := service.NewReplicatedStorage(primary, secondary, service.ReplicationSync)
+	if err != nil {
+		t.Fatalf("NewReplicatedStorage() error = %v, want nil", err)
+	}
+	replicated.Upload(context.Background(), "default/doc-1", 5, "text/plain", strings.NewReader("hello"))
+
+	uc := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, service.NewMigratingStorage(replicated), &mock_test.MockServiceQueue{})
+	status := uc.Health(context.Background())
+
+	if status["storage"] != "ok" {
+		t.Errorf("storage status = %s, want ok while the secondary is up", status["storage"])
+	}
+	if status["storage.primary"] != "error: connection refused" {
+		t.Errorf("primary status = %s, want error: connection refused", status["storage.primary"])
+	}
+	if status["storage.secondary"] != "ok (1 repairs pending)" {
+		t.Errorf("secondary status = %s, want ok (1 repairs pending)", status["storage.secondary"])
+	}
+}
@@ -0,0 +1,186 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newFolderUsecase(folderRepo *mock_test.MockFolderRepository, docRepo *mock_test.MockDocumentRepository) *usecase.FolderUsecase {
+	docs := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithFolders(folderRepo))
+	return usecase.NewFolderUsecase(folderRepo, docs)
+}
+
+func TestCreateFolderMaterializesPath(t *testing.T) {
+	folderRepo := &mock_test.MockFolderRepository{}
+	docRepo := &mock_test.MockDocumentRepository{}
+
+	folderRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Folder, error) {
+		return &entity.Folder{ID: id, Name: "parent", Path: "/root/" + id + "/"}, nil
+	}
+
+	uc := newFolderUsecase(folderRepo, docRepo)
+
+	folder, err := uc.Create(context.Background(), " invoices ", "p1")
+	if err != nil {
+		t.Fatalf("Create() error = %v, want nil", err)
+	}
+
+	if folder.Name != "invoices" {
+		t.Errorf("Create() Name = %q, want invoices", folder.Name)
+	}
+	if want := "/root/p1/" + folder.ID + "/"; folder.Path != want {
+		t.Errorf("Create() Path = %s, want %s", folder.Path, want)
+	}
+	if folder.ParentID == nil || *folder.ParentID != "p1" {
+		t.Errorf("Create() ParentID = %v, want p1", folder.ParentID)
+	}
+}
+
+func TestMoveFolderIntoDescendantRejected(t *testing.T) {
+	folderRepo := &mock_test.MockFolderRepository{}
+	docRepo := &mock_test.MockDocumentRepository{}
+
+	folders := map[string]*entity.Folder{
+		"a": {ID: "a", Name: "a", Path: "/a/"},
+		"b": {ID: "b", Name: "b", Path: "/a/b/"},
+	}
+	folderRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Folder, error) {
+		return folders[id], nil
+	}
+	folderRepo.MoveFunc = func(ctx context.Context, folder *entity.Folder, parent *entity.Folder) error {
+		t.Errorf("Update() moved folder into its own subtree")
+		return nil
+	}
+
+	uc := newFolderUsecase(folderRepo, docRepo)
+
+	parentID := "b"
+	_, err := uc.Update(context.Background(), "a", usecase.FolderUpdateInput{ParentID: &parentID})
+	if !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Update() error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+}
+
+func TestDeleteNonEmptyFolderWithoutCascade(t *testing.T) {
+	folderRepo := &mock_test.MockFolderRepository{}
+	docRepo := &mock_test.MockDocumentRepository{}
+
+	folderRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Folder, error) {
+		return &entity.Folder{ID: id, Path: "/" + id + "/"}, nil
+	}
+	folderRepo.StatsFunc = func(ctx context.Context, folder *entity.Folder) (*entity.FolderStats, error) {
+		return &entity.FolderStats{DocumentCount: 2}, nil
+	}
+	folderRepo.DeleteSubtreeFunc = func(ctx context.Context, folder *entity.Folder) error {
+		t.Errorf("Delete() removed a non-empty folder")
+		return nil
+	}
+
+	uc := newFolderUsecase(folderRepo, docRepo)
+
+	err := uc.Delete(context.Background(), "a", false)
+	if !errors.Is(err, entity.ErrFolderNotEmpty) {
+		t.Errorf("Delete() error = %v, want %v", err, entity.ErrFolderNotEmpty)
+	}
+}
+
+func TestDeleteFolderCascadeMovesDocumentsToTrash(t *testing.T) {
+	folderRepo := &mock_test.MockFolderRepository{}
+	docRepo := &mock_test.MockDocumentRepository{}
+
+	folderRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Folder, error) {
+		return &entity.Folder{ID: id, Path: "/" + id + "/"}, nil
+	}
+	folderRepo.StatsFunc = func(ctx context.Context, folder *entity.Folder) (*entity.FolderStats, error) {
+		return &entity.FolderStats{DocumentCount: 2}, nil
+	}
+
+	var listedPath string
+	docRepo.FindAllFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+		listedPath = filter.FolderPath
+		return []*entity.Document{{ID: "d1"}, {ID: "d2"}}, nil
+	}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id}, nil
+	}
+
+	var trashed []string
+	docRepo.TrashFunc = func(ctx context.Context, id string, deletedAt time.Time) error {
+		trashed = append(trashed, id)
+		return nil
+	}
+
+	deleted := false
+	folderRepo.DeleteSubtreeFunc = func(ctx context.Context, folder *entity.Folder) error {
+		deleted = true
+		return nil
+	}
+
+	uc := newFolderUsecase(folderRepo, docRepo)
+
+	if err := uc.Delete(context.Background(), "a", true); err != nil {
+		t.Fatalf("Delete() error = %v, want nil", err)
+	}
+
+	if listedPath != "/a/" {
+		t.Errorf("Delete() listed documents under %q, want /a/", listedPath)
+	}
+	if len(trashed) != 2 {
+		t.Errorf("Delete() trashed %v, want 2 documents", trashed)
+	}
+	if !deleted {
+		t.Errorf("Delete() did not remove the folder subtree")
+	}
+}
+
+func TestDeleteFolderCascadeTrashesNothingWhenADocumentIsHeld(t *testing.T) {
+	folderRepo := &mock_test.MockFolderRepository{}
+	docRepo := &mock_test.MockDocumentRepository{}
+	holdRepo := &mock_test.MockLegalHoldRepository{}
+
+	folderRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Folder, error) {
+		return &entity.Folder{ID: id, Path: "/" + id + "/"}, nil
+	}
+	folderRepo.StatsFunc = func(ctx context.Context, folder *entity.Folder) (*entity.FolderStats, error) {
+		return &entity.FolderStats{DocumentCount: 2}, nil
+	}
+	docRepo.FindAllFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+		return []*entity.Document{{ID: "d1"}, {ID: "d2"}}, nil
+	}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id}, nil
+	}
+	holdRepo.CountActiveFunc = func(ctx context.Context, documentID string) (int64, error) {
+		if documentID == "d2" {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	var trashed []string
+	docRepo.TrashFunc = func(ctx context.Context, id string, deletedAt time.Time) error {
+		trashed = append(trashed, id)
+		return nil
+	}
+	deleted := false
+	folderRepo.DeleteSubtreeFunc = func(ctx context.Context, folder *entity.Folder) error {
+		deleted = true
+		return nil
+	}
+
+	docs := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{},
+		usecase.WithFolders(folderRepo), usecase.WithLegalHolds(holdRepo))
+	uc := usecase.NewFolderUsecase(folderRepo, docs)
+
+	if err := uc.Delete(context.Background(), "a", true); !errors.Is(err, entity.ErrDocumentOnHold) {
+		t.Fatalf("Delete() error = %v, want %v", err, entity.ErrDocumentOnHold)
+	}
+	if len(trashed) != 0 || deleted {
+		t.Errorf("Delete() trashed %v, deleted folder = %v, want nothing changed", trashed, deleted)
+	}
+}
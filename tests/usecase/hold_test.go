@@ -0,0 +1,162 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeleteHeldDocumentRejected(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	holdRepo := &mock_test.MockLegalHoldRepository{}
+
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: "contract.pdf"}, nil
+	}
+	docRepo.TrashFunc = func(ctx context.Context, id string, deletedAt time.Time) error {
+		t.Errorf("Delete() trashed a document under legal hold")
+		return nil
+	}
+	holdRepo.CountActiveFunc = func(ctx context.Context, documentID string) (int64, error) {
+		return 1, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithLegalHolds(holdRepo))
+
+	err := uc.Delete(context.Background(), "doc-1")
+	if !errors.Is(err, entity.ErrDocumentOnHold) {
+		t.Errorf("Delete() error = %v, want %v", err, entity.ErrDocumentOnHold)
+	}
+}
+
+func TestPurgeHeldDocumentRejected(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	holdRepo := &mock_test.MockLegalHoldRepository{}
+	storage := &mock_test.MockServiceStorage{}
+
+	deletedAt := time.Now()
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: "contract.pdf", DeletedAt: &deletedAt}, nil
+	}
+	holdRepo.CountActiveFunc = func(ctx context.Context, documentID string) (int64, error) {
+		return 2, nil
+	}
+	storage.DeleteFunc = func(ctx context.Context, filename string) error {
+		t.Errorf("Purge() deleted the object of a document under legal hold")
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{}, usecase.WithLegalHolds(holdRepo))
+
+	err := uc.Purge(context.Background(), "doc-1")
+	if !errors.Is(err, entity.ErrDocumentOnHold) {
+		t.Errorf("Purge() error = %v, want %v", err, entity.ErrDocumentOnHold)
+	}
+}
+
+func TestReleaseHoldRecordsActor(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	holdRepo := &mock_test.MockLegalHoldRepository{}
+	queue := &mock_test.MockServiceQueue{}
+
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, FileName: "contract.pdf"}, nil
+	}
+	holdRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.LegalHold, error) {
+		return &entity.LegalHold{ID: id, DocumentID: "doc-1", Reason: "case 42", CreatedBy: "legal"}, nil
+	}
+
+	var published string
+	queue.PublishFunc = func(ctx context.Context, message string) error {
+		published = message
+		return nil
+	}
+
+	docs := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, queue, usecase.WithLegalHolds(holdRepo))
+	uc := usecase.NewLegalHoldUsecase(holdRepo, docs)
+
+	hold, err := uc.Release(context.Background(), "doc-1", "hold-1", " counsel ", "settled")
+	if err != nil {
+		t.Fatalf("Release() error = %v, want nil", err)
+	}
+
+	if hold.ReleasedBy == nil || *hold.ReleasedBy != "counsel" || hold.ReleasedAt == nil {
+		t.Errorf("Release() hold = %+v, want released by counsel", hold)
+	}
+	if published == "" {
+		t.Errorf("Release() published no event")
+	}
+}
+
+func TestReleaseHoldOfAnotherDocumentNotFound(t *testing.T) {
+	holdRepo := &mock_test.MockLegalHoldRepository{}
+	holdRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.LegalHold, error) {
+		return &entity.LegalHold{ID: id, DocumentID: "doc-2"}, nil
+	}
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id}, nil
+	}
+
+	docs := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+	uc := usecase.NewLegalHoldUsecase(holdRepo, docs)
+
+	_, err := uc.Release(context.Background(), "doc-1", "hold-1", "counsel", "")
+	if !errors.Is(err, entity.ErrLegalHoldNotFound) {
+		t.Errorf("Release() error = %v, want %v", err, entity.ErrLegalHoldNotFound)
+	}
+}
+
+func TestReleaseHoldOnAnotherTenantsDocumentNotFound(t *testing.T) {
+	holdRepo := &mock_test.MockLegalHoldRepository{}
+	holdRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.LegalHold, error) {
+		return &entity.LegalHold{ID: id, DocumentID: "doc-1"}, nil
+	}
+	released := false
+	holdRepo.ReleaseFunc = func(ctx context.Context, hold *entity.LegalHold) error {
+		released = true
+		return nil
+	}
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, TenantID: "acme"}, nil
+	}
+
+	docs := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+	uc := usecase.NewLegalHoldUsecase(holdRepo, docs)
+
+	ctx := entity.WithPrincipal(entity.WithTenant(context.Background(), "globex"), &entity.Principal{ID: "user:mallory", TenantID: "globex", Permissions: []string{entity.PermissionAdmin}})
+	if _, err := uc.Release(ctx, "doc-1", "hold-1", "", ""); !errors.Is(err, entity.ErrDocumentNotFound) {
+		t.Errorf("Release() across tenants error = %v, want %v", err, entity.ErrDocumentNotFound)
+	}
+	if released {
+		t.Error("Release() released a hold on another tenant's document")
+	}
+}
+
+func TestListHoldsHiddenByACLNotFound(t *testing.T) {
+	holdRepo := &mock_test.MockLegalHoldRepository{}
+	read := false
+	holdRepo.FindByDocumentFunc = func(ctx context.Context, documentID string, activeOnly bool) ([]*entity.LegalHold, error) {
+		read = true
+		return []*entity.LegalHold{{ID: "hold-1", DocumentID: documentID}}, nil
+	}
+
+	docs := usecase.NewDocumentUsecase(ownedDocumentRepo("user:alice"), &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithACL(&mock_test.MockDocumentACLRepository{}))
+	uc := usecase.NewLegalHoldUsecase(holdRepo, docs)
+
+	if _, err := uc.List(withPrincipal("user:bob"), "doc-1", false); !errors.Is(err, entity.ErrDocumentNotFound) {
+		t.Errorf("List() by a reader without access error = %v, want %v", err, entity.ErrDocumentNotFound)
+	}
+	if read {
+		t.Error("List() read the holds of a document the caller cannot see")
+	}
+
+	if holds, err := uc.List(withPrincipal("user:alice"), "doc-1", false); err != nil || len(holds) != 1 {
+		t.Errorf("List() by the owner = %d holds, %v, want 1, nil", len(holds), err)
+	}
+}
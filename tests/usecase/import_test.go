@@ -0,0 +1,258 @@
+package usecase_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"docvault/database"
+	"docvault/entity"
+	"docvault/repository"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"testing"
+	"time"
+)
+
+type archiveFile struct {
+	name    string
+	content string
+}
+
+func zipArchive(t *testing.T, files ...archiveFile) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			t.Fatalf("zip Create(%s) error = %v", file.name, err)
+		}
+		io.WriteString(w, file.content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close() error = %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// memoryFolders keeps folders created through the mock so lookups find them.
+func memoryFolders() (*mock_test.MockFolderRepository, map[string]*entity.Folder) {
+	folders := map[string]*entity.Folder{}
+	repo := &mock_test.MockFolderRepository{}
+	repo.SaveFunc = func(ctx context.Context, folder *entity.Folder) error {
+		folders[folder.ID] = folder
+		return nil
+	}
+	repo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Folder, error) {
+		if folder, ok := folders[id]; ok {
+			return folder, nil
+		}
+		return nil, entity.ErrFolderNotFound
+	}
+	repo.FindChildrenFunc = func(ctx context.Context, tenantID string, parentID *string) ([]*entity.Folder, error) {
+		var children []*entity.Folder
+		for _, folder := range folders {
+			if (parentID == nil && folder.ParentID == nil) || (parentID != nil && folder.ParentID != nil && *folder.ParentID == *parentID) {
+				children = append(children, folder)
+			}
+		}
+		return children, nil
+	}
+
+	return repo, folders
+}
+
+func importUsecase(objects map[string][]byte, limits usecase.ImportLimits) (*usecase.ImportUsecase, map[string]*entity.Folder) {
+	folderRepo, folders := memoryFolders()
+	storage := memoryStorage(objects)
+	docs := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, storage, &mock_test.MockServiceQueue{}, usecase.WithFolders(folderRepo))
+
+	return usecase.NewImportUsecase(&mock_test.MockImportRepository{}, docs, usecase.NewFolderUsecase(folderRepo, docs), storage, limits), folders
+}
+
+func TestImportZipMirrorsDirectories(t *testing.T) {
+	objects := map[string][]byte{}
+	uc, folders := importUsecase(objects, usecase.ImportLimits{})
+
+	data := zipArchive(t,
+		archiveFile{"readme.txt", "hello"},
+		archiveFile{"docs/2024/a.txt", "a"},
+		archiveFile{"docs/b.txt", "b"},
+		archiveFile{"../evil.txt", "x"},
+		archiveFile{"__MACOSX/docs/._a.txt", "junk"},
+	)
+
+	imp, err := uc.Import(context.Background(), usecase.ImportInput{FileName: "share.zip", FileSize: int64(len(data)), File: bytes.NewReader(data)})
+	if err != nil {
+		t.Fatalf("Import() error = %v, want nil", err)
+	}
+
+	if imp.Status != entity.ImportStatusCompleted || imp.Imported != 3 || imp.Failed != 1 || imp.Skipped != 1 {
+		t.Errorf("Import() = %s imported %d failed %d skipped %d, want completed 3/1/1", imp.Status, imp.Imported, imp.Failed, imp.Skipped)
+	}
+	if result := imp.Results[3]; result.Path != "../evil.txt" || result.Status != entity.ImportEntryFailed || !strings.Contains(*result.Error, "unsafe path") {
+		t.Errorf("Import() traversal result = %+v, want failed as unsafe path", result)
+	}
+	if len(folders) != 2 {
+		t.Errorf("Import() created %d folders, want docs and docs/2024", len(folders))
+	}
+	if len(objects) != 3 {
+		t.Errorf("Import() stored %d objects, want 3", len(objects))
+	}
+}
+
+func TestImportStreamsGzippedTar(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "notes/", Typeflag: tar.TypeDir, Mode: 0o755})
+	tw.WriteHeader(&tar.Header{Name: "notes/a.txt", Typeflag: tar.TypeReg, Mode: 0o644, Size: 5})
+	tw.Write([]byte("hello"))
+	tw.WriteHeader(&tar.Header{Name: "notes/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"})
+	tw.Close()
+	gz.Close()
+
+	objects := map[string][]byte{}
+	uc, _ := importUsecase(objects, usecase.ImportLimits{MaxEntries: 10, MaxTotalBytes: 1024})
+
+	// A plain io.Reader, as the tar is read without seeking.
+	imp, err := uc.Import(context.Background(), usecase.ImportInput{FileName: "notes.tgz", File: io.MultiReader(&buf)})
+	if err != nil {
+		t.Fatalf("Import() error = %v, want nil", err)
+	}
+
+	if imp.Imported != 1 || imp.Skipped != 1 || imp.Results[1].Path != "notes/link" {
+		t.Errorf("Import() results = %+v, want a.txt imported and the symlink skipped", imp.Results)
+	}
+	for key, content := range objects {
+		if string(content) != "hello" {
+			t.Errorf("Import() object %s = %q, want hello", key, content)
+		}
+	}
+}
+
+func TestImportRejectsArchiveBombs(t *testing.T) {
+	bomb := zipArchive(t, archiveFile{"zeros.bin", strings.Repeat("0", 1<<20)}, archiveFile{"ok.txt", "fine"})
+
+	objects := map[string][]byte{}
+	uc, _ := importUsecase(objects, usecase.ImportLimits{MaxCompressionRatio: 100})
+
+	// Not an io.ReaderAt, so the zip is spooled to disk first.
+	imp, err := uc.Import(context.Background(), usecase.ImportInput{FileName: "bomb.zip", File: io.MultiReader(bytes.NewReader(bomb))})
+	if err != nil {
+		t.Fatalf("Import() error = %v, want nil", err)
+	}
+	if imp.Failed != 1 || imp.Imported != 1 || !strings.Contains(*imp.Results[0].Error, "compressed more than 100 to 1") {
+		t.Errorf("Import() results = %+v, want zeros.bin rejected for its ratio", imp.Results)
+	}
+
+	uc, _ = importUsecase(objects, usecase.ImportLimits{MaxTotalBytes: 1 << 10})
+	if _, err := uc.Import(context.Background(), usecase.ImportInput{FileName: "bomb.zip", FileSize: int64(len(bomb)), File: bytes.NewReader(bomb)}); !errors.Is(err, entity.ErrArchiveTooLarge) {
+		t.Errorf("Import() over total error = %v, want %v", err, entity.ErrArchiveTooLarge)
+	}
+
+	if _, err := uc.Import(context.Background(), usecase.ImportInput{FileName: "a.zip", File: strings.NewReader("not an archive")}); !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Import() of text error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+}
+
+func TestStartImportRunsAsJob(t *testing.T) {
+	db, err := database.NewSQLite(filepath.Join(t.TempDir(), "docvault.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v, want nil", err)
+	}
+	defer db.Close()
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() error = %v, want nil", err)
+	}
+
+	objects := map[string][]byte{}
+	storage := memoryStorage(objects)
+	folderRepo, _ := memoryFolders()
+	docs := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, storage, &mock_test.MockServiceQueue{}, usecase.WithFolders(folderRepo))
+	imports := repository.NewSQLiteImportRepository(db)
+	jobRepo := repository.NewSQLiteJobRepository(db)
+	jobs := usecase.NewJobUsecase(jobRepo, storage, time.Hour)
+	uc := usecase.NewImportUsecase(imports, docs, usecase.NewFolderUsecase(folderRepo, docs), storage, usecase.ImportLimits{})
+	uc.RegisterJobs(jobs)
+
+	ctx := context.Background()
+	data := zipArchive(t, archiveFile{"a.txt", "a"}, archiveFile{"b.txt", "b"})
+	start := func() *entity.Import {
+		t.Helper()
+		imp, err := uc.Start(ctx, usecase.ImportInput{FileName: "a.zip", FileSize: int64(len(data)), File: bytes.NewReader(data)})
+		if err != nil {
+			t.Fatalf("Start() error = %v, want nil", err)
+		}
+		return imp
+	}
+	status := func(id string) string {
+		t.Helper()
+		imp, err := uc.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("Get() error = %v, want nil", err)
+		}
+		return imp.Status
+	}
+	archived := func(id string) bool {
+		for key := range objects {
+			if strings.HasPrefix(key, "imports/") && strings.HasSuffix(key, "/"+id) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ran := start()
+	if ran, err := jobs.RunNext(ctx); err != nil || !ran {
+		t.Fatalf("RunNext() = %v, %v, want true, nil", ran, err)
+	}
+	if imp, _ := uc.Get(ctx, ran.ID); imp.Status != entity.ImportStatusCompleted || imp.Imported != 2 {
+		t.Errorf("import after its job ran = %s with %d imported, want completed with 2", imp.Status, imp.Imported)
+	}
+	if job, _ := jobs.Get(ctx, ran.ID); job.Status != entity.JobStatusSucceeded {
+		t.Errorf("import job = %s, want succeeded", job.Status)
+	}
+
+	canceled := start()
+	if !archived(canceled.ID) {
+		t.Fatal("Start() did not store the archive")
+	}
+	if _, err := jobs.Cancel(ctx, canceled.ID); err != nil {
+		t.Fatalf("Cancel() error = %v, want nil", err)
+	}
+	if got := status(canceled.ID); got != entity.ImportStatusCanceled {
+		t.Errorf("import canceled while queued = %s, want canceled", got)
+	}
+
+	// A restart while the job runs leaves it running with nobody on it.
+	interrupted := start()
+	if _, err := jobRepo.Claim(ctx, time.Now()); err != nil {
+		t.Fatalf("Claim() error = %v, want nil", err)
+	}
+	if err := jobs.Recover(ctx); err != nil {
+		t.Fatalf("Recover() error = %v, want nil", err)
+	}
+	if got := status(interrupted.ID); got != entity.ImportStatusFailed {
+		t.Errorf("import cut short by a restart = %s, want failed", got)
+	}
+
+	for _, imp := range []*entity.Import{ran, canceled, interrupted} {
+		if archived(imp.ID) {
+			t.Errorf("import %s left its archive in storage", imp.ID)
+		}
+	}
+
+	if _, err := jobs.Submit(ctx, usecase.JobKindImport, nil); !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Submit() of an import job error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+}
@@ -0,0 +1,181 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSubmitJobRequiresKindPermission(t *testing.T) {
+	jobs := usecase.NewJobUsecase(&mock_test.MockJobRepository{}, &mock_test.MockServiceStorage{}, time.Hour)
+	usecase.NewDocumentUsecase(archiveDocumentRepo(), &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}).RegisterJobs(jobs)
+
+	ctx := withPrincipal("user:alice")
+	if _, err := jobs.Submit(ctx, usecase.JobKindThumbnails, nil); !errors.Is(err, entity.ErrForbidden) {
+		t.Errorf("Submit(thumbnails) error = %v, want %v", err, entity.ErrForbidden)
+	}
+	if _, err := jobs.Submit(ctx, "reindex", nil); !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Submit(reindex) error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+	if _, err := jobs.Submit(ctx, usecase.JobKindArchive, json.RawMessage(`{}`)); !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Submit(archive without selection) error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+
+	job, err := jobs.Submit(ctx, usecase.JobKindArchive, json.RawMessage(`{"ids":["doc-1"]}`))
+	if err != nil {
+		t.Fatalf("Submit(archive) error = %v, want nil", err)
+	}
+	if job.Status != entity.JobStatusQueued || job.CreatedBy == nil || *job.CreatedBy != "user:alice" {
+		t.Errorf("Submit(archive) = %+v, want queued job created by user:alice", job)
+	}
+}
+
+func TestArchiveJobStoresArtifact(t *testing.T) {
+	objects := map[string][]byte{"documents/doc-1": []byte("hello")}
+	storage := memoryStorage(objects)
+	docRepo := archiveDocumentRepo(&entity.Document{ID: "doc-1", TenantID: entity.DefaultTenantID, FileName: "a.txt", FileSize: 5, StorageKey: "documents/doc-1", ScanStatus: entity.ScanStatusClean})
+
+	var finished *entity.Job
+	jobRepo := &mock_test.MockJobRepository{}
+	jobRepo.ClaimFunc = func(ctx context.Context, now time.Time) (*entity.Job, error) {
+		return &entity.Job{ID: "job-1", TenantID: entity.DefaultTenantID, Kind: usecase.JobKindArchive, Status: entity.JobStatusRunning, Params: `{"ids":["doc-1"]}`}, nil
+	}
+	jobRepo.FinishFunc = func(ctx context.Context, job *entity.Job) error {
+		finished = job
+		return nil
+	}
+
+	jobs := usecase.NewJobUsecase(jobRepo, storage, time.Hour)
+	usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{}).RegisterJobs(jobs)
+
+	if ran, err := jobs.RunNext(context.Background()); !ran || err != nil {
+		t.Fatalf("RunNext() = %v, %v, want true, nil", ran, err)
+	}
+
+	if finished == nil {
+		t.Fatal("RunNext() did not finish the job")
+	}
+	if finished.Status != entity.JobStatusSucceeded {
+		t.Fatalf("RunNext() finished %+v, want succeeded job", finished)
+	}
+	if finished.ArtifactKey == nil || readArchive(t, objects[*finished.ArtifactKey])["a.txt"] != "hello" {
+		t.Errorf("RunNext() artifact = %v, want a zip holding a.txt", finished.ArtifactKey)
+	}
+	if finished.Progress != 5 || finished.Total != 5 {
+		t.Errorf("RunNext() progress = %d/%d, want 5/5", finished.Progress, finished.Total)
+	}
+}
+
+func TestRunningJobStopsWhenCanceled(t *testing.T) {
+	var finished *entity.Job
+	jobRepo := &mock_test.MockJobRepository{}
+	jobRepo.ClaimFunc = func(ctx context.Context, now time.Time) (*entity.Job, error) {
+		return &entity.Job{ID: "job-1", Kind: "wait", Status: entity.JobStatusRunning}, nil
+	}
+	jobRepo.UpdateProgressFunc = func(ctx context.Context, id string, progress, total int64, now time.Time) (bool, error) {
+		return true, nil
+	}
+	jobRepo.FinishFunc = func(ctx context.Context, job *entity.Job) error {
+		finished = job
+		return nil
+	}
+
+	jobs := usecase.NewJobUsecase(jobRepo, &mock_test.MockServiceStorage{}, time.Hour)
+	jobs.Register("wait", usecase.JobKind{Run: func(ctx context.Context, job *entity.Job, run *usecase.JobRun) error {
+		run.Progress(ctx, 1, 10)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	if _, err := jobs.RunNext(context.Background()); err != nil {
+		t.Fatalf("RunNext() error = %v, want nil", err)
+	}
+
+	if finished == nil || finished.Status != entity.JobStatusCanceled {
+		t.Errorf("RunNext() finished %+v, want canceled job", finished)
+	}
+}
+
+func TestRecoverRequeuesResumableJobs(t *testing.T) {
+	jobRepo := &mock_test.MockJobRepository{}
+	jobRepo.FindByStatusFunc = func(ctx context.Context, status string) ([]*entity.Job, error) {
+		return []*entity.Job{
+			{ID: "job-1", Kind: "resumable", Status: entity.JobStatusRunning, Attempts: 1},
+			{ID: "job-2", Kind: "once", Status: entity.JobStatusRunning, Attempts: 1},
+			{ID: "job-3", Kind: "resumable", Status: entity.JobStatusRunning, Attempts: 1, CancelRequested: true},
+			{ID: "job-4", Kind: "resumable", Status: entity.JobStatusRunning, Attempts: 3},
+		}, nil
+	}
+	var requeued []string
+	jobRepo.RequeueFunc = func(ctx context.Context, id string, now time.Time) error {
+		requeued = append(requeued, id)
+		return nil
+	}
+	failed := map[string]string{}
+	jobRepo.FinishFunc = func(ctx context.Context, job *entity.Job) error {
+		failed[job.ID] = job.Status
+		return nil
+	}
+
+	jobs := usecase.NewJobUsecase(jobRepo, &mock_test.MockServiceStorage{}, time.Hour)
+	jobs.Register("resumable", usecase.JobKind{Resumable: true})
+	jobs.Register("once", usecase.JobKind{})
+
+	if err := jobs.Recover(context.Background()); err != nil {
+		t.Fatalf("Recover() error = %v, want nil", err)
+	}
+
+	if len(requeued) != 1 || requeued[0] != "job-1" {
+		t.Errorf("Recover() requeued %v, want [job-1]", requeued)
+	}
+	want := map[string]string{
+		"job-2": entity.JobStatusFailed,
+		"job-3": entity.JobStatusCanceled,
+		"job-4": entity.JobStatusFailed,
+	}
+	if len(failed) != len(want) {
+		t.Errorf("Recover() finished %v, want %v", failed, want)
+	}
+	for id, status := range want {
+		if failed[id] != status {
+			t.Errorf("Recover() finished %s as %q, want %q", id, failed[id], status)
+		}
+	}
+}
+
+func TestShutdownReleasesResumableJobs(t *testing.T) {
+	jobRepo := &mock_test.MockJobRepository{}
+	jobRepo.ClaimFunc = func(ctx context.Context, now time.Time) (*entity.Job, error) {
+		return &entity.Job{ID: "job-1", Kind: "resumable", Status: entity.JobStatusRunning, Attempts: 1}, nil
+	}
+	var released []string
+	jobRepo.ReleaseFunc = func(ctx context.Context, id string, now time.Time) error {
+		released = append(released, id)
+		return nil
+	}
+	jobRepo.FinishFunc = func(ctx context.Context, job *entity.Job) error {
+		t.Errorf("RunNext() finished %s with %q during a shutdown", job.ID, job.Status)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jobs := usecase.NewJobUsecase(jobRepo, &mock_test.MockServiceStorage{}, time.Hour)
+	jobs.Register("resumable", usecase.JobKind{Resumable: true, Run: func(ctx context.Context, job *entity.Job, run *usecase.JobRun) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	if _, err := jobs.RunNext(ctx); err != nil {
+		t.Fatalf("RunNext() error = %v, want nil", err)
+	}
+
+	if len(released) != 1 || released[0] != "job-1" {
+		t.Errorf("RunNext() released %v, want [job-1]", released)
+	}
+}
@@ -0,0 +1,241 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"docvault/entity"
+	"docvault/service"
+	"docvault/usecase"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testJWTSecret = "test-secret-test-secret-test-secret"
+
+func signingInput(t *testing.T, header, claims map[string]any) string {
+	t.Helper()
+
+	encode := func(value map[string]any) string {
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	return encode(header) + "." + encode(claims)
+}
+
+func mintHS256(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+
+	input := signingInput(t, map[string]any{"alg": "HS256", "typ": "JWT"}, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func mintRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+
+	input := signingInput(t, map[string]any{"alg": "RS256", "typ": "JWT", "kid": kid}, claims)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15() error = %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims(roles ...string) map[string]any {
+	return map[string]any{
+		"sub":   "alice",
+		"iss":   "https://auth.example.com",
+		"aud":   "docvault",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	}
+}
+
+func hs256Authenticator(t *testing.T) *usecase.JWTAuthenticator {
+	t.Helper()
+
+	verifier, err := service.NewJWTVerifier(service.JWTOptions{
+		HMACSecret: []byte(testJWTSecret),
+		Issuer:     "https://auth.example.com",
+		Audience:   "docvault",
+	})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() error = %v", err)
+	}
+
+	return usecase.NewJWTAuthenticator(verifier, "", "", "")
+}
+
+func TestJWTAuthenticateMapsRolesToPermissions(t *testing.T) {
+	auth := hs256Authenticator(t)
+
+	tests := []struct {
+		roles  []string
+		can    []string
+		cannot []string
+	}{
+		{[]string{"viewer"}, []string{entity.PermissionDocumentsRead}, []string{entity.PermissionDocumentsWrite, entity.PermissionAdmin}},
+		{[]string{"editor"}, []string{entity.PermissionDocumentsWrite, entity.PermissionDocumentsDelete}, []string{entity.PermissionAdmin}},
+		{[]string{"admin"}, []string{entity.PermissionAdmin, entity.PermissionDocumentsDelete}, nil},
+		{[]string{"guest"}, nil, []string{entity.PermissionDocumentsRead}},
+	}
+
+	for _, tt := range tests {
+		principal, err := auth.Authenticate(context.Background(), mintHS256(t, testJWTSecret, validClaims(tt.roles...)))
+		if err != nil {
+			t.Fatalf("Authenticate(%v) error = %v, want nil", tt.roles, err)
+		}
+		if principal.ID != "user:alice" || principal.Type != entity.PrincipalTypeUser {
+			t.Errorf("Authenticate(%v) principal = %+v, want user:alice", tt.roles, principal)
+		}
+		for _, permission := range tt.can {
+			if !principal.Can(permission) {
+				t.Errorf("Authenticate(%v) cannot %s, want allowed", tt.roles, permission)
+			}
+		}
+		for _, permission := range tt.cannot {
+			if principal.Can(permission) {
+				t.Errorf("Authenticate(%v) can %s, want denied", tt.roles, permission)
+			}
+		}
+	}
+}
+
+func TestJWTAuthenticateRejectsInvalidTokens(t *testing.T) {
+	auth := hs256Authenticator(t)
+
+	expired := validClaims("viewer")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	notYetValid := validClaims("viewer")
+	notYetValid["nbf"] = time.Now().Add(time.Hour).Unix()
+
+	wrongIssuer := validClaims("viewer")
+	wrongIssuer["iss"] = "https://evil.example.com"
+
+	wrongAudience := validClaims("viewer")
+	wrongAudience["aud"] = []string{"other", "another"}
+
+	missingExp := validClaims("viewer")
+	delete(missingExp, "exp")
+
+	unsigned := signingInput(t, map[string]any{"alg": "none"}, validClaims("admin")) + "."
+
+	tests := map[string]string{
+		"expired":         mintHS256(t, testJWTSecret, expired),
+		"not yet valid":   mintHS256(t, testJWTSecret, notYetValid),
+		"wrong issuer":    mintHS256(t, testJWTSecret, wrongIssuer),
+		"wrong audience":  mintHS256(t, testJWTSecret, wrongAudience),
+		"missing exp":     mintHS256(t, testJWTSecret, missingExp),
+		"wrong secret":    mintHS256(t, "another-secret", validClaims("viewer")),
+		"alg none":        unsigned,
+		"malformed parts": "a.b.c",
+	}
+
+	for name, token := range tests {
+		principal, err := auth.Authenticate(context.Background(), token)
+		if !errors.Is(err, entity.ErrUnauthorized) {
+			t.Errorf("%s: Authenticate() error = %v, want ErrUnauthorized", name, err)
+		}
+		if principal != nil {
+			t.Errorf("%s: Authenticate() principal = %+v, want nil", name, principal)
+		}
+	}
+}
+
+func TestJWTAuthenticateAcceptsAudienceListAndLeeway(t *testing.T) {
+	verifier, err := service.NewJWTVerifier(service.JWTOptions{
+		HMACSecret: []byte(testJWTSecret),
+		Audience:   "docvault",
+		Leeway:     time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() error = %v", err)
+	}
+	auth := usecase.NewJWTAuthenticator(verifier, "", "", "")
+
+	claims := validClaims("viewer")
+	claims["aud"] = []string{"other", "docvault"}
+	claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+
+	if _, err := auth.Authenticate(context.Background(), mintHS256(t, testJWTSecret, claims)); err != nil {
+		t.Errorf("Authenticate() error = %v, want nil within leeway", err)
+	}
+}
+
+func TestJWTAuthenticateIgnoresNonJWTCredentials(t *testing.T) {
+	auth := hs256Authenticator(t)
+
+	principal, err := auth.Authenticate(context.Background(), "dv_abcdef123456_secret")
+	if err != nil || principal != nil {
+		t.Errorf("Authenticate() = %+v, %v, want nil, nil", principal, err)
+	}
+}
+
+func TestJWTAuthenticateRS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]any{{
+		"kty": "RSA",
+		"kid": "key-1",
+		"alg": "RS256",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	verifier, err := service.NewJWTVerifier(service.JWTOptions{JWKSFile: path, Audience: "docvault"})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() error = %v", err)
+	}
+	auth := usecase.NewJWTAuthenticator(verifier, "realm_roles", "", "")
+
+	claims := validClaims()
+	claims["realm_roles"] = "viewer editor"
+	claims["groups"] = []string{"legal"}
+
+	principal, err := auth.Authenticate(context.Background(), mintRS256(t, key, "key-1", claims))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v, want nil", err)
+	}
+	if !slices.Equal(principal.Roles, []string{"viewer", "editor"}) || !principal.Can(entity.PermissionDocumentsDelete) {
+		t.Errorf("Authenticate() principal = %+v, want viewer+editor roles", principal)
+	}
+	if !slices.Equal(principal.Subjects(), []string{"user:alice", "group:legal"}) {
+		t.Errorf("Subjects() = %v, want user:alice and group:legal", principal.Subjects())
+	}
+
+	if _, err := auth.Authenticate(context.Background(), mintRS256(t, key, "unknown-kid", claims)); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("Authenticate() with unknown kid error = %v, want ErrUnauthorized", err)
+	}
+
+	// An HS256 token signed with the public modulus must not pass as RS256.
+	forged := mintHS256(t, string(key.N.Bytes()), claims)
+	if _, err := auth.Authenticate(context.Background(), forged); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("Authenticate() with alg confusion error = %v, want ErrUnauthorized", err)
+	}
+}
@@ -0,0 +1,66 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/database"
+	"docvault/repository"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRenamedLegacyDocumentKeepsItsObject runs the migrations over a row
+// from before storage keys existed, whose object sits under its file name.
+func TestRenamedLegacyDocumentKeepsItsObject(t *testing.T) {
+	db, err := database.NewSQLite(filepath.Join(t.TempDir(), "docvault.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v, want nil", err)
+	}
+	defer db.Close()
+
+	if err := database.CreateDocumentsTable(db); err != nil {
+		t.Fatalf("CreateDocumentsTable() error = %v, want nil", err)
+	}
+	if _, err := db.Exec(`INSERT INTO documents (id, file_name, file_size, content_type, created_at) VALUES ('legacy-1', 'report.pdf', 6, 'application/pdf', ?)`, time.Now()); err != nil {
+		t.Fatalf("inserting legacy document: %v", err)
+	}
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() error = %v, want nil", err)
+	}
+
+	objects := map[string][]byte{"report.pdf": []byte("report"), "summary.pdf": []byte("another document")}
+	uc := usecase.NewDocumentUsecase(repository.NewSQLiteDocumentRepository(db), memoryStorage(objects), &mock_test.MockServiceQueue{})
+	ctx := context.Background()
+
+	renamed := "summary.pdf"
+	if _, err := uc.Update(ctx, "legacy-1", usecase.UpdateInput{FileName: &renamed}); err != nil {
+		t.Fatalf("Update() error = %v, want nil", err)
+	}
+
+	doc, object, err := uc.Download(ctx, "legacy-1")
+	if err != nil {
+		t.Fatalf("Download() error = %v, want nil", err)
+	}
+	content, _ := io.ReadAll(object)
+	object.Close()
+	if doc.FileName != renamed || string(content) != "report" {
+		t.Errorf("Download() = %s %q, want summary.pdf holding report", doc.FileName, content)
+	}
+
+	if err := uc.Delete(ctx, "legacy-1"); err != nil {
+		t.Fatalf("Delete() error = %v, want nil", err)
+	}
+	if err := uc.Purge(ctx, "legacy-1"); err != nil {
+		t.Fatalf("Purge() error = %v, want nil", err)
+	}
+	if _, ok := objects["report.pdf"]; ok {
+		t.Error("Purge() left the document's object behind")
+	}
+	if !strings.Contains(string(objects["summary.pdf"]), "another") {
+		t.Error("Purge() removed the object stored under the new file name")
+	}
+}
@@ -0,0 +1,110 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/service"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func reconcileFixture() (*mock_test.MockDocumentRepository, *mock_test.MockServiceStorage, map[string]*time.Time, *[]string) {
+	old := time.Now().Add(-24 * time.Hour)
+	earlier := old.Add(-time.Hour)
+	docs := []*entity.Document{
+		{ID: "doc-1", StorageKey: "default/doc-1"},
+		{ID: "doc-2", StorageKey: "default/doc-2"},
+		{ID: "doc-3", StorageKey: "default/doc-3", MissingAt: &earlier},
+	}
+
+	marked := map[string]*time.Time{}
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindStoredFunc = func(ctx context.Context) ([]*entity.Document, error) {
+		return docs, nil
+	}
+	docRepo.UpdateMissingFunc = func(ctx context.Context, id string, missingAt *time.Time) error {
+		marked[id] = missingAt
+		return nil
+	}
+
+	var deleted []string
+	storage := &mock_test.MockServiceStorage{}
+	storage.ListFunc = func(ctx context.Context, prefix string) ([]service.StorageObject, error) {
+		return []service.StorageObject{
+			{Key: "default/doc-1", LastModified: old},
+			{Key: "default/doc-3", LastModified: old},
+			{Key: "thumbnails/default/doc-1/128", LastModified: old},
+			{Key: "thumbnails/default/gone/128", LastModified: old},
+			{Key: "default/orphan", Size: 7, LastModified: old},
+			{Key: "default/uploading", LastModified: time.Now()},
+			{Key: "jobs/default/job-1/documents.zip", LastModified: old},
+		}, nil
+	}
+	storage.DeleteFunc = func(ctx context.Context, filename string) error {
+		deleted = append(deleted, filename)
+		return nil
+	}
+
+	return docRepo, storage, marked, &deleted
+}
+
+func TestReconcileReportsWithoutRepairing(t *testing.T) {
+	docRepo, storage, marked, deleted := reconcileFixture()
+	uc := usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{})
+
+	report, err := uc.Reconcile(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v, want nil", err)
+	}
+
+	var orphans []string
+	for _, orphan := range report.Orphans {
+		orphans = append(orphans, orphan.Key)
+	}
+	if !slices.Equal(orphans, []string{"thumbnails/default/gone/128", "default/orphan"}) {
+		t.Errorf("Reconcile() orphans = %v, want the orphaned thumbnail and object", orphans)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Document.ID != "doc-2" || report.Missing[0].Marked {
+		t.Errorf("Reconcile() missing = %+v, want doc-2 unmarked", report.Missing)
+	}
+	if !slices.Equal(report.Found, []string{"doc-3"}) {
+		t.Errorf("Reconcile() found = %v, want [doc-3]", report.Found)
+	}
+	if len(*deleted) != 0 || len(marked) != 0 {
+		t.Errorf("Reconcile() without repair deleted %v and marked %v", *deleted, marked)
+	}
+}
+
+func TestReconcileRepairs(t *testing.T) {
+	docRepo, storage, marked, deleted := reconcileFixture()
+	uc := usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{})
+
+	report, err := uc.Reconcile(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v, want nil", err)
+	}
+
+	if !slices.Equal(*deleted, []string{"thumbnails/default/gone/128", "default/orphan"}) || !report.Orphans[1].Deleted {
+		t.Errorf("Reconcile() deleted %v, want the orphaned thumbnail and object", *deleted)
+	}
+	if marked["doc-2"] == nil || !report.Missing[0].Marked {
+		t.Errorf("Reconcile() did not mark doc-2 missing")
+	}
+	if missingAt, ok := marked["doc-3"]; !ok || missingAt != nil {
+		t.Errorf("Reconcile() did not clear the missing mark of doc-3")
+	}
+}
+
+func TestDownloadRefusesMissingDocument(t *testing.T) {
+	now := time.Now()
+	uc := usecase.NewDocumentUsecase(archiveDocumentRepo(&entity.Document{ID: "doc-1", TenantID: entity.DefaultTenantID, MissingAt: &now}),
+		&mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+
+	if _, _, err := uc.Download(context.Background(), "doc-1"); !errors.Is(err, entity.ErrDocumentMissing) {
+		t.Errorf("Download() error = %v, want %v", err, entity.ErrDocumentMissing)
+	}
+}
@@ -0,0 +1,234 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/database"
+	"docvault/entity"
+	"docvault/repository"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
+func TestUploadAppliesPolicyDefaultTTL(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	policyRepo := &mock_test.MockRetentionPolicyRepository{}
+
+	policyRepo.FindAllFunc = func(ctx context.Context) ([]*entity.RetentionPolicy, error) {
+		return []*entity.RetentionPolicy{
+			{ID: "any", Name: "any", DefaultTTL: durationPtr(time.Hour)},
+			{ID: "pdf", Name: "pdf", ContentType: "application/pdf", DefaultTTL: durationPtr(30 * 24 * time.Hour)},
+		}, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithRetentionPolicies(policyRepo))
+
+	doc, err := uc.Upload(context.Background(), usecase.UploadInput{
+		FileName:    "report.pdf",
+		FileSize:    4,
+		ContentType: "application/pdf",
+		File:        strings.NewReader("data"),
+	})
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+
+	if doc.RetentionPolicyID == nil || *doc.RetentionPolicyID != "pdf" {
+		t.Fatalf("Upload() RetentionPolicyID = %v, want pdf", doc.RetentionPolicyID)
+	}
+	if doc.ExpiresAt == nil || !doc.ExpiresAt.Equal(doc.CreatedAt.Add(30*24*time.Hour)) {
+		t.Errorf("Upload() ExpiresAt = %v, want created_at + 30 days", doc.ExpiresAt)
+	}
+}
+
+func TestUploadWithoutPolicyKeepsDocument(t *testing.T) {
+	uc := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+
+	doc, err := uc.Upload(context.Background(), usecase.UploadInput{
+		FileName:    "notes.txt",
+		FileSize:    4,
+		ContentType: "text/plain",
+		File:        strings.NewReader("data"),
+	})
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+
+	if doc.ExpiresAt != nil {
+		t.Errorf("Upload() ExpiresAt = %v, want nil", doc.ExpiresAt)
+	}
+}
+
+func TestRetentionPolicyClampsRequestedExpiry(t *testing.T) {
+	created := time.Now()
+	policy := &entity.RetentionPolicy{
+		MinRetention: durationPtr(24 * time.Hour),
+		MaxRetention: durationPtr(7 * 24 * time.Hour),
+	}
+
+	tooSoon := created.Add(time.Hour)
+	doc := &entity.Document{CreatedAt: created, RequestedExpiresAt: &tooSoon}
+	if got := policy.ExpiresAt(doc); got == nil || !got.Equal(created.Add(24*time.Hour)) {
+		t.Errorf("ExpiresAt() = %v, want created_at + min_retention", got)
+	}
+
+	forever := &entity.Document{CreatedAt: created, RetainForever: true}
+	if got := policy.ExpiresAt(forever); got == nil || !got.Equal(created.Add(7*24*time.Hour)) {
+		t.Errorf("ExpiresAt() = %v, want created_at + max_retention", got)
+	}
+}
+
+func TestDeleteExpiredSkipsDocumentsExtendedByPolicy(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	policyRepo := &mock_test.MockRetentionPolicyRepository{}
+
+	created := time.Now().Add(-2 * time.Hour)
+	expired := created.Add(time.Hour)
+	docRepo.FindExpiredFunc = func(ctx context.Context, now time.Time) ([]*entity.Document, error) {
+		return []*entity.Document{{ID: "doc-1", ContentType: "image/png", CreatedAt: created, ExpiresAt: &expired}}, nil
+	}
+	policyRepo.FindAllFunc = func(ctx context.Context) ([]*entity.RetentionPolicy, error) {
+		return []*entity.RetentionPolicy{{ID: "images", Name: "images", ContentType: "image/*", NeverExpire: true}}, nil
+	}
+
+	var retained *entity.Document
+	docRepo.UpdateRetentionFunc = func(ctx context.Context, doc *entity.Document) error {
+		retained = doc
+		return nil
+	}
+	docRepo.TrashFunc = func(ctx context.Context, id string, deletedAt time.Time) error {
+		t.Errorf("DeleteExpiredDocuments() trashed %s, want it retained", id)
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithRetentionPolicies(policyRepo))
+
+	if err := uc.DeleteExpiredDocuments(context.Background()); err != nil {
+		t.Fatalf("DeleteExpiredDocuments() error = %v, want nil", err)
+	}
+
+	if retained == nil || retained.ExpiresAt != nil {
+		t.Errorf("DeleteExpiredDocuments() retained = %+v, want expiry cleared", retained)
+	}
+}
+
+func TestCreatePolicyRejectsMinAboveMax(t *testing.T) {
+	policyRepo := &mock_test.MockRetentionPolicyRepository{}
+	policyRepo.SaveFunc = func(ctx context.Context, policy *entity.RetentionPolicy) error {
+		t.Errorf("Create() saved an invalid policy")
+		return nil
+	}
+
+	docs := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithRetentionPolicies(policyRepo))
+	uc := usecase.NewRetentionUsecase(policyRepo, &mock_test.MockFolderRepository{}, docs)
+
+	_, err := uc.Create(context.Background(), usecase.RetentionPolicyInput{
+		Name:         "bad",
+		MinRetention: durationPtr(48 * time.Hour),
+		MaxRetention: durationPtr(24 * time.Hour),
+	})
+	if !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Create() error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+}
+
+func TestReapplyRetentionPagesThroughDocuments(t *testing.T) {
+	docs := make([]*entity.Document, 1200)
+	for i := range docs {
+		docs[i] = &entity.Document{ID: "doc", CreatedAt: time.Now()}
+	}
+
+	docRepo := &mock_test.MockDocumentRepository{}
+	var pages []int
+	docRepo.FindAllFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+		if filter.Limit == 0 {
+			t.Fatal("ReapplyRetention() listed every document at once, want pages")
+		}
+		pages = append(pages, filter.Offset)
+		end := min(filter.Offset+filter.Limit, len(docs))
+		return docs[filter.Offset:end], nil
+	}
+	updated := 0
+	docRepo.UpdateRetentionFunc = func(ctx context.Context, doc *entity.Document) error {
+		updated++
+		return nil
+	}
+	policyRepo := &mock_test.MockRetentionPolicyRepository{}
+	policyRepo.FindAllFunc = func(ctx context.Context) ([]*entity.RetentionPolicy, error) {
+		return []*entity.RetentionPolicy{{ID: "any", Name: "any", DefaultTTL: durationPtr(time.Hour)}}, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithRetentionPolicies(policyRepo))
+
+	count, err := uc.ReapplyRetention(context.Background())
+	if err != nil {
+		t.Fatalf("ReapplyRetention() error = %v, want nil", err)
+	}
+	if count != len(docs) || updated != len(docs) || len(pages) != 3 {
+		t.Errorf("ReapplyRetention() = %d, updated %d in pages %v, want all %d in 3 pages", count, updated, pages, len(docs))
+	}
+}
+
+// TestPagingDocumentsCreatedTogether pages through documents
+// that share created_at, as bulk imports and migrations leave them.
+func TestPagingDocumentsCreatedTogether(t *testing.T) {
+	db, err := database.NewSQLite(filepath.Join(t.TempDir(), "docvault.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v, want nil", err)
+	}
+	defer db.Close()
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() error = %v, want nil", err)
+	}
+
+	createdAt := time.Now()
+	for i := range 1200 {
+		id := fmt.Sprintf("doc-%04d", (i*7919)%1200)
+		if _, err := db.Exec(`INSERT INTO documents (id, file_name, file_size, content_type, created_at) VALUES (?, 'a.txt', 1, 'text/plain', ?)`, id, createdAt); err != nil {
+			t.Fatalf("inserting document: %v", err)
+		}
+	}
+
+	policyRepo := &mock_test.MockRetentionPolicyRepository{}
+	policyRepo.FindAllFunc = func(ctx context.Context) ([]*entity.RetentionPolicy, error) {
+		return []*entity.RetentionPolicy{{ID: "any", Name: "any", DefaultTTL: durationPtr(time.Hour)}}, nil
+	}
+	uc := usecase.NewDocumentUsecase(repository.NewSQLiteDocumentRepository(db), &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithRetentionPolicies(policyRepo))
+
+	if _, err := uc.ReapplyRetention(context.Background()); err != nil {
+		t.Fatalf("ReapplyRetention() error = %v, want nil", err)
+	}
+
+	var missed int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM documents WHERE retention_policy_id IS NULL`).Scan(&missed); err != nil {
+		t.Fatalf("counting documents: %v", err)
+	}
+	if missed != 0 {
+		t.Errorf("ReapplyRetention() skipped %d of 1200 documents", missed)
+	}
+
+	// Ties must break the same way on every query for offsets to line up.
+	var listed []string
+	for offset := 0; offset < 1200; offset += 500 {
+		docs, err := uc.List(context.Background(), entity.DocumentFilter{Limit: 500, Offset: offset})
+		if err != nil {
+			t.Fatalf("List() error = %v, want nil", err)
+		}
+		for _, doc := range docs {
+			listed = append(listed, doc.ID)
+		}
+	}
+	if len(listed) != 1200 || !slices.IsSorted(listed) || len(slices.Compact(slices.Clone(listed))) != 1200 {
+		t.Errorf("List() pages of documents created together = %d ids, want all 1200 in id order", len(listed))
+	}
+}
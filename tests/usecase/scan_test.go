@@ -0,0 +1,203 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"docvault/database"
+	"docvault/entity"
+	"docvault/repository"
+	"docvault/service"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// memoryStorage keeps uploaded objects so tests can follow them being moved.
+func memoryStorage(objects map[string][]byte) *mock_test.MockServiceStorage {
+	storage := &mock_test.MockServiceStorage{}
+	storage.UploadFunc = func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+		content, err := io.ReadAll(file)
+		objects[filename] = content
+		return err
+	}
+	storage.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(objects[filename])), nil
+	}
+	storage.DeleteFunc = func(ctx context.Context, filename string) error {
+		delete(objects, filename)
+		return nil
+	}
+
+	return storage
+}
+
+func TestUploadQuarantinesInfectedFile(t *testing.T) {
+	objects := map[string][]byte{}
+	var events []string
+	queue := &mock_test.MockServiceQueue{}
+	queue.PublishFunc = func(ctx context.Context, message string) error {
+		events = append(events, message)
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, memoryStorage(objects), queue,
+		usecase.WithScanner(service.NewSignatureScanner()))
+
+	doc, err := uc.Upload(context.Background(), usecase.UploadInput{FileName: "eicar.com", FileSize: int64(len(eicar)), File: strings.NewReader(eicar)})
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+
+	if doc.ScanStatus != entity.ScanStatusInfected || doc.ScanResult == nil || *doc.ScanResult != "EICAR-Test-File" {
+		t.Errorf("Upload() scan = %s %v, want infected with EICAR-Test-File", doc.ScanStatus, doc.ScanResult)
+	}
+	if doc.StorageKey != "quarantine/default/"+doc.ID || len(objects) != 1 || objects[doc.StorageKey] == nil {
+		t.Errorf("Upload() storage key = %s, objects = %d, want only quarantine/default/%s", doc.StorageKey, len(objects), doc.ID)
+	}
+	if len(events) != 2 || !strings.Contains(events[1], `"type":"file.quarantined"`) {
+		t.Errorf("Upload() events = %v, want file.uploaded then file.quarantined", events)
+	}
+}
+
+func TestUploadMarksCleanFileScanned(t *testing.T) {
+	uc := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, memoryStorage(map[string][]byte{}), &mock_test.MockServiceQueue{},
+		usecase.WithScanner(service.NewSignatureScanner()))
+
+	doc, err := uc.Upload(context.Background(), usecase.UploadInput{FileName: "a.txt", FileSize: 4, File: strings.NewReader("data")})
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+
+	if doc.ScanStatus != entity.ScanStatusClean || doc.ScannedAt == nil {
+		t.Errorf("Upload() ScanStatus = %s, ScannedAt = %v, want clean with a scan time", doc.ScanStatus, doc.ScannedAt)
+	}
+}
+
+func TestDownloadBlockedUntilClean(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	uc := usecase.NewDocumentUsecase(docRepo, memoryStorage(map[string][]byte{"default/doc-1": []byte("data")}), &mock_test.MockServiceQueue{},
+		usecase.WithScanner(service.NewSignatureScanner()))
+
+	for status, want := range map[string]error{
+		entity.ScanStatusPending:  entity.ErrScanPending,
+		entity.ScanStatusInfected: entity.ErrDocumentInfected,
+		entity.ScanStatusClean:    nil,
+	} {
+		docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+			return &entity.Document{ID: id, TenantID: entity.DefaultTenantID, StorageKey: "default/" + id, ScanStatus: status}, nil
+		}
+
+		_, object, err := uc.Download(context.Background(), "doc-1")
+		if !errors.Is(err, want) {
+			t.Errorf("Download() with %s scan error = %v, want %v", status, err, want)
+		}
+		if object != nil {
+			object.Close()
+		}
+	}
+}
+
+func TestScanPendingQuarantinesLegacyDocument(t *testing.T) {
+	objects := map[string][]byte{"legacy.txt": []byte(eicar)}
+	var updated *entity.Document
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByScanStatusFunc = func(ctx context.Context, status string, attemptedBefore time.Time, limit int) ([]*entity.Document, error) {
+		return []*entity.Document{{ID: "doc-1", FileName: "legacy.txt", StorageKey: "legacy.txt", FileSize: int64(len(eicar)), ScanStatus: status}}, nil
+	}
+	docRepo.UpdateScanFunc = func(ctx context.Context, doc *entity.Document) error {
+		updated = doc
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, memoryStorage(objects), &mock_test.MockServiceQueue{},
+		usecase.WithScanner(service.NewSignatureScanner()))
+
+	if err := uc.ScanPending(context.Background()); err != nil {
+		t.Fatalf("ScanPending() error = %v, want nil", err)
+	}
+
+	if updated == nil || updated.ScanStatus != entity.ScanStatusInfected || updated.StorageKey != "quarantine/legacy.txt" {
+		t.Fatalf("ScanPending() updated = %+v, want infected under quarantine/legacy.txt", updated)
+	}
+	if _, ok := objects["legacy.txt"]; ok {
+		t.Errorf("ScanPending() left the infected object at its original key")
+	}
+}
+
+// TestScanPendingGetsPastDocumentsItCannotScan fills the first batch with
+// documents whose object is gone and checks that a newer one is still
+// scanned, and that the others are eventually given up on.
+func TestScanPendingGetsPastDocumentsItCannotScan(t *testing.T) {
+	db, err := database.NewSQLite(filepath.Join(t.TempDir(), "docvault.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v, want nil", err)
+	}
+	defer db.Close()
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() error = %v, want nil", err)
+	}
+
+	repo := repository.NewSQLiteDocumentRepository(db)
+	ctx := context.Background()
+	created := time.Now().Add(-time.Hour)
+	save := func(id string) {
+		created = created.Add(time.Second)
+		if err := repo.Save(ctx, &entity.Document{ID: id, FileName: id, StorageKey: "default/" + id, CreatedAt: created}, nil); err != nil {
+			t.Fatalf("Save(%s) error = %v, want nil", id, err)
+		}
+	}
+	for i := range 100 {
+		save(fmt.Sprintf("broken-%03d", i))
+	}
+	save("missing")
+	missingAt := time.Now()
+	if err := repo.UpdateMissing(ctx, "missing", &missingAt); err != nil {
+		t.Fatalf("UpdateMissing() error = %v, want nil", err)
+	}
+	save("fine")
+
+	storage := objectStorage(map[string][]byte{"default/fine": []byte("clean content")})
+	var tried []string
+	download := storage.DownloadFunc
+	storage.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		tried = append(tried, filename)
+		return download(ctx, filename)
+	}
+	uc := usecase.NewDocumentUsecase(repo, storage, &mock_test.MockServiceQueue{}, usecase.WithScanner(service.NewSignatureScanner()))
+
+	// Each run stands for one an hour apart, past the retry delay.
+	for run := range 6 {
+		if err := uc.ScanPending(ctx); err != nil {
+			t.Fatalf("ScanPending() error = %v, want nil", err)
+		}
+		if _, err := db.Exec(`UPDATE documents SET scan_attempted_at = ? WHERE scan_attempted_at IS NOT NULL`, time.Now().Add(-2*time.Hour)); err != nil {
+			t.Fatalf("backdating scan attempts: %v", err)
+		}
+
+		if run == 1 {
+			if doc, _ := repo.FindById(ctx, "fine"); doc.ScanStatus != entity.ScanStatusClean {
+				t.Errorf("fine after two runs = %s, want clean", doc.ScanStatus)
+			}
+		}
+	}
+
+	if slices.Contains(tried, "default/missing") {
+		t.Error("ScanPending() tried a document marked missing")
+	}
+	broken, _ := repo.FindById(ctx, "broken-000")
+	if broken.ScanStatus != entity.ScanStatusError || broken.ScanResult == nil {
+		t.Errorf("broken-000 = %s %v, want error with the failure", broken.ScanStatus, broken.ScanResult)
+	}
+	if _, _, err := uc.Download(ctx, "broken-000"); !errors.Is(err, entity.ErrScanFailed) {
+		t.Errorf("Download() of a document given up on error = %v, want %v", err, entity.ErrScanFailed)
+	}
+}
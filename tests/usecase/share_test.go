@@ -0,0 +1,163 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type trackedReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *trackedReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func shareFixture(t *testing.T) (*usecase.ShareUsecase, *mock_test.MockShareLinkRepository, *mock_test.MockServiceStorage) {
+	t.Helper()
+
+	shareRepo := &mock_test.MockShareLinkRepository{}
+	storage := &mock_test.MockServiceStorage{}
+	docs := usecase.NewDocumentUsecase(ownedDocumentRepo("user:alice"), storage, &mock_test.MockServiceQueue{})
+
+	return usecase.NewShareUsecase(shareRepo, docs), shareRepo, storage
+}
+
+func TestCreateShareLinkStoresOnlyHashes(t *testing.T) {
+	uc, shareRepo, _ := shareFixture(t)
+
+	var saved *entity.ShareLink
+	shareRepo.SaveFunc = func(ctx context.Context, link *entity.ShareLink) error {
+		saved = link
+		return nil
+	}
+
+	link, token, err := uc.Create(withPrincipal("user:alice"), "doc-1", usecase.ShareInput{ExpiresIn: 3600, Password: "hunter2", MaxDownloads: 3})
+	if err != nil {
+		t.Fatalf("Create() error = %v, want nil", err)
+	}
+
+	if len(token) < 40 || saved.TokenHash == token || strings.Contains(saved.TokenHash, token) {
+		t.Errorf("Create() token = %q, TokenHash = %q, want long token stored only as a hash", token, saved.TokenHash)
+	}
+	if !link.HasPassword() || *link.PasswordHash == "hunter2" {
+		t.Errorf("Create() PasswordHash = %v, want bcrypt hash", link.PasswordHash)
+	}
+	if link.MaxDownloads == nil || *link.MaxDownloads != 3 || link.ExpiresAt == nil || link.CreatedBy != "user:alice" {
+		t.Errorf("Create() link = %+v, want 3 downloads, expiry and creator", link)
+	}
+
+	if _, _, err := uc.Create(withPrincipal("user:bob"), "doc-1", usecase.ShareInput{}); !errors.Is(err, entity.ErrDocumentNotFound) {
+		t.Errorf("Create() by stranger error = %v, want %v", err, entity.ErrDocumentNotFound)
+	}
+}
+
+func TestShareDownloadChecksPassword(t *testing.T) {
+	uc, shareRepo, storage := shareFixture(t)
+
+	var saved *entity.ShareLink
+	shareRepo.SaveFunc = func(ctx context.Context, link *entity.ShareLink) error {
+		saved = link
+		return nil
+	}
+	shareRepo.FindByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.ShareLink, error) {
+		if saved == nil || tokenHash != saved.TokenHash {
+			return nil, entity.ErrShareLinkNotFound
+		}
+		return saved, nil
+	}
+	consumed := 0
+	shareRepo.ConsumeDownloadFunc = func(ctx context.Context, id string, now time.Time) error {
+		consumed++
+		return nil
+	}
+	storage.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("content")), nil
+	}
+
+	_, token, err := uc.Create(withPrincipal("user:alice"), "doc-1", usecase.ShareInput{Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("Create() error = %v, want nil", err)
+	}
+
+	for _, password := range []string{"", "wrong"} {
+		if _, _, err := uc.Download(context.Background(), token, password); !errors.Is(err, entity.ErrUnauthorized) {
+			t.Errorf("Download(password %q) error = %v, want %v", password, err, entity.ErrUnauthorized)
+		}
+	}
+	if consumed != 0 {
+		t.Errorf("Download() consumed %d downloads with a wrong password, want 0", consumed)
+	}
+
+	if _, _, err := uc.Download(context.Background(), "unknown", "hunter2"); !errors.Is(err, entity.ErrShareLinkNotFound) {
+		t.Errorf("Download(unknown token) error = %v, want %v", err, entity.ErrShareLinkNotFound)
+	}
+
+	doc, object, err := uc.Download(context.Background(), token, "hunter2")
+	if err != nil {
+		t.Fatalf("Download() error = %v, want nil", err)
+	}
+	defer object.Close()
+	if doc.ID != "doc-1" || consumed != 1 {
+		t.Errorf("Download() doc = %s, consumed = %d, want doc-1 and 1", doc.ID, consumed)
+	}
+}
+
+func TestShareDownloadRejectsUnavailableLinks(t *testing.T) {
+	uc, shareRepo, storage := shareFixture(t)
+
+	past := time.Now().Add(-time.Minute)
+	limit := int64(2)
+	links := map[string]*entity.ShareLink{
+		"expired":   {ID: "l1", DocumentID: "doc-1", ExpiresAt: &past},
+		"revoked":   {ID: "l2", DocumentID: "doc-1", RevokedAt: &past},
+		"exhausted": {ID: "l3", DocumentID: "doc-1", MaxDownloads: &limit, DownloadCount: 2},
+	}
+	var current *entity.ShareLink
+	shareRepo.FindByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.ShareLink, error) {
+		return current, nil
+	}
+	storage.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		t.Errorf("Download() opened the object for an unavailable link")
+		return nil, nil
+	}
+
+	for name, link := range links {
+		current = link
+		if _, _, err := uc.Download(context.Background(), "token", ""); !errors.Is(err, entity.ErrShareLinkUnavailable) {
+			t.Errorf("%s: Download() error = %v, want %v", name, err, entity.ErrShareLinkUnavailable)
+		}
+	}
+}
+
+func TestShareDownloadClosesObjectWhenLimitRaced(t *testing.T) {
+	uc, shareRepo, storage := shareFixture(t)
+
+	limit := int64(1)
+	shareRepo.FindByTokenHashFunc = func(ctx context.Context, tokenHash string) (*entity.ShareLink, error) {
+		return &entity.ShareLink{ID: "l1", DocumentID: "doc-1", MaxDownloads: &limit}, nil
+	}
+	shareRepo.ConsumeDownloadFunc = func(ctx context.Context, id string, now time.Time) error {
+		return entity.ErrShareLinkUnavailable
+	}
+	reader := &trackedReader{Reader: strings.NewReader("content")}
+	storage.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		return reader, nil
+	}
+
+	if _, _, err := uc.Download(context.Background(), "token", ""); !errors.Is(err, entity.ErrShareLinkUnavailable) {
+		t.Errorf("Download() error = %v, want %v", err, entity.ErrShareLinkUnavailable)
+	}
+	if !reader.closed {
+		t.Errorf("Download() left the storage object open")
+	}
+}
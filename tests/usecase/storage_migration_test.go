@@ -0,0 +1,335 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"docvault/entity"
+	"docvault/service"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// objectStorage keeps objects in memory and, unlike memoryStorage, lists them
+// and fails downloads of missing ones.
+func objectStorage(objects map[string][]byte) *mock_test.MockServiceStorage {
+	storage := memoryStorage(objects)
+	storage.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		content, ok := objects[filename]
+		if !ok {
+			return nil, service.ErrObjectNotFound
+		}
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+	storage.ListFunc = func(ctx context.Context, prefix string) ([]service.StorageObject, error) {
+		var listed []service.StorageObject
+		for key, content := range objects {
+			if strings.HasPrefix(key, prefix) {
+				listed = append(listed, service.StorageObject{Key: key, Size: int64(len(content))})
+			}
+		}
+		sort.Slice(listed, func(i, j int) bool { return listed[i].Key < listed[j].Key })
+		return listed, nil
+	}
+
+	return storage
+}
+
+// memoryMigrationRepo keeps the latest migration and its items in memory.
+func memoryMigrationRepo(latest *entity.StorageMigration, items ...*entity.StorageMigrationItem) *mock_test.MockStorageMigrationRepository {
+	byKey := map[string]*entity.StorageMigrationItem{}
+	for _, item := range items {
+		byKey[item.Key] = item
+	}
+
+	repo := &mock_test.MockStorageMigrationRepository{}
+	repo.SaveFunc = func(ctx context.Context, migration *entity.StorageMigration) error {
+		latest = migration
+		return nil
+	}
+	repo.UpdateFunc = func(ctx context.Context, migration *entity.StorageMigration) error {
+		latest = migration
+		return nil
+	}
+	repo.FindLatestFunc = func(ctx context.Context) (*entity.StorageMigration, error) {
+		if latest == nil {
+			return nil, entity.ErrStorageMigrationNotFound
+		}
+		return latest, nil
+	}
+	repo.SaveItemFunc = func(ctx context.Context, item *entity.StorageMigrationItem) error {
+		byKey[item.Key] = item
+		return nil
+	}
+	repo.FindItemsFunc = func(ctx context.Context, migrationID string) ([]*entity.StorageMigrationItem, error) {
+		var found []*entity.StorageMigrationItem
+		for _, item := range byKey {
+			found = append(found, item)
+		}
+		return found, nil
+	}
+
+	return repo
+}
+
+// runStorageMigration runs one storage_migration job to target and returns
+// it as finished.
+func runStorageMigration(t *testing.T, migrations *usecase.StorageMigrationUsecase, target string) *entity.Job {
+	t.Helper()
+
+	var finished *entity.Job
+	jobRepo := &mock_test.MockJobRepository{}
+	jobRepo.ClaimFunc = func(ctx context.Context, now time.Time) (*entity.Job, error) {
+		return &entity.Job{ID: "job-1", Kind: usecase.JobKindStorageMigration, Status: entity.JobStatusRunning, Params: `{"target":"` + target + `"}`}, nil
+	}
+	jobRepo.FinishFunc = func(ctx context.Context, job *entity.Job) error {
+		finished = job
+		return nil
+	}
+
+	jobs := usecase.NewJobUsecase(jobRepo, &mock_test.MockServiceStorage{}, time.Hour)
+	migrations.RegisterJobs(jobs)
+	if ran, err := jobs.RunNext(context.Background()); !ran || err != nil {
+		t.Fatalf("RunNext() = %v, %v, want true, nil", ran, err)
+	}
+	if finished == nil {
+		t.Fatal("RunNext() did not finish the job")
+	}
+
+	return finished
+}
+
+func TestStorageMigrationCopiesAndSwitchesBackend(t *testing.T) {
+	sourceObjects := map[string][]byte{"default/doc-1": []byte("one"), "default/doc-2": []byte("two")}
+	targetObjects := map[string][]byte{"default/doc-2": []byte("two")}
+	source, target := objectStorage(sourceObjects), objectStorage(targetObjects)
+
+	storage := service.NewMigratingStorage(source)
+	repo := memoryMigrationRepo(nil)
+	open := func(url string) (service.StorageService, error) { return target, nil }
+	migrations := usecase.NewStorageMigrationUsecase(repo, storage, open, "minio://old")
+
+	job := runStorageMigration(t, migrations, "file:///srv/new")
+	if job.Status != entity.JobStatusSucceeded {
+		t.Fatalf("job = %s %v, want succeeded", job.Status, job.Error)
+	}
+
+	var result map[string]any
+	json.Unmarshal([]byte(*job.Result), &result)
+	if result["copied"] != 1.0 || result["present"] != 1.0 || result["failed"] != 0.0 {
+		t.Errorf("job result = %v, want 1 copied, 1 present, 0 failed", result)
+	}
+	if string(targetObjects["default/doc-1"]) != "one" {
+		t.Errorf("target doc-1 = %q, want one", targetObjects["default/doc-1"])
+	}
+
+	status, err := migrations.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v, want nil", err)
+	}
+	if status.Active != "file:///srv/new" || status.Migration.Status != entity.StorageMigrationCompleted {
+		t.Errorf("Status() = %s %s, want file:///srv/new completed", status.Active, status.Migration.Status)
+	}
+	for _, item := range status.Items {
+		if item.Key == "default/doc-1" && (item.Status != entity.StorageMigrationItemCopied || item.Checksum == nil) {
+			t.Errorf("item %s = %s, want copied with checksum", item.Key, item.Status)
+		}
+	}
+
+	if active, next := storage.Backends(); active != target || next != nil {
+		t.Error("Backends() after migration, want target active and no move")
+	}
+}
+
+func TestStorageMigrationWaitsForUploadsToTheSource(t *testing.T) {
+	sourceObjects := map[string][]byte{"default/doc-1": []byte("one")}
+	targetObjects := map[string][]byte{}
+	source, target := objectStorage(sourceObjects), objectStorage(targetObjects)
+
+	// The upload picks the source, then stalls until the migration has begun.
+	uploading, release := make(chan struct{}), make(chan struct{})
+	upload := source.UploadFunc
+	source.UploadFunc = func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+		close(uploading)
+		<-release
+		return upload(ctx, filename, fileSize, contentType, file)
+	}
+
+	storage := service.NewMigratingStorage(source)
+	uploaded := make(chan error, 1)
+	go func() {
+		uploaded <- storage.Upload(context.Background(), "default/doc-2", 3, "text/plain", strings.NewReader("two"))
+	}()
+	<-uploading
+
+	open := func(url string) (service.StorageService, error) {
+		time.AfterFunc(50*time.Millisecond, func() { close(release) })
+		return target, nil
+	}
+	migrations := usecase.NewStorageMigrationUsecase(memoryMigrationRepo(nil), storage, open, "minio://old")
+
+	job := runStorageMigration(t, migrations, "file:///srv/new")
+	if job.Status != entity.JobStatusSucceeded {
+		t.Fatalf("job = %s %v, want succeeded", job.Status, job.Error)
+	}
+	if err := <-uploaded; err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+	if string(targetObjects["default/doc-2"]) != "two" {
+		t.Errorf("target doc-2 = %q, want the upload that was running when the migration began", targetObjects["default/doc-2"])
+	}
+}
+
+func TestStorageMigrationRecopiesStaleTargetObjects(t *testing.T) {
+	sourceObjects := map[string][]byte{"default/doc-1": []byte("one"), "default/doc-2": []byte("two"), "default/doc-3": []byte("three")}
+	// doc-1 was cut short by an earlier attempt and doc-2 has other content
+	// of the same size.
+	targetObjects := map[string][]byte{"default/doc-1": []byte("o"), "default/doc-2": []byte("owt"), "default/doc-3": []byte("three")}
+	source, target := objectStorage(sourceObjects), objectStorage(targetObjects)
+
+	repo := memoryMigrationRepo(nil)
+	open := func(url string) (service.StorageService, error) { return target, nil }
+	migrations := usecase.NewStorageMigrationUsecase(repo, service.NewMigratingStorage(source), open, "minio://old")
+
+	job := runStorageMigration(t, migrations, "minio://new")
+	if job.Status != entity.JobStatusSucceeded {
+		t.Fatalf("job = %s %v, want succeeded", job.Status, job.Error)
+	}
+
+	var result map[string]any
+	json.Unmarshal([]byte(*job.Result), &result)
+	if result["copied"] != 2.0 || result["present"] != 1.0 {
+		t.Errorf("job result = %v, want 2 copied, 1 present", result)
+	}
+	for key, content := range sourceObjects {
+		if !bytes.Equal(targetObjects[key], content) {
+			t.Errorf("target %s = %q, want %q", key, targetObjects[key], content)
+		}
+	}
+}
+
+func TestStorageMigrationResumesSkippingCopiedObjects(t *testing.T) {
+	sourceObjects := map[string][]byte{"default/doc-1": []byte("one"), "default/doc-2": []byte("two")}
+	targetObjects := map[string][]byte{"default/doc-1": []byte("one")}
+	source, target := objectStorage(sourceObjects), objectStorage(targetObjects)
+	source.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		if filename == "default/doc-1" {
+			t.Errorf("Download(%s), want copied object skipped", filename)
+		}
+		return io.NopCloser(bytes.NewReader(sourceObjects[filename])), nil
+	}
+
+	unfinished := &entity.StorageMigration{ID: "migration-1", Source: "minio://old", Target: "minio://new", Status: entity.StorageMigrationCopying}
+	repo := memoryMigrationRepo(unfinished, &entity.StorageMigrationItem{MigrationID: "migration-1", Key: "default/doc-1", Status: entity.StorageMigrationItemCopied})
+
+	storage := service.NewMigratingStorage(source)
+	open := func(url string) (service.StorageService, error) { return target, nil }
+	migrations := usecase.NewStorageMigrationUsecase(repo, storage, open, "minio://old")
+	if err := migrations.Restore(context.Background()); err != nil {
+		t.Fatalf("Restore() error = %v, want nil", err)
+	}
+
+	// Until the copy finishes, reads fall back to the old backend.
+	object, err := storage.Download(context.Background(), "default/doc-2")
+	if err != nil {
+		t.Fatalf("Download() during move error = %v, want nil", err)
+	}
+	if content, _ := io.ReadAll(object); string(content) != "two" {
+		t.Errorf("Download() during move = %q, want two", content)
+	}
+
+	job := runStorageMigration(t, migrations, "minio://new")
+	if job.Status != entity.JobStatusSucceeded {
+		t.Fatalf("job = %s %v, want succeeded", job.Status, job.Error)
+	}
+	if unfinished.Status != entity.StorageMigrationCompleted || string(targetObjects["default/doc-2"]) != "two" {
+		t.Errorf("migration = %s, target doc-2 = %q, want completed with two", unfinished.Status, targetObjects["default/doc-2"])
+	}
+}
+
+func TestStorageMigrationFailsOnChecksumMismatch(t *testing.T) {
+	sourceObjects := map[string][]byte{"default/doc-1": []byte("one")}
+	targetObjects := map[string][]byte{}
+	source, target := objectStorage(sourceObjects), objectStorage(targetObjects)
+	target.UploadFunc = func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+		io.Copy(io.Discard, file)
+		targetObjects[filename] = []byte("corrupted")
+		return nil
+	}
+
+	storage := service.NewMigratingStorage(source)
+	repo := memoryMigrationRepo(nil)
+	open := func(url string) (service.StorageService, error) { return target, nil }
+	migrations := usecase.NewStorageMigrationUsecase(repo, storage, open, "minio://old")
+
+	job := runStorageMigration(t, migrations, "minio://new")
+	if job.Status != entity.JobStatusFailed {
+		t.Fatalf("job = %s, want failed", job.Status)
+	}
+	if _, ok := targetObjects["default/doc-1"]; ok {
+		t.Error("target kept the bad copy, want it removed")
+	}
+
+	status, _ := migrations.Status(context.Background())
+	if status.Active != "minio://old" || status.Migration.Status != entity.StorageMigrationCopying {
+		t.Errorf("Status() = %s %s, want minio://old still copying", status.Active, status.Migration.Status)
+	}
+	if len(status.Items) != 1 || status.Items[0].Status != entity.StorageMigrationItemFailed || status.Items[0].Error == nil {
+		t.Errorf("Status() items = %+v, want one failed item with an error", status.Items)
+	}
+	if active, next := storage.Backends(); active != source || next != target {
+		t.Error("Backends() after failed run, want the move still in progress")
+	}
+}
+
+func TestSubmitStorageMigrationRequiresPlatformAdmin(t *testing.T) {
+	open := func(url string) (service.StorageService, error) { return &mock_test.MockServiceStorage{}, nil }
+	migrations := usecase.NewStorageMigrationUsecase(memoryMigrationRepo(nil), service.NewMigratingStorage(&mock_test.MockServiceStorage{}), open, "minio://old")
+	jobs := usecase.NewJobUsecase(&mock_test.MockJobRepository{}, &mock_test.MockServiceStorage{}, time.Hour)
+	migrations.RegisterJobs(jobs)
+
+	tenantAdmin := entity.WithPrincipal(context.Background(), &entity.Principal{ID: "user:alice", TenantID: "acme", Permissions: []string{entity.PermissionAdmin}})
+	if _, err := jobs.Submit(tenantAdmin, usecase.JobKindStorageMigration, json.RawMessage(`{"target":"minio://new"}`)); !errors.Is(err, entity.ErrForbidden) {
+		t.Errorf("Submit() by tenant admin error = %v, want %v", err, entity.ErrForbidden)
+	}
+
+	platformAdmin := entity.WithPrincipal(context.Background(), &entity.Principal{ID: "user:root", Permissions: []string{entity.PermissionAdmin}})
+	if _, err := jobs.Submit(platformAdmin, usecase.JobKindStorageMigration, json.RawMessage(`{"target":"minio://old"}`)); !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Submit() to the active backend error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+	if _, err := jobs.Submit(platformAdmin, usecase.JobKindStorageMigration, json.RawMessage(`{"target":"minio://new"}`)); err != nil {
+		t.Errorf("Submit() error = %v, want nil", err)
+	}
+}
+
+func TestStorageMigrationRejectsReplicatedStorage(t *testing.T) {
+	replicated, err := service.NewReplicatedStorage(objectStorage(map[string][]byte{}), objectStorage(map[string][]byte{}), service.ReplicationSync)
+	if err != nil {
+		t.Fatalf("NewReplicatedStorage() error = %v, want nil", err)
+	}
+	storage := service.NewMigratingStorage(replicated)
+	open := func(url string) (service.StorageService, error) { return objectStorage(map[string][]byte{}), nil }
+	jobs := usecase.NewJobUsecase(&mock_test.MockJobRepository{}, &mock_test.MockServiceStorage{}, time.Hour)
+
+	migrations := usecase.NewStorageMigrationUsecase(memoryMigrationRepo(nil), storage, open, "minio://old")
+	migrations.RegisterJobs(jobs)
+	platformAdmin := entity.WithPrincipal(context.Background(), &entity.Principal{ID: "user:root", Permissions: []string{entity.PermissionAdmin}})
+	if _, err := jobs.Submit(platformAdmin, usecase.JobKindStorageMigration, json.RawMessage(`{"target":"minio://new"}`)); !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Submit() with replicated storage error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+
+	unfinished := &entity.StorageMigration{ID: "migration-1", Source: "minio://old", Target: "minio://new", Status: entity.StorageMigrationCopying}
+	migrations = usecase.NewStorageMigrationUsecase(memoryMigrationRepo(unfinished), storage, open, "minio://old")
+	if err := migrations.Restore(context.Background()); err == nil {
+		t.Error("Restore() of a migration with replicated storage error = nil, want an error")
+	}
+	if active, next := storage.Backends(); active != replicated || next != nil {
+		t.Error("Backends() after Restore(), want the replicated storage active and no move")
+	}
+}
@@ -0,0 +1,130 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func tenantRepo(tenants ...*entity.Tenant) *mock_test.MockTenantRepository {
+	repo := &mock_test.MockTenantRepository{}
+	repo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Tenant, error) {
+		for _, tenant := range tenants {
+			if tenant.ID == id {
+				return tenant, nil
+			}
+		}
+		return nil, entity.ErrTenantNotFound
+	}
+	repo.FindAllFunc = func(ctx context.Context) ([]*entity.Tenant, error) {
+		return tenants, nil
+	}
+
+	return repo
+}
+
+func TestUploadPrefixesStorageKeyWithTenant(t *testing.T) {
+	var storedKey string
+	storage := &mock_test.MockServiceStorage{}
+	storage.UploadFunc = func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+		storedKey = filename
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, storage, &mock_test.MockServiceQueue{},
+		usecase.WithTenants(tenantRepo(&entity.Tenant{ID: "acme", Name: "Acme"})))
+
+	doc, err := uc.Upload(entity.WithTenant(context.Background(), "acme"), usecase.UploadInput{FileName: "a.txt", FileSize: 4, File: strings.NewReader("data")})
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+
+	if doc.TenantID != "acme" || storedKey != "acme/"+doc.ID {
+		t.Errorf("Upload() TenantID = %s, storage key = %s, want acme and acme/%s", doc.TenantID, storedKey, doc.ID)
+	}
+}
+
+func TestGetMetadataHidesOtherTenantsDocuments(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, TenantID: "acme"}, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+
+	if _, err := uc.GetMetadata(entity.WithTenant(context.Background(), "acme"), "doc-1"); err != nil {
+		t.Errorf("GetMetadata() in own tenant error = %v, want nil", err)
+	}
+
+	if _, err := uc.GetMetadata(entity.WithTenant(context.Background(), "globex"), "doc-1"); !errors.Is(err, entity.ErrDocumentNotFound) {
+		t.Errorf("GetMetadata() across tenants error = %v, want %v", err, entity.ErrDocumentNotFound)
+	}
+}
+
+func TestListScopesFilterToTenant(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindAllFunc = func(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+		if filter.TenantID != "acme" {
+			t.Errorf("List() filter.TenantID = %q, want acme", filter.TenantID)
+		}
+		return []*entity.Document{}, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+
+	if _, err := uc.List(entity.WithTenant(context.Background(), "acme"), entity.DocumentFilter{TenantID: "globex"}); err != nil {
+		t.Errorf("List() error = %v, want nil", err)
+	}
+}
+
+func TestUploadAppliesTenantDefaultRetention(t *testing.T) {
+	uc := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{},
+		usecase.WithTenants(tenantRepo(&entity.Tenant{ID: "acme", Name: "Acme", DefaultRetention: durationPtr(time.Hour)})))
+
+	doc, err := uc.Upload(entity.WithTenant(context.Background(), "acme"), usecase.UploadInput{FileName: "a.txt", FileSize: 4, File: strings.NewReader("data")})
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+
+	if doc.ExpiresAt == nil || !doc.ExpiresAt.Equal(doc.CreatedAt.Add(time.Hour)) {
+		t.Errorf("Upload() ExpiresAt = %v, want created_at + 1h", doc.ExpiresAt)
+	}
+}
+
+func TestPinnedCallerCreatesKeysOnlyForOwnTenant(t *testing.T) {
+	repo, _ := storedKeyRepo()
+	uc := usecase.NewAPIKeyUsecase(repo)
+	ctx := entity.WithPrincipal(context.Background(), &entity.Principal{ID: "api_key:1", TenantID: "acme", Permissions: []string{entity.PermissionAdmin}})
+
+	key, _, err := uc.Create(ctx, "ci", []string{"documents:read"}, 0, "")
+	if err != nil {
+		t.Fatalf("Create() error = %v, want nil", err)
+	}
+	if key.TenantID != "acme" {
+		t.Errorf("Create() TenantID = %q, want acme", key.TenantID)
+	}
+
+	if _, _, err := uc.Create(ctx, "ci", []string{"documents:read"}, 0, "globex"); !errors.Is(err, entity.ErrForbidden) {
+		t.Errorf("Create() for another tenant error = %v, want %v", err, entity.ErrForbidden)
+	}
+}
+
+func TestDeleteDefaultTenantRejected(t *testing.T) {
+	repo := tenantRepo()
+	repo.DeleteFunc = func(ctx context.Context, id string) error {
+		t.Errorf("Delete() removed the default tenant")
+		return nil
+	}
+
+	uc := usecase.NewTenantUsecase(repo, nil)
+
+	if err := uc.Delete(context.Background(), entity.DefaultTenantID); !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Delete() error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+}
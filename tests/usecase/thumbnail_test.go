@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"docvault/entity"
+	"docvault/service"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestGenerateThumbnailsStoresDerivedObjects(t *testing.T) {
+	var img bytes.Buffer
+	png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 300, 150)))
+	objects := map[string][]byte{"acme/doc-1": img.Bytes()}
+
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, TenantID: "acme", StorageKey: "acme/" + id, ContentType: "image/png"}, nil
+	}
+	var saved []*entity.Thumbnail
+	thumbnailRepo := &mock_test.MockThumbnailRepository{}
+	thumbnailRepo.SaveFunc = func(ctx context.Context, thumbnail *entity.Thumbnail) error {
+		saved = append(saved, thumbnail)
+		return nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, memoryStorage(objects), &mock_test.MockServiceQueue{},
+		usecase.WithThumbnails(thumbnailRepo, service.NewImageThumbnailer(), []int{64, 128}))
+
+	if err := uc.GenerateThumbnails(context.Background(), "doc-1"); err != nil {
+		t.Fatalf("GenerateThumbnails() error = %v, want nil", err)
+	}
+
+	if len(saved) != 2 || saved[0].StorageKey != "thumbnails/acme/doc-1/64" || saved[0].Width != 64 || saved[0].Height != 32 {
+		t.Fatalf("GenerateThumbnails() saved = %+v, want 64x32 at thumbnails/acme/doc-1/64 and a 128 thumbnail", saved)
+	}
+	if objects["thumbnails/acme/doc-1/128"] == nil {
+		t.Errorf("GenerateThumbnails() did not store thumbnails/acme/doc-1/128")
+	}
+}
+
+func TestGenerateThumbnailsSkipsNonImages(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, StorageKey: "default/" + id, ContentType: "application/pdf"}, nil
+	}
+	storage := &mock_test.MockServiceStorage{}
+	storage.DownloadFunc = func(ctx context.Context, filename string) (io.ReadCloser, error) {
+		t.Errorf("GenerateThumbnails() downloaded a PDF")
+		return nil, errors.New("unexpected download")
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{},
+		usecase.WithThumbnails(&mock_test.MockThumbnailRepository{}, service.NewImageThumbnailer(), []int{64}))
+
+	if err := uc.GenerateThumbnails(context.Background(), "doc-1"); err != nil {
+		t.Errorf("GenerateThumbnails() error = %v, want nil", err)
+	}
+}
+
+func TestThumbnailRejectsUnconfiguredSize(t *testing.T) {
+	uc := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{},
+		usecase.WithThumbnails(&mock_test.MockThumbnailRepository{}, service.NewImageThumbnailer(), []int{64, 128}))
+
+	if _, _, err := uc.Thumbnail(context.Background(), "doc-1", 100); !errors.Is(err, entity.ErrInvalidInput) {
+		t.Errorf("Thumbnail() error = %v, want %v", err, entity.ErrInvalidInput)
+	}
+}
+
+func TestPurgeRemovesThumbnails(t *testing.T) {
+	objects := map[string][]byte{"default/doc-1": {1}, "thumbnails/default/doc-1/64": {2}}
+	now := time.Now()
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.FindByIdFunc = func(ctx context.Context, id string) (*entity.Document, error) {
+		return &entity.Document{ID: id, TenantID: entity.DefaultTenantID, StorageKey: "default/" + id, DeletedAt: &now}, nil
+	}
+	thumbnailRepo := &mock_test.MockThumbnailRepository{}
+	thumbnailRepo.FindByDocumentFunc = func(ctx context.Context, documentID string) ([]*entity.Thumbnail, error) {
+		return []*entity.Thumbnail{{DocumentID: documentID, Size: 64, StorageKey: "thumbnails/default/doc-1/64"}}, nil
+	}
+
+	uc := usecase.NewDocumentUsecase(docRepo, memoryStorage(objects), &mock_test.MockServiceQueue{},
+		usecase.WithThumbnails(thumbnailRepo, service.NewImageThumbnailer(), []int{64}))
+
+	if err := uc.Purge(context.Background(), "doc-1"); err != nil {
+		t.Fatalf("Purge() error = %v, want nil", err)
+	}
+	if len(objects) != 0 {
+		t.Errorf("Purge() left objects %v, want none", objects)
+	}
+}
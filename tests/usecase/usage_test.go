@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"docvault/entity"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestUploadRejectsOverUserQuotaBeforeStoring(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.SaveFunc = func(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error {
+		t.Errorf("Upload() saved a document over quota")
+		return nil
+	}
+
+	storage := &mock_test.MockServiceStorage{}
+	storage.UploadFunc = func(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader) error {
+		t.Errorf("Upload() stored content over quota")
+		return nil
+	}
+
+	usageRepo := &mock_test.MockUsageRepository{}
+	usageRepo.FindFunc = func(ctx context.Context, scope, subjectID string) (*entity.Usage, error) {
+		if scope == entity.UsageScopeUser {
+			return &entity.Usage{Scope: scope, SubjectID: subjectID, Bytes: 8, Documents: 1}, nil
+		}
+		return &entity.Usage{Scope: scope, SubjectID: subjectID}, nil
+	}
+
+	tenant := &entity.Tenant{ID: entity.DefaultTenantID, Name: "Default", QuotaBytes: int64Ptr(100), UserQuotaBytes: int64Ptr(10)}
+	uc := usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{}, usecase.WithTenants(tenantRepo(tenant)), usecase.WithUsage(usageRepo))
+
+	_, err := uc.Upload(withPrincipal("user:alice"), usecase.UploadInput{FileName: "a.txt", FileSize: 4, File: strings.NewReader("data")})
+
+	var quotaErr *entity.QuotaError
+	if !errors.Is(err, entity.ErrQuotaExceeded) || !errors.As(err, &quotaErr) {
+		t.Fatalf("Upload() error = %v, want %v", err, entity.ErrQuotaExceeded)
+	}
+	if quotaErr.Quota.Scope != entity.UsageScopeUser || quotaErr.Quota.SubjectID != "user:alice" || quotaErr.Used != 8 || quotaErr.Limit != 10 {
+		t.Errorf("Upload() quota error = %+v, want user:alice using 8 of 10 bytes", quotaErr)
+	}
+}
+
+func TestUploadPassesQuotasToSave(t *testing.T) {
+	var saved []entity.Quota
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.SaveFunc = func(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error {
+		saved = quotas
+		return nil
+	}
+
+	tenant := &entity.Tenant{ID: entity.DefaultTenantID, Name: "Default", QuotaDocuments: int64Ptr(5), UserQuotaBytes: int64Ptr(10)}
+	uc := usecase.NewDocumentUsecase(docRepo, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{}, usecase.WithTenants(tenantRepo(tenant)))
+
+	if _, err := uc.Upload(withPrincipal("user:alice"), usecase.UploadInput{FileName: "a.txt", FileSize: 4, File: strings.NewReader("data")}); err != nil {
+		t.Fatalf("Upload() error = %v, want nil", err)
+	}
+
+	if len(saved) != 2 || saved[0].SubjectID != entity.DefaultTenantID || *saved[0].MaxDocuments != 5 ||
+		saved[1].SubjectID != "user:alice" || *saved[1].MaxBytes != 10 {
+		t.Errorf("Save() quotas = %+v, want tenant and user quotas", saved)
+	}
+}
+
+func TestUploadRemovesObjectWhenQuotaRaced(t *testing.T) {
+	docRepo := &mock_test.MockDocumentRepository{}
+	docRepo.SaveFunc = func(ctx context.Context, doc *entity.Document, quotas []entity.Quota) error {
+		return &entity.QuotaError{Quota: quotas[0], Resource: "bytes", Limit: 10, Used: 10, Requested: doc.FileSize}
+	}
+
+	var deleted string
+	storage := &mock_test.MockServiceStorage{}
+	storage.DeleteFunc = func(ctx context.Context, filename string) error {
+		deleted = filename
+		return nil
+	}
+
+	tenant := &entity.Tenant{ID: entity.DefaultTenantID, Name: "Default", QuotaBytes: int64Ptr(10)}
+	uc := usecase.NewDocumentUsecase(docRepo, storage, &mock_test.MockServiceQueue{}, usecase.WithTenants(tenantRepo(tenant)), usecase.WithUsage(&mock_test.MockUsageRepository{}))
+
+	doc, err := uc.Upload(context.Background(), usecase.UploadInput{FileName: "a.txt", FileSize: 4, File: strings.NewReader("data")})
+	if !errors.Is(err, entity.ErrQuotaExceeded) {
+		t.Fatalf("Upload() error = %v, want %v", err, entity.ErrQuotaExceeded)
+	}
+	if doc != nil || !strings.HasPrefix(deleted, entity.DefaultTenantID+"/") {
+		t.Errorf("Upload() removed %q, want the stored object", deleted)
+	}
+}
+
+func TestUsageReportsTenantAndCaller(t *testing.T) {
+	usageRepo := &mock_test.MockUsageRepository{}
+	usageRepo.FindFunc = func(ctx context.Context, scope, subjectID string) (*entity.Usage, error) {
+		return &entity.Usage{Scope: scope, SubjectID: subjectID, Bytes: 42, Documents: 3}, nil
+	}
+
+	tenant := &entity.Tenant{ID: entity.DefaultTenantID, Name: "Default", QuotaBytes: int64Ptr(100)}
+	uc := usecase.NewUsageUsecase(usageRepo, tenantRepo(tenant))
+
+	report, err := uc.Get(withPrincipal("user:alice"))
+	if err != nil {
+		t.Fatalf("Get() error = %v, want nil", err)
+	}
+
+	if len(report) != 2 || report[0].Usage.Bytes != 42 || *report[0].Quota.MaxBytes != 100 || report[1].Usage.SubjectID != "user:alice" {
+		t.Errorf("Get() = %+v, want tenant and user usage", report)
+	}
+}
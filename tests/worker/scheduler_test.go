@@ -0,0 +1,33 @@
+package worker_test
+
+import (
+	"context"
+	mock_test "docvault/tests/mock"
+	"docvault/usecase"
+	"docvault/worker"
+	"testing"
+	"time"
+)
+
+func TestSchedulerStartsWithReconcileDisabled(t *testing.T) {
+	docs := usecase.NewDocumentUsecase(&mock_test.MockDocumentRepository{}, &mock_test.MockServiceStorage{}, &mock_test.MockServiceQueue{})
+	usage := usecase.NewUsageUsecase(&mock_test.MockUsageRepository{}, &mock_test.MockTenantRepository{})
+
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		scheduler := worker.NewSchedulerWorker(docs, usage, time.Hour, interval)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			scheduler.Start(ctx)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Errorf("Start() with reconcile interval %v did not return after cancel", interval)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/repository"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// aclSubjectPattern accepts the subject forms principals resolve to.
+var aclSubjectPattern = regexp.MustCompile(`^(user|api_key|group):[^\s]{1,255}$`)
+
+type ACLUsecase struct {
+	repo      repository.DocumentACLRepository
+	documents *DocumentUsecase
+}
+
+func NewACLUsecase(repo repository.DocumentACLRepository, documents *DocumentUsecase) *ACLUsecase {
+	return &ACLUsecase{repo: repo, documents: documents}
+}
+
+// Grant gives subject a permission on a document. Granting an existing entry
+// returns it unchanged.
+func (u *ACLUsecase) Grant(ctx context.Context, documentID, subject, permission string) (entry *entity.ACLEntry, err error) {
+	defer func() {
+		u.documents.audit.Record(ctx, entity.AuditActionACLGrant, documentID, permission+" to "+subject, err)
+	}()
+
+	subject = strings.TrimSpace(subject)
+	if !aclSubjectPattern.MatchString(subject) {
+		return nil, fmt.Errorf("%w: subject must be user:<id>, api_key:<id> or group:<name>", entity.ErrInvalidInput)
+	}
+
+	permission = strings.ToLower(strings.TrimSpace(permission))
+	if !slices.Contains(entity.ACLPermissions, permission) {
+		return nil, fmt.Errorf("%w: permission must be one of %s", entity.ErrInvalidInput, strings.Join(entity.ACLPermissions, ", "))
+	}
+
+	doc, err := u.documents.findManageable(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := u.repo.FindByDocument(ctx, doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list acl entries %w", err)
+	}
+	for _, existing := range entries {
+		if existing.Subject == subject && existing.Permission == permission {
+			return existing, nil
+		}
+	}
+
+	entry = &entity.ACLEntry{
+		ID:         uuid.New().String(),
+		DocumentID: doc.ID,
+		Subject:    subject,
+		Permission: permission,
+		CreatedBy:  actorName(ctx, auditActorSystem),
+		CreatedAt:  time.Now(),
+	}
+
+	if err := u.repo.Save(ctx, entry); err != nil {
+		return nil, fmt.Errorf("Failed to save acl entry %w", err)
+	}
+
+	return entry, nil
+}
+
+func (u *ACLUsecase) List(ctx context.Context, documentID string) ([]*entity.ACLEntry, error) {
+	doc, err := u.documents.findFor(ctx, documentID, entity.ACLPermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := u.repo.FindByDocument(ctx, doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list acl entries %w", err)
+	}
+
+	return entries, nil
+}
+
+func (u *ACLUsecase) Revoke(ctx context.Context, documentID, entryID string) (err error) {
+	defer func() {
+		u.documents.audit.Record(ctx, entity.AuditActionACLRevoke, documentID, "entry "+entryID, err)
+	}()
+
+	if _, err := u.documents.findManageable(ctx, documentID); err != nil {
+		return err
+	}
+
+	if err := u.repo.Delete(ctx, documentID, entryID); err != nil {
+		return fmt.Errorf("Failed to revoke acl entry %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"docvault/entity"
+	"docvault/repository"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyMarker        = "dv_"
+	apiKeyPrefixBytes   = 6
+	apiKeySecretBytes   = 32
+	maxAPIKeyNameLength = 128
+
+	// apiKeyTouchInterval limits last_used_at writes to one per key per interval.
+	apiKeyTouchInterval = time.Minute
+
+	bootstrapKeyName = "bootstrap"
+)
+
+// API keys look like dv_<prefix>_<secret>; the prefix is stored in clear for
+// lookup and only a SHA-256 hash of the secret is kept.
+var apiKeyPattern = regexp.MustCompile(`^dv_([a-z0-9]{8,32})_([A-Za-z0-9]{32,128})$`)
+
+type APIKeyUsecase struct {
+	repo repository.APIKeyRepository
+}
+
+func NewAPIKeyUsecase(repo repository.APIKeyRepository) *APIKeyUsecase {
+	return &APIKeyUsecase{repo: repo}
+}
+
+// Create issues a new key and returns it together with the full secret, which
+// is not stored and cannot be retrieved again. An empty tenantID issues a
+// platform key; callers pinned to a tenant can only issue keys for it.
+func (u *APIKeyUsecase) Create(ctx context.Context, name string, permissions []string, expiresIn time.Duration, tenantID string) (*entity.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return nil, "", fmt.Errorf("%w: name must be 1 to %d characters", entity.ErrInvalidInput, maxAPIKeyNameLength)
+	}
+
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if expiresIn < 0 {
+		return nil, "", fmt.Errorf("%w: expires_in must not be negative", entity.ErrInvalidInput)
+	}
+
+	tenantID, err = keyTenant(ctx, tenantID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	prefix, err := randomHex(apiKeyPrefixBytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := randomHex(apiKeySecretBytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &entity.APIKey{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Prefix:      prefix,
+		SecretHash:  hashSecret(secret),
+		Permissions: permissions,
+		TenantID:    tenantID,
+		CreatedAt:   time.Now(),
+	}
+	if expiresIn > 0 {
+		expiresAt := key.CreatedAt.Add(expiresIn)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := u.repo.Save(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("Failed to save api key %w", err)
+	}
+
+	return key, apiKeyMarker + prefix + "_" + secret, nil
+}
+
+func (u *APIKeyUsecase) List(ctx context.Context) ([]*entity.APIKey, error) {
+	keys, err := u.repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list api keys %w", err)
+	}
+
+	principal, _ := entity.PrincipalFromContext(ctx)
+	if principal == nil || principal.IsPlatform() {
+		return keys, nil
+	}
+
+	visible := []*entity.APIKey{}
+	for _, key := range keys {
+		if key.TenantID == principal.TenantID {
+			visible = append(visible, key)
+		}
+	}
+
+	return visible, nil
+}
+
+func (u *APIKeyUsecase) Revoke(ctx context.Context, id string) error {
+	principal, _ := entity.PrincipalFromContext(ctx)
+	if principal != nil && !principal.IsPlatform() {
+		key, err := u.repo.FindById(ctx, id)
+		if err != nil {
+			return fmt.Errorf("Failed to find api key %w", err)
+		}
+		if key.TenantID != principal.TenantID {
+			return fmt.Errorf("Failed to find api key %w", entity.ErrAPIKeyNotFound)
+		}
+	}
+
+	if err := u.repo.Revoke(ctx, id, time.Now()); err != nil {
+		return fmt.Errorf("Failed to revoke api key %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate resolves an API key to its principal. Credentials that are not
+// shaped like an API key yield (nil, nil) so other authenticators can try them.
+func (u *APIKeyUsecase) Authenticate(ctx context.Context, credential string) (*entity.Principal, error) {
+	if !strings.HasPrefix(credential, apiKeyMarker) {
+		return nil, nil
+	}
+
+	match := apiKeyPattern.FindStringSubmatch(credential)
+	if match == nil {
+		return nil, fmt.Errorf("%w: malformed api key", entity.ErrUnauthorized)
+	}
+
+	key, err := u.repo.FindByPrefix(ctx, match[1])
+	if err != nil {
+		if errors.Is(err, entity.ErrAPIKeyNotFound) {
+			return nil, fmt.Errorf("%w: invalid api key", entity.ErrUnauthorized)
+		}
+		return nil, fmt.Errorf("Failed to find api key %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(match[2])), []byte(key.SecretHash)) != 1 {
+		return nil, fmt.Errorf("%w: invalid api key", entity.ErrUnauthorized)
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, fmt.Errorf("%w: api key is expired or revoked", entity.ErrUnauthorized)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := u.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			fmt.Printf("Failed to record api key use %s: %v\n", key.ID, err)
+		}
+	}
+
+	return key.Principal(), nil
+}
+
+// EnsureBootstrapKey registers a configured admin key so a fresh deployment
+// can create its first keys. It is a no-op once the key exists.
+func (u *APIKeyUsecase) EnsureBootstrapKey(ctx context.Context, raw string) error {
+	match := apiKeyPattern.FindStringSubmatch(raw)
+	if match == nil {
+		return fmt.Errorf("%w: bootstrap key must look like dv_<8-32 lowercase alphanumerics>_<32-128 alphanumerics>", entity.ErrInvalidInput)
+	}
+
+	existing, err := u.repo.FindByPrefix(ctx, match[1])
+	if err == nil {
+		if existing.SecretHash != hashSecret(match[2]) {
+			return fmt.Errorf("%w: bootstrap key prefix is already used by another key", entity.ErrInvalidInput)
+		}
+		return nil
+	}
+	if !errors.Is(err, entity.ErrAPIKeyNotFound) {
+		return fmt.Errorf("Failed to find api key %w", err)
+	}
+
+	key := &entity.APIKey{
+		ID:          uuid.New().String(),
+		Name:        bootstrapKeyName,
+		Prefix:      match[1],
+		SecretHash:  hashSecret(match[2]),
+		Permissions: []string{entity.PermissionAdmin},
+		CreatedAt:   time.Now(),
+	}
+
+	if err := u.repo.Save(ctx, key); err != nil {
+		return fmt.Errorf("Failed to save bootstrap api key %w", err)
+	}
+
+	return nil
+}
+
+// keyTenant decides which tenant a new key is pinned to.
+func keyTenant(ctx context.Context, requested string) (string, error) {
+	requested = strings.TrimSpace(requested)
+	if requested != "" && !tenantIDPattern.MatchString(requested) {
+		return "", fmt.Errorf("%w: invalid tenant_id", entity.ErrInvalidInput)
+	}
+
+	principal, _ := entity.PrincipalFromContext(ctx)
+	if principal == nil || principal.IsPlatform() {
+		return requested, nil
+	}
+
+	if requested != "" && requested != principal.TenantID {
+		return "", fmt.Errorf("%w: keys can only be issued for tenant %s", entity.ErrForbidden, principal.TenantID)
+	}
+
+	return principal.TenantID, nil
+}
+
+func normalizePermissions(permissions []string) ([]string, error) {
+	normalized := []string{}
+	for _, permission := range permissions {
+		permission = strings.ToLower(strings.TrimSpace(permission))
+		if !slices.Contains(entity.Permissions, permission) {
+			return nil, fmt.Errorf("%w: unknown permission %q", entity.ErrInvalidInput, permission)
+		}
+		if !slices.Contains(normalized, permission) {
+			normalized = append(normalized, permission)
+		}
+	}
+
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: at least one permission is required", entity.ErrInvalidInput)
+	}
+
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("Failed to generate random bytes %w", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
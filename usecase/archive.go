@@ -0,0 +1,425 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"docvault/entity"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const archiveManifestName = "manifest.json"
+
+// archiveStoredTypes are already compressed, so deflating them again only
+// costs CPU.
+var archiveStoredTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/zip", "application/gzip", "application/pdf"}
+
+func WithArchiveLimits(maxBytes int64, maxDocuments int) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.archiveMaxBytes = maxBytes
+		u.archiveMaxDocuments = maxDocuments
+	}
+}
+
+// ArchiveInput selects documents either by ID or by filter. With Recursive,
+// a folder filter also takes in its subfolders, which become directories in
+// the archive.
+type ArchiveInput struct {
+	IDs       []string
+	Filter    entity.DocumentFilter
+	Recursive bool
+}
+
+type ArchiveEntry struct {
+	Document *entity.Document
+	// Path is the entry's unique name inside the archive.
+	Path string
+}
+
+// ArchiveSkip is a selected document left out of the archive, such as one
+// that has not passed its malware scan.
+type ArchiveSkip struct {
+	Document *entity.Document
+	Reason   string
+}
+
+type Archive struct {
+	Entries    []ArchiveEntry
+	Skipped    []ArchiveSkip
+	TotalBytes int64
+}
+
+// PrepareArchive resolves and checks everything an archive will contain, so
+// that any error can still be reported before streaming starts.
+func (u *DocumentUsecase) PrepareArchive(ctx context.Context, input ArchiveInput) (*Archive, error) {
+	docs, root, err := u.archiveDocuments(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &Archive{Entries: []ArchiveEntry{}, Skipped: []ArchiveSkip{}}
+	used := map[string]bool{archiveManifestName: true}
+	folders := map[string]*entity.Folder{}
+
+	for _, doc := range docs {
+		err := u.ensureScanned(doc)
+		if err == nil {
+			err = ensureStored(doc)
+		}
+		if err != nil {
+			archive.Skipped = append(archive.Skipped, ArchiveSkip{Document: doc, Reason: err.Error()})
+			continue
+		}
+
+		dir, err := u.archiveDir(ctx, root, doc, folders)
+		if err != nil {
+			return nil, err
+		}
+
+		archive.Entries = append(archive.Entries, ArchiveEntry{Document: doc, Path: uniqueArchivePath(used, dir, doc)})
+		archive.TotalBytes += doc.FileSize
+	}
+
+	if u.archiveMaxBytes > 0 && archive.TotalBytes > u.archiveMaxBytes {
+		return nil, fmt.Errorf("%w: %d bytes selected, at most %d allowed", entity.ErrArchiveTooLarge, archive.TotalBytes, u.archiveMaxBytes)
+	}
+
+	return archive, nil
+}
+
+// archiveDocuments returns the selected documents and, for recursive folder
+// archives, the folder their paths are relative to.
+func (u *DocumentUsecase) archiveDocuments(ctx context.Context, input ArchiveInput) ([]*entity.Document, *entity.Folder, error) {
+	filter := input.Filter
+	hasFilter := selectsDocuments(filter)
+
+	switch {
+	case len(input.IDs) > 0 && hasFilter:
+		return nil, nil, fmt.Errorf("%w: select documents by ids or by filter, not both", entity.ErrInvalidInput)
+	case len(input.IDs) > 0:
+		docs := []*entity.Document{}
+		seen := map[string]bool{}
+		for _, id := range input.IDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if err := u.checkArchiveCount(len(seen)); err != nil {
+				return nil, nil, err
+			}
+
+			doc, err := u.findFor(ctx, id, entity.ACLPermissionRead)
+			if err != nil {
+				return nil, nil, err
+			}
+			docs = append(docs, doc)
+		}
+		return docs, nil, nil
+	case !hasFilter:
+		return nil, nil, fmt.Errorf("%w: ids or a filter is required", entity.ErrInvalidInput)
+	}
+
+	var root *entity.Folder
+	if input.Recursive && filter.FolderID != nil && *filter.FolderID != "" {
+		folderID, err := u.resolveFolder(ctx, *filter.FolderID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if root, err = u.folders.FindById(ctx, *folderID); err != nil {
+			return nil, nil, fmt.Errorf("Failed to find folder %w", err)
+		}
+		filter.FolderID = nil
+		filter.FolderPath = root.Path
+	}
+
+	// One document over the limit is enough to tell the selection is too
+	// large.
+	if u.archiveMaxDocuments > 0 {
+		filter.Limit, filter.Offset = u.archiveMaxDocuments+1, 0
+	}
+	docs, err := u.List(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := u.checkArchiveCount(len(docs)); err != nil {
+		return nil, nil, err
+	}
+
+	return docs, root, nil
+}
+
+func (u *DocumentUsecase) checkArchiveCount(count int) error {
+	if u.archiveMaxDocuments > 0 && count > u.archiveMaxDocuments {
+		return fmt.Errorf("%w: more than %d documents selected", entity.ErrArchiveTooLarge, u.archiveMaxDocuments)
+	}
+
+	return nil
+}
+
+// archiveDir mirrors the folders between root and doc as directories.
+func (u *DocumentUsecase) archiveDir(ctx context.Context, root *entity.Folder, doc *entity.Document, folders map[string]*entity.Folder) (string, error) {
+	if root == nil || doc.FolderID == nil || *doc.FolderID == root.ID {
+		return "", nil
+	}
+
+	folder, ok := folders[*doc.FolderID]
+	if !ok {
+		var err error
+		if folder, err = u.folders.FindById(ctx, *doc.FolderID); err != nil {
+			return "", fmt.Errorf("Failed to find folder %w", err)
+		}
+		folders[folder.ID] = folder
+	}
+
+	var names []string
+	for _, id := range strings.Split(strings.Trim(strings.TrimPrefix(folder.Path, root.Path), "/"), "/") {
+		ancestor, ok := folders[id]
+		if !ok {
+			var err error
+			if ancestor, err = u.folders.FindById(ctx, id); err != nil {
+				return "", fmt.Errorf("Failed to find folder %w", err)
+			}
+			folders[id] = ancestor
+		}
+		names = append(names, archiveName(ancestor.Name, ancestor.ID))
+	}
+
+	return path.Join(names...), nil
+}
+
+// archiveName keeps a name from escaping its directory when extracted.
+func archiveName(name, fallback string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == ".." || name == "/" || strings.TrimSpace(name) == "" {
+		return fallback
+	}
+
+	return name
+}
+
+// uniqueArchivePath numbers names that collide, ignoring case as most file
+// systems do: "a.txt", "a (1).txt", "a (2).txt".
+func uniqueArchivePath(used map[string]bool, dir string, doc *entity.Document) string {
+	name := archiveName(doc.FileName, doc.ID)
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	candidate := path.Join(dir, name)
+	for i := 1; used[strings.ToLower(candidate)]; i++ {
+		candidate = path.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+	}
+	used[strings.ToLower(candidate)] = true
+
+	return candidate
+}
+
+type archiveManifest struct {
+	GeneratedAt time.Time                 `json:"generated_at"`
+	Count       int                       `json:"count"`
+	TotalBytes  int64                     `json:"total_bytes"`
+	Documents   []archiveManifestDocument `json:"documents"`
+	Skipped     []archiveManifestSkip     `json:"skipped"`
+}
+
+type archiveManifestDocument struct {
+	ID          string    `json:"id"`
+	Path        string    `json:"path"`
+	FileName    string    `json:"file_name"`
+	FileSize    int64     `json:"file_size"`
+	ContentType string    `json:"content_type"`
+	FolderID    *string   `json:"folder_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type archiveManifestSkip struct {
+	ID       string `json:"id"`
+	FileName string `json:"file_name"`
+	Reason   string `json:"reason"`
+}
+
+// WriteArchive streams a ZIP of archive to w, manifest first, reading each
+// document straight from storage. A failure part way leaves w truncated.
+func (u *DocumentUsecase) WriteArchive(ctx context.Context, archive *Archive, w io.Writer) error {
+	return u.writeArchive(ctx, archive, w, nil)
+}
+
+// writeArchive calls progress, when set, with the bytes written so far after
+// each document.
+func (u *DocumentUsecase) writeArchive(ctx context.Context, archive *Archive, w io.Writer, progress func(written int64)) error {
+	zw := zip.NewWriter(w)
+
+	if err := writeArchiveManifest(zw, archive); err != nil {
+		return err
+	}
+
+	var written int64
+	for _, entry := range archive.Entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := u.writeArchiveEntry(ctx, zw, entry)
+		u.audit.Record(ctx, entity.AuditActionDownload, entry.Document.ID, "archive", err)
+		if err != nil {
+			return err
+		}
+
+		written += entry.Document.FileSize
+		if progress != nil {
+			progress(written)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("Failed to finish archive %w", err)
+	}
+
+	return nil
+}
+
+func writeArchiveManifest(zw *zip.Writer, archive *Archive) error {
+	manifest := archiveManifest{
+		GeneratedAt: time.Now().UTC(),
+		Count:       len(archive.Entries),
+		TotalBytes:  archive.TotalBytes,
+		Documents:   []archiveManifestDocument{},
+		Skipped:     []archiveManifestSkip{},
+	}
+	for _, entry := range archive.Entries {
+		doc := entry.Document
+		manifest.Documents = append(manifest.Documents, archiveManifestDocument{
+			ID:          doc.ID,
+			Path:        entry.Path,
+			FileName:    doc.FileName,
+			FileSize:    doc.FileSize,
+			ContentType: doc.ContentType,
+			FolderID:    doc.FolderID,
+			CreatedAt:   doc.CreatedAt,
+		})
+	}
+	for _, skip := range archive.Skipped {
+		manifest.Skipped = append(manifest.Skipped, archiveManifestSkip{ID: skip.Document.ID, FileName: skip.Document.FileName, Reason: skip.Reason})
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to marshal archive manifest %w", err)
+	}
+
+	file, err := zw.CreateHeader(&zip.FileHeader{Name: archiveManifestName, Method: zip.Deflate, Modified: manifest.GeneratedAt})
+	if err != nil {
+		return fmt.Errorf("Failed to write archive manifest %w", err)
+	}
+	if _, err := file.Write(manifestJSON); err != nil {
+		return fmt.Errorf("Failed to write archive manifest %w", err)
+	}
+
+	return nil
+}
+
+func (u *DocumentUsecase) writeArchiveEntry(ctx context.Context, zw *zip.Writer, entry ArchiveEntry) error {
+	doc := entry.Document
+
+	object, err := u.storage.Download(ctx, doc.ObjectKey())
+	if err != nil {
+		return fmt.Errorf("Failed to download from storage %w", err)
+	}
+	defer object.Close()
+
+	method := zip.Deflate
+	if slices.Contains(archiveStoredTypes, baseMediaType(doc.ContentType)) {
+		method = zip.Store
+	}
+
+	file, err := zw.CreateHeader(&zip.FileHeader{Name: entry.Path, Method: method, Modified: doc.CreatedAt})
+	if err != nil {
+		return fmt.Errorf("Failed to add %s to archive %w", entry.Path, err)
+	}
+
+	if _, err := io.Copy(file, object); err != nil {
+		return fmt.Errorf("Failed to add %s to archive %w", entry.Path, err)
+	}
+
+	return nil
+}
+
+// archiveJobParams are the parameters of an archive job, selecting documents
+// like an archive download does.
+type archiveJobParams struct {
+	IDs       []string          `json:"ids"`
+	FolderID  *string           `json:"folder_id"`
+	Recursive bool              `json:"recursive"`
+	Tags      []string          `json:"tags"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+func (p archiveJobParams) input() ArchiveInput {
+	return ArchiveInput{
+		IDs:       p.IDs,
+		Filter:    entity.DocumentFilter{FolderID: p.FolderID, Tags: p.Tags, Metadata: p.Metadata},
+		Recursive: p.Recursive,
+	}
+}
+
+func validateArchiveJob(params json.RawMessage) error {
+	var p archiveJobParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return fmt.Errorf("%w: invalid archive params: %v", entity.ErrInvalidInput, err)
+	}
+
+	input := p.input()
+	if len(input.IDs) > 0 == selectsDocuments(input.Filter) {
+		return fmt.Errorf("%w: select documents by ids or by filter", entity.ErrInvalidInput)
+	}
+
+	return nil
+}
+
+// runArchiveJob builds the archive in a temporary file and stores it as the
+// job's artifact, so that it can be downloaded once and at leisure.
+func (u *DocumentUsecase) runArchiveJob(ctx context.Context, job *entity.Job, run *JobRun) error {
+	var p archiveJobParams
+	if err := json.Unmarshal([]byte(job.Params), &p); err != nil {
+		return fmt.Errorf("%w: invalid archive params: %v", entity.ErrInvalidInput, err)
+	}
+
+	archive, err := u.PrepareArchive(ctx, p.input())
+	if err != nil {
+		return err
+	}
+	run.Progress(ctx, 0, archive.TotalBytes)
+
+	spool, err := os.CreateTemp("", "docvault-archive-*.zip")
+	if err != nil {
+		return fmt.Errorf("Failed to create archive file %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	err = u.writeArchive(ctx, archive, spool, func(written int64) {
+		run.Progress(ctx, written, archive.TotalBytes)
+	})
+	if err != nil {
+		return err
+	}
+
+	size, err := spool.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("Failed to read archive file %w", err)
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("Failed to read archive file %w", err)
+	}
+
+	if err := run.StoreArtifact(ctx, "documents.zip", "application/zip", size, spool); err != nil {
+		return err
+	}
+
+	return run.SetResult(map[string]any{"documents": len(archive.Entries), "skipped": len(archive.Skipped), "total_bytes": archive.TotalBytes})
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"docvault/entity"
+	"fmt"
+	"mime"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxTags             = 50
+	maxTagLength        = 64
+	maxMetadataEntries  = 50
+	maxMetadataKeyLen   = 64
+	maxMetadataValueLen = 1024
+	maxFileNameLength   = 255
+)
+
+var (
+	tagPattern         = regexp.MustCompile(`^[a-z0-9][a-z0-9._:-]*$`)
+	metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := []string{}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		if len(tag) > maxTagLength || !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("%w: tag %q must be at most %d characters of a-z, 0-9, '.', '_', ':' or '-'", entity.ErrInvalidInput, tag, maxTagLength)
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxTags {
+		return nil, fmt.Errorf("%w: at most %d tags are allowed", entity.ErrInvalidInput, maxTags)
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func normalizeMetadata(metadata map[string]string) (map[string]string, error) {
+	if len(metadata) > maxMetadataEntries {
+		return nil, fmt.Errorf("%w: at most %d metadata entries are allowed", entity.ErrInvalidInput, maxMetadataEntries)
+	}
+
+	normalized := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		key = strings.TrimSpace(key)
+		if len(key) > maxMetadataKeyLen || !metadataKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("%w: metadata key %q must be at most %d characters of A-Z, a-z, 0-9, '.', '_' or '-'", entity.ErrInvalidInput, key, maxMetadataKeyLen)
+		}
+
+		if len(value) > maxMetadataValueLen {
+			return nil, fmt.Errorf("%w: metadata value for %q exceeds %d characters", entity.ErrInvalidInput, key, maxMetadataValueLen)
+		}
+
+		normalized[key] = value
+	}
+
+	return normalized, nil
+}
+
+func normalizeFileName(fileName string) (string, error) {
+	fileName = strings.TrimSpace(fileName)
+	if fileName == "" || len(fileName) > maxFileNameLength || fileName == "." || fileName == ".." {
+		return "", fmt.Errorf("%w: file_name must be 1-%d characters", entity.ErrInvalidInput, maxFileNameLength)
+	}
+
+	if strings.ContainsAny(fileName, "/\\") || strings.IndexFunc(fileName, unicode.IsControl) >= 0 {
+		return "", fmt.Errorf("%w: file_name must not contain slashes or control characters", entity.ErrInvalidInput)
+	}
+
+	return fileName, nil
+}
+
+func normalizeContentType(contentType string) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.Contains(mediaType, "/") {
+		return "", fmt.Errorf("%w: content_type %q is not a valid media type", entity.ErrInvalidInput, contentType)
+	}
+
+	return mime.FormatMediaType(mediaType, params), nil
+}
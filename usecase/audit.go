@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/repository"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	auditActorSystem    = "system"
+	auditActorAnonymous = "anonymous"
+
+	auditVerifyBatchSize = 500
+)
+
+type AuditUsecase struct {
+	repo repository.AuditRepository
+	// mu serializes appends so every entry links to the one written before it.
+	mu sync.Mutex
+}
+
+func NewAuditUsecase(repo repository.AuditRepository) *AuditUsecase {
+	return &AuditUsecase{repo: repo}
+}
+
+// Record appends an entry for action on documentID, with the outcome taken
+// from err. It never fails the operation being audited; write errors are logged.
+func (u *AuditUsecase) Record(ctx context.Context, action, documentID, detail string, err error) {
+	if u == nil {
+		return
+	}
+
+	entry := &entity.AuditEntry{
+		Action:     action,
+		DocumentID: documentID,
+		Actor:      auditActorSystem,
+		Outcome:    entity.AuditOutcomeSuccess,
+		Detail:     detail,
+	}
+
+	if info, ok := entity.RequestInfoFromContext(ctx); ok {
+		entry.Actor = auditActorAnonymous
+		entry.ClientIP = info.ClientIP
+		entry.UserAgent = info.UserAgent
+		entry.RequestID = info.RequestID
+	}
+
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		entry.Actor = principal.ID
+	}
+
+	if err != nil {
+		entry.Outcome = entity.AuditOutcomeFailure
+		if entry.Detail == "" {
+			entry.Detail = err.Error()
+		}
+	}
+
+	if err := u.append(context.WithoutCancel(ctx), entry); err != nil {
+		fmt.Printf("Failed to write audit entry for %s on %s: %v\n", action, documentID, err)
+	}
+}
+
+func (u *AuditUsecase) append(ctx context.Context, entry *entity.AuditEntry) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	last, err := u.repo.Last(ctx)
+	if err != nil {
+		return err
+	}
+
+	if last != nil {
+		entry.PrevHash = last.Hash
+	}
+	entry.Timestamp = time.Now().UTC()
+	entry.Hash = entry.ComputeHash()
+
+	return u.repo.Append(ctx, entry)
+}
+
+func (u *AuditUsecase) List(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	entries, err := u.repo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list audit entries %w", err)
+	}
+
+	return entries, nil
+}
+
+func (u *AuditUsecase) Count(ctx context.Context, filter entity.AuditFilter) (int64, error) {
+	count, err := u.repo.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count audit entries %w", err)
+	}
+
+	return count, nil
+}
+
+// Verify walks the whole log in order, recomputing every hash and checking
+// that each entry links to its predecessor.
+func (u *AuditUsecase) Verify(ctx context.Context) (*entity.AuditVerification, error) {
+	result := &entity.AuditVerification{Valid: true}
+	prevHash := ""
+	var afterSeq int64
+
+	for {
+		entries, err := u.repo.FindAfter(ctx, afterSeq, auditVerifyBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read audit log %w", err)
+		}
+
+		for _, entry := range entries {
+			reason := ""
+			switch {
+			case entry.PrevHash != prevHash:
+				reason = "previous hash does not match the preceding entry"
+			case entry.ComputeHash() != entry.Hash:
+				reason = "entry hash does not match its content"
+			}
+
+			if reason != "" {
+				seq := entry.Seq
+				result.Valid = false
+				result.BrokenAt = &seq
+				result.Reason = reason
+				return result, nil
+			}
+
+			result.Checked++
+			prevHash = entry.Hash
+			afterSeq = entry.Seq
+		}
+
+		if len(entries) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	BatchActionDelete = "delete"
+	BatchActionTags   = "tags"
+	BatchActionExpiry = "expiry"
+	BatchActionMove   = "move"
+)
+
+const (
+	BatchResultChanged   = "changed"
+	BatchResultUnchanged = "unchanged"
+	BatchResultFailed    = "failed"
+)
+
+// batchMaxDocuments bounds how many documents one batch may touch.
+const batchMaxDocuments = 1000
+
+// BatchInput applies one action to documents selected by ID or by filter.
+type BatchInput struct {
+	Action string
+	IDs    []string
+	Filter entity.DocumentFilter
+
+	AddTags    []string
+	RemoveTags []string
+	// ExpiresAt is applied when SetExpiresAt is true; nil keeps documents forever.
+	ExpiresAt    *time.Time
+	SetExpiresAt bool
+	// FolderID is where documents move to; "" moves them to the root.
+	FolderID *string
+
+	// DryRun reports what would change without changing anything.
+	DryRun bool
+}
+
+type BatchResult struct {
+	DocumentID string
+	Status     string
+	// Changes names the fields that changed, or would change in a dry run.
+	Changes []string
+	Err     error
+}
+
+type BatchReport struct {
+	Action    string
+	DryRun    bool
+	Changed   int
+	Unchanged int
+	Failed    int
+	Results   []BatchResult
+}
+
+// selectsDocuments reports whether filter narrows documents down at all, so
+// that an empty request cannot act on everything the caller can see.
+func selectsDocuments(filter entity.DocumentFilter) bool {
+	return filter.FolderID != nil || len(filter.Tags) > 0 || len(filter.Metadata) > 0
+}
+
+// Batch applies input.Action to every selected document in turn. A document
+// that fails is reported and does not stop the others.
+func (u *DocumentUsecase) Batch(ctx context.Context, input BatchInput) (*BatchReport, error) {
+	folderID, err := u.validateBatch(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := u.batchDocuments(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BatchReport{Action: input.Action, DryRun: input.DryRun, Results: []BatchResult{}}
+	for _, id := range ids {
+		result := u.batchOne(ctx, id, input, folderID)
+		switch result.Status {
+		case BatchResultChanged:
+			report.Changed++
+		case BatchResultUnchanged:
+			report.Unchanged++
+		default:
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+// validateBatch normalizes the action's parameters and returns the resolved
+// folder of a move.
+func (u *DocumentUsecase) validateBatch(ctx context.Context, input *BatchInput) (*string, error) {
+	switch input.Action {
+	case BatchActionDelete:
+	case BatchActionTags:
+		add, err := normalizeTags(input.AddTags)
+		if err != nil {
+			return nil, err
+		}
+		remove, err := normalizeTags(input.RemoveTags)
+		if err != nil {
+			return nil, err
+		}
+		if len(add) == 0 && len(remove) == 0 {
+			return nil, fmt.Errorf("%w: add_tags or remove_tags is required", entity.ErrInvalidInput)
+		}
+		input.AddTags, input.RemoveTags = add, remove
+	case BatchActionExpiry:
+		if !input.SetExpiresAt {
+			return nil, fmt.Errorf("%w: expires_at is required, null keeps documents forever", entity.ErrInvalidInput)
+		}
+		if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+			return nil, fmt.Errorf("%w: expires_at must be in the future", entity.ErrInvalidInput)
+		}
+	case BatchActionMove:
+		if input.FolderID == nil {
+			return nil, fmt.Errorf("%w: folder_id is required, \"\" moves documents to the root", entity.ErrInvalidInput)
+		}
+		return u.resolveFolder(ctx, *input.FolderID)
+	default:
+		return nil, fmt.Errorf("%w: unknown batch action %q", entity.ErrInvalidInput, input.Action)
+	}
+
+	return nil, nil
+}
+
+func (u *DocumentUsecase) batchDocuments(ctx context.Context, input BatchInput) ([]string, error) {
+	var ids []string
+	switch {
+	case len(input.IDs) > 0 && selectsDocuments(input.Filter):
+		return nil, fmt.Errorf("%w: select documents by ids or by filter, not both", entity.ErrInvalidInput)
+	case len(input.IDs) > batchMaxDocuments:
+		return nil, fmt.Errorf("%w: %d ids given, at most %d per batch", entity.ErrInvalidInput, len(input.IDs), batchMaxDocuments)
+	case len(input.IDs) > 0:
+		seen := make(map[string]bool, len(input.IDs))
+		for _, id := range input.IDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	case selectsDocuments(input.Filter):
+		// One document over the limit is enough to reject the batch.
+		filter := input.Filter
+		filter.Limit, filter.Offset = batchMaxDocuments+1, 0
+		docs, err := u.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			ids = append(ids, doc.ID)
+		}
+	default:
+		return nil, fmt.Errorf("%w: ids or a filter is required", entity.ErrInvalidInput)
+	}
+
+	if len(ids) > batchMaxDocuments {
+		return nil, fmt.Errorf("%w: more than %d documents selected per batch", entity.ErrInvalidInput, batchMaxDocuments)
+	}
+
+	return ids, nil
+}
+
+func (u *DocumentUsecase) batchOne(ctx context.Context, id string, input BatchInput, folderID *string) BatchResult {
+	result := BatchResult{DocumentID: id, Status: BatchResultFailed}
+
+	if input.Action == BatchActionDelete {
+		doc, err := u.findFor(ctx, id, entity.ACLPermissionDelete)
+		if err == nil && input.DryRun {
+			err = u.ensureNotHeld(ctx, doc)
+		}
+		if err == nil && !input.DryRun {
+			err = u.Delete(ctx, id)
+		}
+		if err != nil {
+			result.Err = err
+			return result
+		}
+
+		result.Status = BatchResultChanged
+		result.Changes = []string{"deleted_at"}
+		return result
+	}
+
+	doc, err := u.findFor(ctx, id, entity.ACLPermissionWrite)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	update := UpdateInput{ExpectedVersion: &doc.Version}
+	switch input.Action {
+	case BatchActionTags:
+		tags := []string{}
+		for _, tag := range append(slices.Clone(doc.Tags), input.AddTags...) {
+			if !slices.Contains(input.RemoveTags, tag) && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if !sameTags(tags, doc.Tags) {
+			update.Tags = &tags
+			result.Changes = []string{"tags"}
+		}
+	case BatchActionExpiry:
+		unchanged := input.ExpiresAt == nil && doc.RetainForever && doc.RequestedExpiresAt == nil ||
+			input.ExpiresAt != nil && doc.RequestedExpiresAt != nil && input.ExpiresAt.Equal(*doc.RequestedExpiresAt)
+		if !unchanged {
+			update.ExpiresAt, update.SetExpiresAt = input.ExpiresAt, true
+			result.Changes = []string{"expires_at"}
+		}
+	case BatchActionMove:
+		if !sameFolder(doc.FolderID, folderID) {
+			update.FolderID = input.FolderID
+			result.Changes = []string{"folder_id"}
+		}
+	}
+
+	if len(result.Changes) == 0 {
+		result.Status = BatchResultUnchanged
+		return result
+	}
+
+	if !input.DryRun {
+		if _, err := u.Update(ctx, id, update); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	result.Status = BatchResultChanged
+	return result
+}
+
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, tag := range a {
+		if !slices.Contains(b, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sameFolder(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
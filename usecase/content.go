@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"bytes"
+	"docvault/entity"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// sniffLength is how much of an upload is read to detect its type, the most
+// http.DetectContentType considers.
+const sniffLength = 512
+
+const unknownContentType = "application/octet-stream"
+
+// executableSignatures cover formats http.DetectContentType reports as
+// application/octet-stream, so that they can be denied by type.
+var executableSignatures = []struct {
+	magic     []byte
+	mediaType string
+}{
+	{[]byte("MZ"), "application/vnd.microsoft.portable-executable"},
+	{[]byte("\x7fELF"), "application/x-executable"},
+	{[]byte{0xfe, 0xed, 0xfa, 0xce}, "application/x-mach-binary"},
+	{[]byte{0xfe, 0xed, 0xfa, 0xcf}, "application/x-mach-binary"},
+	{[]byte{0xce, 0xfa, 0xed, 0xfe}, "application/x-mach-binary"},
+	{[]byte{0xcf, 0xfa, 0xed, 0xfe}, "application/x-mach-binary"},
+	{[]byte("#!"), "text/x-shellscript"},
+}
+
+// contentTypeAliases maps alternative spellings to one canonical media type.
+var contentTypeAliases = map[string]string{
+	"application/x-gzip":           "application/gzip",
+	"application/x-zip-compressed": "application/zip",
+	"audio/wave":                   "audio/wav",
+	"audio/x-wav":                  "audio/wav",
+	"image/x-icon":                 "image/vnd.microsoft.icon",
+	"text/xml":                     "application/xml",
+	"application/x-msdownload":     "application/vnd.microsoft.portable-executable",
+}
+
+func WithUploadPolicy(policy entity.UploadPolicy) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.uploadPolicy = policy
+	}
+}
+
+// sniffContent detects the type of file from its first bytes and returns a
+// reader that still yields the whole file.
+func sniffContent(file io.Reader) (string, io.Reader, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", nil, fmt.Errorf("Failed to read upload %w", err)
+	}
+	head = head[:n]
+
+	return detectContentType(head), io.MultiReader(bytes.NewReader(head), file), nil
+}
+
+func detectContentType(head []byte) string {
+	for _, signature := range executableSignatures {
+		if bytes.HasPrefix(head, signature.magic) {
+			return signature.mediaType
+		}
+	}
+
+	if len(head) == 0 {
+		return unknownContentType
+	}
+
+	return baseMediaType(http.DetectContentType(head))
+}
+
+// baseMediaType drops parameters such as charset and canonicalises aliases.
+func baseMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	if canonical, ok := contentTypeAliases[mediaType]; ok {
+		return canonical
+	}
+
+	return mediaType
+}
+
+// resolveContentType applies the upload policy and returns the type to store.
+// detected is empty for documents uploaded before content was sniffed.
+func (u *DocumentUsecase) resolveContentType(fileName, declared, detected string, size int64) (string, error) {
+	policy := u.uploadPolicy
+
+	if !policy.AllowsExtension(fileName) {
+		return "", fmt.Errorf("%w: extension of %q", entity.ErrUnsupportedContent, fileName)
+	}
+
+	contentType := declared
+	declaredType := baseMediaType(declared)
+	switch {
+	case declaredType == "" || declaredType == unknownContentType:
+		contentType = detected
+	case compatibleContentTypes(declaredType, detected):
+	case policy.OnMismatch == entity.ContentMismatchReject:
+		return "", fmt.Errorf("%w: declared %s but content is %s", entity.ErrUnsupportedContent, declaredType, detected)
+	case policy.OnMismatch == entity.ContentMismatchCorrect:
+		contentType = detected
+	}
+
+	// Both types must pass, so a denied type cannot be smuggled in under a
+	// compatible label or the other way round.
+	for _, mediaType := range []string{declaredType, detected} {
+		if mediaType != "" && !policy.AllowsType(mediaType) {
+			return "", fmt.Errorf("%w: %s", entity.ErrUnsupportedContent, mediaType)
+		}
+	}
+
+	effective := baseMediaType(contentType)
+	if limit, ok := policy.SizeLimit(effective); ok && size > limit {
+		return "", fmt.Errorf("%w: %s files may be at most %d bytes", entity.ErrFileTooLarge, effective, limit)
+	}
+
+	return contentType, nil
+}
+
+// compatibleContentTypes reports whether the declared type is a plausible
+// label for content detected as detected. Sniffing cannot tell formats built
+// on zip or plain text apart, and unknown content contradicts nothing.
+func compatibleContentTypes(declared, detected string) bool {
+	switch {
+	case declared == detected || detected == "" || detected == unknownContentType:
+		return true
+	case detected == "application/zip":
+		return isZipBased(declared)
+	case strings.HasPrefix(detected, "text/") || detected == "application/xml" || detected == "application/json":
+		return isTextual(declared)
+	}
+
+	return false
+}
+
+func isZipBased(mediaType string) bool {
+	return strings.HasSuffix(mediaType, "+zip") ||
+		strings.HasPrefix(mediaType, "application/vnd.openxmlformats-officedocument.") ||
+		strings.HasPrefix(mediaType, "application/vnd.oasis.opendocument.") ||
+		mediaType == "application/java-archive" ||
+		mediaType == "application/epub+zip" ||
+		mediaType == "application/vnd.android.package-archive"
+}
+
+func isTextual(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") ||
+		mediaType == "application/json" ||
+		mediaType == "application/xml" ||
+		mediaType == "application/javascript" ||
+		mediaType == "application/x-yaml" ||
+		mediaType == "application/yaml" ||
+		mediaType == "application/sql"
+}
@@ -8,63 +8,243 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type DocumentUsecase struct {
-	repo    repository.DocumentRepository
-	storage service.StorageService
-	queue   service.QueueService
+	repo     repository.DocumentRepository
+	storage  service.StorageService
+	queue    service.QueueService
+	folders  repository.FolderRepository
+	policies repository.RetentionPolicyRepository
+	holds    repository.LegalHoldRepository
+	acl      repository.DocumentACLRepository
+	tenants  repository.TenantRepository
+	usage    repository.UsageRepository
+	scanner  service.Scanner
+	audit    *AuditUsecase
+
+	uploadPolicy entity.UploadPolicy
+
+	thumbnails     repository.ThumbnailRepository
+	thumbnailer    service.Thumbnailer
+	thumbnailSizes []int
+
+	archiveMaxBytes     int64
+	archiveMaxDocuments int
 }
 
-func NewDocumentUsecase(repo repository.DocumentRepository, storage service.StorageService, queue service.QueueService) *DocumentUsecase {
-	return &DocumentUsecase{repo: repo, storage: storage, queue: queue}
+type DocumentOption func(*DocumentUsecase)
+
+func WithFolders(folders repository.FolderRepository) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.folders = folders
+	}
+}
+
+func WithRetentionPolicies(policies repository.RetentionPolicyRepository) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.policies = policies
+	}
+}
+
+func WithLegalHolds(holds repository.LegalHoldRepository) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.holds = holds
+	}
+}
+
+func WithACL(acl repository.DocumentACLRepository) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.acl = acl
+	}
+}
+
+func WithTenants(tenants repository.TenantRepository) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.tenants = tenants
+	}
+}
+
+func WithUsage(usage repository.UsageRepository) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.usage = usage
+	}
+}
+
+func WithAudit(audit *AuditUsecase) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.audit = audit
+	}
+}
+
+func NewDocumentUsecase(repo repository.DocumentRepository, storage service.StorageService, queue service.QueueService, opts ...DocumentOption) *DocumentUsecase {
+	u := &DocumentUsecase{repo: repo, storage: storage, queue: queue}
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
 }
 
-func (u *DocumentUsecase) Upload(ctx context.Context, filename string, fileSize int64, contentType string, file io.Reader, expiresIn int) (*entity.Document, error) {
+type UploadInput struct {
+	FileName    string
+	FileSize    int64
+	ContentType string
+	File        io.Reader
+	// ExpiresIn requests an expiry in seconds; 0 leaves it to the retention policy.
+	ExpiresIn int
+	Tags      []string
+	Metadata  map[string]string
+	FolderID  string
+}
+
+type UpdateInput struct {
+	FileName    *string
+	ContentType *string
+	// ExpiresAt is applied when SetExpiresAt is true; nil keeps the document forever.
+	ExpiresAt    *time.Time
+	SetExpiresAt bool
+	Tags         *[]string
+	Metadata     *map[string]string
+	// FolderID moves the document; "" moves it to the root.
+	FolderID *string
+	// ExpectedVersion rejects the update when the document has changed since it was read.
+	ExpectedVersion *int64
+}
+
+func (u *DocumentUsecase) Upload(ctx context.Context, input UploadInput) (document *entity.Document, err error) {
 	documentID := uuid.New().String()
-	now := time.Now()
-	expiresAt := now.Add(time.Duration(expiresIn) * time.Second)
+	defer func() {
+		u.audit.Record(ctx, entity.AuditActionUpload, documentID, input.FileName, err)
+	}()
 
-	if err := u.storage.Upload(ctx, filename, fileSize, contentType, file); err != nil {
-		return nil, fmt.Errorf("Failed to upload to storage %w", err)
+	tags, err := normalizeTags(input.Tags)
+	if err != nil {
+		return nil, err
 	}
 
-	document := &entity.Document{
+	metadata, err := normalizeMetadata(input.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	folderID, err := u.resolveFolder(ctx, input.FolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.ExpiresIn < 0 {
+		return nil, fmt.Errorf("%w: expires_in must not be negative", entity.ErrInvalidInput)
+	}
+
+	detectedType, file, err := sniffContent(input.File)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, err := u.resolveContentType(input.FileName, input.ContentType, detectedType, input.FileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID := tenantOf(ctx)
+	var ownerID *string
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		ownerID = &principal.ID
+	}
+
+	quotas, err := u.quotas(ctx, tenantID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.ensureQuota(ctx, quotas, input.FileSize); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	document = &entity.Document{
 		ID:          documentID,
-		FileName:    filename,
-		FileSize:    fileSize,
+		TenantID:    tenantID,
+		StorageKey:  tenantID + "/" + documentID,
+		FileName:    input.FileName,
+		FileSize:    input.FileSize,
 		ContentType: contentType,
-		CreatedAt:   time.Now(),
-		ExpiresAt:   &expiresAt,
+		CreatedAt:   now,
+		Tags:        tags,
+		Metadata:    metadata,
+		FolderID:    folderID,
+		OwnerID:     ownerID,
+
+		DeclaredContentType: input.ContentType,
+		DetectedContentType: detectedType,
 	}
-	if err := u.repo.Save(ctx, document); err != nil {
-		return nil, fmt.Errorf("Failed to save to repository documents %w", err)
+	if input.ExpiresIn > 0 {
+		requested := now.Add(time.Duration(input.ExpiresIn) * time.Second)
+		document.RequestedExpiresAt = &requested
+	}
+	if err := u.applyRetention(ctx, document); err != nil {
+		return nil, err
 	}
 
-	event := map[string]interface{}{
-		"type":        "file.uploaded",
-		"document_id": documentID,
-		"filename":    filename,
-		"timestamp":   time.Now().Format(time.RFC3339),
+	var waitScan func(error) (service.ScanResult, error)
+	if u.scanner != nil {
+		file, waitScan = u.scanWhileUploading(ctx, file)
 	}
 
-	eventJSON, err := json.Marshal(event)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to marshal event %w", err)
+	uploadErr := u.storage.Upload(ctx, document.StorageKey, input.FileSize, contentType, file)
+	var replacedKey string
+	if waitScan != nil {
+		result, scanErr := waitScan(uploadErr)
+		if uploadErr == nil && scanErr == nil {
+			replacedKey, scanErr = u.applyScan(ctx, document, result)
+		}
+		if uploadErr == nil && scanErr != nil {
+			// The document stays pending and ScanPending retries it.
+			fmt.Printf("Failed to scan document %s on upload: %v\n", document.ID, scanErr)
+		}
+	}
+	if uploadErr != nil {
+		return nil, fmt.Errorf("Failed to upload to storage %w", uploadErr)
+	}
+
+	if err := u.repo.Save(ctx, document, quotas); err != nil {
+		// Nothing refers to the stored object without its row, whether the
+		// quota ran out since ensureQuota or the save failed outright.
+		u.removeObject(ctx, document.StorageKey)
+		u.removeObject(ctx, replacedKey)
+		return nil, fmt.Errorf("Failed to save to repository documents %w", err)
 	}
+	u.removeObject(ctx, replacedKey)
 
-	if err := u.queue.Publish(ctx, string(eventJSON)); err != nil {
+	if err := u.publishEvent(ctx, "file.uploaded", document); err != nil {
 		return nil, fmt.Errorf("Failed to publish to queue %w", err)
 	}
 
+	if document.ScanStatus != "" && document.ScanStatus != entity.ScanStatusPending {
+		if err := u.publishScan(ctx, document); err != nil {
+			return nil, fmt.Errorf("Failed to publish to queue %w", err)
+		}
+	}
+
 	return document, nil
 }
 
-func (u *DocumentUsecase) List(ctx context.Context) ([]*entity.Document, error) {
-	doc, err := u.repo.FindAll(ctx)
+func (u *DocumentUsecase) List(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
+		return nil, err
+	}
+	filter.Tags = tags
+	filter.Subjects = visibleTo(ctx)
+	filter.TenantID, _ = entity.TenantFromContext(ctx)
+
+	doc, err := u.repo.FindAll(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to list items from documents %w", err)
 	}
@@ -72,58 +252,620 @@ func (u *DocumentUsecase) List(ctx context.Context) ([]*entity.Document, error)
 	return doc, nil
 }
 
+func (u *DocumentUsecase) Count(ctx context.Context, filter entity.DocumentFilter) (int64, error) {
+	filter.Subjects = visibleTo(ctx)
+	filter.TenantID, _ = entity.TenantFromContext(ctx)
+
+	count, err := u.repo.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count documents %w", err)
+	}
+
+	return count, nil
+}
+
+func (u *DocumentUsecase) Update(ctx context.Context, id string, input UpdateInput) (doc *entity.Document, err error) {
+	var changes []string
+	defer func() {
+		u.audit.Record(ctx, entity.AuditActionUpdate, id, strings.Join(changes, ","), err)
+	}()
+
+	doc, err = u.findFor(ctx, id, entity.ACLPermissionWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.ExpectedVersion != nil && *input.ExpectedVersion != doc.Version {
+		return nil, fmt.Errorf("Failed to update document %w", entity.ErrVersionConflict)
+	}
+
+	if input.FileName != nil {
+		fileName, err := normalizeFileName(*input.FileName)
+		if err != nil {
+			return nil, err
+		}
+		if fileName != doc.FileName {
+			doc.FileName = fileName
+			changes = append(changes, "file_name")
+		}
+	}
+
+	if input.ContentType != nil {
+		contentType, err := normalizeContentType(*input.ContentType)
+		if err != nil {
+			return nil, err
+		}
+		if contentType != doc.ContentType {
+			doc.ContentType = contentType
+			changes = append(changes, "content_type")
+		}
+	}
+
+	// Renaming or relabelling must not get around the upload policy.
+	if slices.Contains(changes, "file_name") || slices.Contains(changes, "content_type") {
+		doc.ContentType, err = u.resolveContentType(doc.FileName, doc.ContentType, doc.DetectedContentType, doc.FileSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if input.SetExpiresAt {
+		if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+			return nil, fmt.Errorf("%w: expires_at must be in the future", entity.ErrInvalidInput)
+		}
+		doc.RequestedExpiresAt = input.ExpiresAt
+		doc.RetainForever = input.ExpiresAt == nil
+		changes = append(changes, "expires_at")
+	}
+
+	if input.Tags != nil {
+		tags, err := normalizeTags(*input.Tags)
+		if err != nil {
+			return nil, err
+		}
+		doc.Tags = tags
+		changes = append(changes, "tags")
+	}
+
+	if input.Metadata != nil {
+		metadata, err := normalizeMetadata(*input.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		doc.Metadata = metadata
+		changes = append(changes, "metadata")
+	}
+
+	if input.FolderID != nil {
+		folderID, err := u.resolveFolder(ctx, *input.FolderID)
+		if err != nil {
+			return nil, err
+		}
+		doc.FolderID = folderID
+		changes = append(changes, "folder_id")
+	}
+
+	if len(changes) == 0 {
+		return doc, nil
+	}
+
+	if err := u.applyRetention(ctx, doc); err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.Update(ctx, doc, doc.Version); err != nil {
+		return nil, fmt.Errorf("Failed to update document %w", err)
+	}
+
+	if err := u.publish(ctx, "file.updated", doc, map[string]interface{}{"changes": changes}); err != nil {
+		return nil, fmt.Errorf("Failed to publish update event %w", err)
+	}
+
+	return doc, nil
+}
+
+// applyRetention selects the retention policy governing doc and derives its
+// effective expiry from it.
+func (u *DocumentUsecase) applyRetention(ctx context.Context, doc *entity.Document) error {
+	rules, err := u.retentionRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	retain(rules, doc)
+
+	return nil
+}
+
+// retentionRules are the retention policies plus each tenant's default
+// retention for documents no policy governs.
+type retentionRules struct {
+	policies       []*entity.RetentionPolicy
+	tenantDefaults map[string]time.Duration
+}
+
+func (u *DocumentUsecase) hasRetentionRules() bool {
+	return u.policies != nil || u.tenants != nil
+}
+
+func (u *DocumentUsecase) retentionRules(ctx context.Context) (*retentionRules, error) {
+	rules := &retentionRules{tenantDefaults: map[string]time.Duration{}}
+
+	if u.policies != nil {
+		policies, err := u.policies.FindAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load retention policies %w", err)
+		}
+		rules.policies = policies
+	}
+
+	if u.tenants != nil {
+		tenants, err := u.tenants.FindAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load tenants %w", err)
+		}
+		for _, tenant := range tenants {
+			if tenant.DefaultRetention != nil {
+				rules.tenantDefaults[tenant.ID] = *tenant.DefaultRetention
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// retain applies the matching policy, or else the tenant default, to doc and
+// reports whether its policy or expiry changed.
+func retain(rules *retentionRules, doc *entity.Document) bool {
+	previousPolicy, previousExpiry := doc.RetentionPolicyID, doc.ExpiresAt
+
+	policy := entity.SelectRetentionPolicy(rules.policies, doc)
+	doc.RetentionPolicyID = nil
+	if policy != nil {
+		doc.RetentionPolicyID = &policy.ID
+	}
+	doc.ExpiresAt = policy.ExpiresAt(doc)
+
+	if defaultRetention, ok := rules.tenantDefaults[doc.TenantID]; ok && policy == nil && doc.ExpiresAt == nil && !doc.RetainForever {
+		expiresAt := doc.CreatedAt.Add(defaultRetention)
+		doc.ExpiresAt = &expiresAt
+	}
+
+	samePolicy := (previousPolicy == nil && doc.RetentionPolicyID == nil) ||
+		(previousPolicy != nil && doc.RetentionPolicyID != nil && *previousPolicy == *doc.RetentionPolicyID)
+	sameExpiry := (previousExpiry == nil && doc.ExpiresAt == nil) ||
+		(previousExpiry != nil && doc.ExpiresAt != nil && previousExpiry.Equal(*doc.ExpiresAt))
+
+	return !samePolicy || !sameExpiry
+}
+
+// retentionPageSize is how many documents ReapplyRetention loads at once.
+const retentionPageSize = 500
+
+// ReapplyRetention re-evaluates every live document against the current
+// retention policies and persists the ones whose expiry changed.
+func (u *DocumentUsecase) ReapplyRetention(ctx context.Context) (int, error) {
+	rules, err := u.retentionRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for offset := 0; ; offset += retentionPageSize {
+		docs, err := u.repo.FindAll(ctx, entity.DocumentFilter{Limit: retentionPageSize, Offset: offset})
+		if err != nil {
+			return updated, fmt.Errorf("Failed to list documents for retention %w", err)
+		}
+
+		for _, doc := range docs {
+			if !retain(rules, doc) {
+				continue
+			}
+
+			if err := u.repo.UpdateRetention(ctx, doc); err != nil {
+				return updated, fmt.Errorf("Failed to update retention for document %s %w", doc.ID, err)
+			}
+			updated++
+		}
+
+		if len(docs) < retentionPageSize {
+			return updated, nil
+		}
+	}
+}
+
+func (u *DocumentUsecase) resolveFolder(ctx context.Context, folderID string) (*string, error) {
+	if folderID == "" {
+		return nil, nil
+	}
+
+	if u.folders == nil {
+		return nil, fmt.Errorf("%w: folders are not enabled", entity.ErrInvalidInput)
+	}
+
+	folder, err := u.folders.FindById(ctx, folderID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find folder %w", err)
+	}
+
+	if !inTenant(ctx, folder.TenantID) {
+		return nil, fmt.Errorf("Failed to find folder %w", entity.ErrFolderNotFound)
+	}
+
+	return &folder.ID, nil
+}
+
 func (u *DocumentUsecase) GetMetadata(ctx context.Context, id string) (*entity.Document, error) {
+	doc, err := u.findFor(ctx, id, entity.ACLPermissionRead)
+	u.audit.Record(ctx, entity.AuditActionRead, id, "", err)
+
+	return doc, err
+}
+
+// load fetches a document, trashed or not, from the caller's tenant.
+func (u *DocumentUsecase) load(ctx context.Context, id string) (*entity.Document, error) {
 	doc, err := u.repo.FindById(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to find item id %w", err)
 	}
 
+	if !inTenant(ctx, doc.TenantID) {
+		return nil, fmt.Errorf("Failed to find item id %w", entity.ErrDocumentNotFound)
+	}
+
 	return doc, nil
 }
 
-func (u *DocumentUsecase) Download(ctx context.Context, filename string) (io.ReadCloser, error) {
-	object, err := u.storage.Download(ctx, filename)
+// find loads a live document; trashed documents count as not found.
+func (u *DocumentUsecase) find(ctx context.Context, id string) (*entity.Document, error) {
+	doc, err := u.load(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to download from storage %w", err)
+		return nil, err
 	}
 
-	return object, nil
+	if doc.IsTrashed() {
+		return nil, fmt.Errorf("Failed to find item id %w", entity.ErrDocumentNotFound)
+	}
+
+	return doc, nil
 }
 
-func (u *DocumentUsecase) Delete(ctx context.Context, id string) error {
-	doc, err := u.repo.FindById(ctx, id)
+// findFor loads a live document and checks that the caller holds permission on it.
+func (u *DocumentUsecase) findFor(ctx context.Context, id, permission string) (*entity.Document, error) {
+	doc, err := u.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.authorize(ctx, doc, permission); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// findManageable loads a live document whose sharing the caller may change:
+// only its owner and admins can grant access to it.
+func (u *DocumentUsecase) findManageable(ctx context.Context, id string) (*entity.Document, error) {
+	doc, err := u.findFor(ctx, id, entity.ACLPermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, ok := entity.PrincipalFromContext(ctx)
+	if !ok || principal.Can(entity.PermissionAdmin) || (doc.OwnerID != nil && *doc.OwnerID == principal.ID) {
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("Failed to manage document access %w", entity.ErrForbidden)
+}
+
+// authorize checks the caller's access to doc. Internal callers without a
+// principal, admins, the owner and documents without an owner are
+// unrestricted; everyone else needs an ACL entry. Any entry grants read, and
+// callers who cannot read the document are told it does not exist.
+func (u *DocumentUsecase) authorize(ctx context.Context, doc *entity.Document, permission string) error {
+	principal, ok := entity.PrincipalFromContext(ctx)
+	if !ok || principal.Can(entity.PermissionAdmin) || doc.OwnerID == nil || *doc.OwnerID == principal.ID {
+		return nil
+	}
+
+	granted := []string{}
+	if u.acl != nil {
+		var err error
+		granted, err = u.acl.FindPermissions(ctx, doc.ID, principal.Subjects())
+		if err != nil {
+			return fmt.Errorf("Failed to check document access %w", err)
+		}
+	}
+
+	if len(granted) == 0 {
+		return fmt.Errorf("Failed to find item id %w", entity.ErrDocumentNotFound)
+	}
+
+	if permission != entity.ACLPermissionRead && !slices.Contains(granted, permission) {
+		return fmt.Errorf("Failed to access document %w", entity.ErrForbidden)
+	}
+
+	return nil
+}
+
+// visibleTo returns the ACL subjects listings are restricted to, or nil when
+// the caller may see every document.
+func visibleTo(ctx context.Context) []string {
+	principal, ok := entity.PrincipalFromContext(ctx)
+	if !ok || principal.Can(entity.PermissionAdmin) {
+		return nil
+	}
+
+	return principal.Subjects()
+}
+
+func (u *DocumentUsecase) Download(ctx context.Context, id string) (doc *entity.Document, object io.ReadCloser, err error) {
+	defer func() {
+		u.audit.Record(ctx, entity.AuditActionDownload, id, "", err)
+	}()
+
+	doc, err = u.findFor(ctx, id, entity.ACLPermissionRead)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := u.ensureScanned(doc); err != nil {
+		return nil, nil, fmt.Errorf("Failed to download document %w", err)
+	}
+	if err := ensureStored(doc); err != nil {
+		return nil, nil, fmt.Errorf("Failed to download document %w", err)
+	}
+
+	object, err = u.storage.Download(ctx, doc.ObjectKey())
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to download from storage %w", err)
+	}
+
+	return doc, object, nil
+}
+
+func (u *DocumentUsecase) Delete(ctx context.Context, id string) (err error) {
+	defer func() {
+		u.audit.Record(ctx, entity.AuditActionDelete, id, "", err)
+	}()
+
+	doc, err := u.findFor(ctx, id, entity.ACLPermissionDelete)
+	if err != nil {
+		return err
+	}
+
+	return u.trash(ctx, doc)
+}
+
+// CheckDelete reports why Delete would refuse doc without trashing it, so
+// that callers trashing several documents can check them all first.
+func (u *DocumentUsecase) CheckDelete(ctx context.Context, doc *entity.Document) error {
+	if err := u.authorize(ctx, doc, entity.ACLPermissionDelete); err != nil {
+		return err
+	}
+
+	return u.ensureNotHeld(ctx, doc)
+}
+
+func (u *DocumentUsecase) trash(ctx context.Context, doc *entity.Document) error {
+	if err := u.ensureNotHeld(ctx, doc); err != nil {
+		return err
+	}
+
+	if err := u.repo.Trash(ctx, doc.ID, time.Now()); err != nil {
+		return fmt.Errorf("Failed to move document to trash %w", err)
+	}
+
+	if err := u.publishEvent(ctx, "file.trashed", doc); err != nil {
+		return fmt.Errorf("Failed to publish trash event %w", err)
+	}
+
+	return nil
+}
+
+func (u *DocumentUsecase) ListTrash(ctx context.Context, filter entity.DocumentFilter) ([]*entity.Document, error) {
+	filter.Subjects = visibleTo(ctx)
+	filter.TenantID, _ = entity.TenantFromContext(ctx)
+
+	docs, err := u.repo.FindTrashed(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list items from trash %w", err)
+	}
+
+	return docs, nil
+}
+
+func (u *DocumentUsecase) Restore(ctx context.Context, id string) (doc *entity.Document, err error) {
+	defer func() {
+		u.audit.Record(ctx, entity.AuditActionRestore, id, "", err)
+	}()
+
+	doc, err = u.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.authorize(ctx, doc, entity.ACLPermissionDelete); err != nil {
+		return nil, err
+	}
+
+	if !doc.IsTrashed() {
+		return nil, fmt.Errorf("Failed to restore document %w", entity.ErrDocumentNotTrashed)
+	}
+
+	if err := u.repo.Restore(ctx, id); err != nil {
+		return nil, fmt.Errorf("Failed to restore document %w", err)
+	}
+	doc.DeletedAt = nil
+
+	if err := u.publishEvent(ctx, "file.restored", doc); err != nil {
+		return nil, fmt.Errorf("Failed to publish restore event %w", err)
+	}
+
+	return doc, nil
+}
+
+func (u *DocumentUsecase) Purge(ctx context.Context, id string) (err error) {
+	defer func() {
+		u.audit.Record(ctx, entity.AuditActionPurge, id, "", err)
+	}()
+
+	doc, err := u.load(ctx, id)
 	if err != nil {
-		return fmt.Errorf("Failed to find item id %w", err)
+		return err
+	}
+
+	if err := u.authorize(ctx, doc, entity.ACLPermissionDelete); err != nil {
+		return err
 	}
 
-	if err := u.storage.Delete(ctx, doc.FileName); err != nil {
+	if !doc.IsTrashed() {
+		return fmt.Errorf("Failed to purge document %w", entity.ErrDocumentNotTrashed)
+	}
+
+	return u.purge(ctx, doc)
+}
+
+func (u *DocumentUsecase) PurgeTrash(ctx context.Context, retention time.Duration) error {
+	trashedDocs, err := u.repo.FindTrashedBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return fmt.Errorf("Failed to find trashed documents %w", err)
+	}
+
+	for _, doc := range trashedDocs {
+		err := u.purge(ctx, doc)
+		u.audit.Record(ctx, entity.AuditActionPurge, doc.ID, "trash retention", err)
+		if err != nil {
+			fmt.Printf("Failed to purge trashed document %s: %v\n", doc.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (u *DocumentUsecase) purge(ctx context.Context, doc *entity.Document) error {
+	if err := u.ensureNotHeld(ctx, doc); err != nil {
+		return err
+	}
+
+	if err := u.removeThumbnails(ctx, doc); err != nil {
+		return err
+	}
+
+	if err := u.storage.Delete(ctx, doc.ObjectKey()); err != nil {
 		return fmt.Errorf("Failed to delete from storage %w", err)
 	}
 
-	if err := u.repo.Delete(ctx, id); err != nil {
+	if err := u.repo.Delete(ctx, doc.ID); err != nil {
+		// The object is gone, so the row is marked missing rather than left
+		// to fail downloads; purging again finishes the job.
+		now := time.Now()
+		if markErr := u.repo.UpdateMissing(context.WithoutCancel(ctx), doc.ID, &now); markErr != nil {
+			fmt.Printf("Failed to mark document %s missing: %v\n", doc.ID, markErr)
+		} else {
+			doc.MissingAt = &now
+		}
 		return fmt.Errorf("Failed to delete from repo %w", err)
 	}
 
-	event := map[string]interface{}{
-		"type":        "file.deleted",
-		"document_id": id,
-		"filename":    doc.FileName,
-		"timestamp":   time.Now().Format(time.RFC3339),
+	if err := u.publishEvent(ctx, "file.purged", doc); err != nil {
+		return fmt.Errorf("Failed to publish purge event %w", err)
 	}
 
-	eventJSON, err := json.Marshal(event)
+	return nil
+}
+
+// quotas lists the limits an upload by ownerID into tenantID must respect.
+func (u *DocumentUsecase) quotas(ctx context.Context, tenantID string, ownerID *string) ([]entity.Quota, error) {
+	if u.tenants == nil {
+		return nil, nil
+	}
+
+	tenant, err := u.tenants.FindById(ctx, tenantID)
 	if err != nil {
-		return fmt.Errorf("Failed to marshal event %w", err)
+		return nil, fmt.Errorf("Failed to find tenant %w", err)
 	}
 
-	if err := u.queue.Publish(ctx, string(eventJSON)); err != nil {
-		return fmt.Errorf("Failed to publish delete event %w", err)
+	return tenant.Quotas(ownerID), nil
+}
+
+// ensureQuota rejects an upload that is already over quota before its content
+// is stored. The repository enforces the quotas again when the document is saved.
+func (u *DocumentUsecase) ensureQuota(ctx context.Context, quotas []entity.Quota, size int64) error {
+	if u.usage == nil {
+		return nil
+	}
+
+	for _, quota := range quotas {
+		if quota.MaxBytes == nil && quota.MaxDocuments == nil {
+			continue
+		}
+
+		usage, err := u.usage.Find(ctx, quota.Scope, quota.SubjectID)
+		if err != nil {
+			return fmt.Errorf("Failed to find storage usage %w", err)
+		}
+
+		if err := quota.Check(usage, size, 1); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// ensureNotHeld rejects destructive operations on a document that still has an
+// active legal hold. There is deliberately no override.
+func (u *DocumentUsecase) ensureNotHeld(ctx context.Context, doc *entity.Document) error {
+	if u.holds == nil {
+		return nil
+	}
+
+	active, err := u.holds.CountActive(ctx, doc.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to check legal holds %w", err)
+	}
+
+	if active > 0 {
+		return fmt.Errorf("Failed to delete document %w", entity.ErrDocumentOnHold)
+	}
+
+	return nil
+}
+
+func (u *DocumentUsecase) publishEvent(ctx context.Context, eventType string, doc *entity.Document) error {
+	return u.publish(ctx, eventType, doc, nil)
+}
+
+func (u *DocumentUsecase) publish(ctx context.Context, eventType string, doc *entity.Document, fields map[string]interface{}) error {
+	event := map[string]interface{}{
+		"type":         eventType,
+		"document_id":  doc.ID,
+		"filename":     doc.FileName,
+		"content_type": doc.ContentType,
+		"tenant_id":    doc.TenantID,
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}
+	for key, value := range fields {
+		event[key] = value
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("Failed to marshal event %w", err)
+	}
+
+	return u.queue.Publish(ctx, string(eventJSON))
+}
+
 func (u *DocumentUsecase) DeleteExpiredDocuments(ctx context.Context) error {
+	rules, err := u.retentionRules(ctx)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	expiredDocs, err := u.repo.FindExpired(ctx, now)
 	if err != nil {
@@ -131,7 +873,18 @@ func (u *DocumentUsecase) DeleteExpiredDocuments(ctx context.Context) error {
 	}
 
 	for _, doc := range expiredDocs {
-		if err := u.Delete(ctx, doc.ID); err != nil {
+		// A policy may have changed since expires_at was stored, so the
+		// document is only deleted if it is still expired under current rules.
+		if u.hasRetentionRules() && retain(rules, doc) && (doc.ExpiresAt == nil || doc.ExpiresAt.After(now)) {
+			if err := u.repo.UpdateRetention(ctx, doc); err != nil {
+				fmt.Printf("Failed to update retention for document %s: %v\n", doc.ID, err)
+			}
+			continue
+		}
+
+		err := u.trash(ctx, doc)
+		u.audit.Record(ctx, entity.AuditActionExpire, doc.ID, "", err)
+		if err != nil {
 			fmt.Printf("Failed to delete expired document %s: %v\n", doc.ID, err)
 		}
 	}
@@ -154,6 +907,23 @@ func (u *DocumentUsecase) Health(ctx context.Context) map[string]string {
 		status["storage"] = "ok"
 	}
 
+	if replicated, ok := u.storage.(service.ReplicaReporter); ok {
+		for _, replica := range replicated.Replicas(ctx) {
+			health := "ok"
+			if replica.Err != nil {
+				health = "error: " + replica.Err.Error()
+			}
+			if replica.PendingRepairs > 0 {
+				health += fmt.Sprintf(" (%d repairs pending", replica.PendingRepairs)
+				if replica.LastRepairErr != nil {
+					health += ", last failed: " + replica.LastRepairErr.Error()
+				}
+				health += ")"
+			}
+			status["storage."+replica.Name] = health
+		}
+	}
+
 	if err := u.queue.Health(ctx); err != nil {
 		status["queue"] = "error: " + err.Error()
 	} else {
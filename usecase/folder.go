@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxFolderNameLength = 255
+
+type FolderUsecase struct {
+	repo      repository.FolderRepository
+	documents *DocumentUsecase
+}
+
+type FolderUpdateInput struct {
+	Name *string
+	// ParentID moves the folder; "" moves it to the root.
+	ParentID *string
+}
+
+type FolderContents struct {
+	Folder    *entity.Folder
+	Folders   []*entity.Folder
+	Documents []*entity.Document
+	Total     int64
+}
+
+func NewFolderUsecase(repo repository.FolderRepository, documents *DocumentUsecase) *FolderUsecase {
+	return &FolderUsecase{repo: repo, documents: documents}
+}
+
+func (u *FolderUsecase) Create(ctx context.Context, name string, parentID string) (*entity.Folder, error) {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+	folder := &entity.Folder{
+		ID:        id,
+		Name:      name,
+		Path:      "/" + id + "/",
+		TenantID:  tenantOf(ctx),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if parentID != "" {
+		parent, err := u.Get(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		folder.ParentID = &parent.ID
+		folder.Path = parent.Path + id + "/"
+	}
+
+	if err := u.repo.Save(ctx, folder); err != nil {
+		return nil, fmt.Errorf("Failed to save folder %w", err)
+	}
+
+	return folder, nil
+}
+
+// findOrCreateChild returns the folder called name under parentID, "" for the
+// root, creating it if it does not exist yet.
+func (u *FolderUsecase) findOrCreateChild(ctx context.Context, parentID, name string) (*entity.Folder, error) {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent *string
+	if parentID != "" {
+		parent = &parentID
+	}
+
+	find := func() (*entity.Folder, error) {
+		children, err := u.repo.FindChildren(ctx, tenantOf(ctx), parent)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list folders %w", err)
+		}
+		for _, child := range children {
+			if child.Name == name {
+				return child, nil
+			}
+		}
+		return nil, nil
+	}
+
+	if folder, err := find(); folder != nil || err != nil {
+		return folder, err
+	}
+
+	folder, err := u.Create(ctx, name, parentID)
+	if errors.Is(err, entity.ErrFolderExists) {
+		// Created concurrently since find.
+		if folder, findErr := find(); folder != nil {
+			return folder, findErr
+		}
+	}
+
+	return folder, err
+}
+
+func (u *FolderUsecase) Get(ctx context.Context, id string) (*entity.Folder, error) {
+	folder, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find folder %w", err)
+	}
+
+	if !inTenant(ctx, folder.TenantID) {
+		return nil, fmt.Errorf("Failed to find folder %w", entity.ErrFolderNotFound)
+	}
+
+	return folder, nil
+}
+
+func (u *FolderUsecase) ListRoot(ctx context.Context) ([]*entity.Folder, error) {
+	folders, err := u.repo.FindChildren(ctx, tenantOf(ctx), nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list folders %w", err)
+	}
+
+	return folders, nil
+}
+
+func (u *FolderUsecase) Update(ctx context.Context, id string, input FolderUpdateInput) (*entity.Folder, error) {
+	folder, err := u.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	folder.UpdatedAt = time.Now()
+
+	if input.Name != nil {
+		name, err := normalizeFolderName(*input.Name)
+		if err != nil {
+			return nil, err
+		}
+		folder.Name = name
+
+		if err := u.repo.Update(ctx, folder); err != nil {
+			return nil, fmt.Errorf("Failed to rename folder %w", err)
+		}
+	}
+
+	if input.ParentID != nil {
+		var parent *entity.Folder
+		if *input.ParentID != "" {
+			parent, err = u.Get(ctx, *input.ParentID)
+			if err != nil {
+				return nil, err
+			}
+
+			if strings.HasPrefix(parent.Path, folder.Path) {
+				return nil, fmt.Errorf("%w: a folder cannot be moved into itself or its descendants", entity.ErrInvalidInput)
+			}
+		}
+
+		if err := u.repo.Move(ctx, folder, parent); err != nil {
+			return nil, fmt.Errorf("Failed to move folder %w", err)
+		}
+	}
+
+	return folder, nil
+}
+
+func (u *FolderUsecase) Delete(ctx context.Context, id string, cascade bool) error {
+	folder, err := u.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	stats, err := u.repo.Stats(ctx, folder)
+	if err != nil {
+		return fmt.Errorf("Failed to compute folder stats %w", err)
+	}
+
+	if !cascade && (stats.FolderCount > 0 || stats.DocumentCount > 0) {
+		return fmt.Errorf("Failed to delete folder %w", entity.ErrFolderNotEmpty)
+	}
+
+	if cascade && stats.DocumentCount > 0 {
+		docs, err := u.documents.List(ctx, entity.DocumentFilter{FolderPath: folder.Path})
+		if err != nil {
+			return err
+		}
+
+		// Documents the caller cannot see would be orphaned by the cascade.
+		if int64(len(docs)) < stats.DocumentCount {
+			return fmt.Errorf("Failed to delete folder %w", entity.ErrForbidden)
+		}
+
+		// Checked up front so that a held or protected document leaves the
+		// folder and its contents as they were.
+		for _, doc := range docs {
+			if err := u.documents.CheckDelete(ctx, doc); err != nil {
+				return fmt.Errorf("Failed to delete folder, document %s cannot be trashed %w", doc.ID, err)
+			}
+		}
+
+		for _, doc := range docs {
+			if err := u.documents.Delete(ctx, doc.ID); err != nil {
+				return fmt.Errorf("Failed to move folder contents to trash %w", err)
+			}
+		}
+	}
+
+	if err := u.repo.DeleteSubtree(ctx, folder); err != nil {
+		return fmt.Errorf("Failed to delete folder %w", err)
+	}
+
+	return nil
+}
+
+func (u *FolderUsecase) Contents(ctx context.Context, id string, page, pageSize int) (*FolderContents, error) {
+	folder, err := u.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := u.repo.FindChildren(ctx, folder.TenantID, &folder.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list subfolders %w", err)
+	}
+
+	filter := entity.DocumentFilter{FolderID: &folder.ID}
+	total, err := u.documents.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+	docs, err := u.documents.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FolderContents{Folder: folder, Folders: folders, Documents: docs, Total: total}, nil
+}
+
+func (u *FolderUsecase) Stats(ctx context.Context, id string) (*entity.FolderStats, error) {
+	folder, err := u.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := u.repo.Stats(ctx, folder)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to compute folder stats %w", err)
+	}
+
+	return stats, nil
+}
+
+func normalizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxFolderNameLength || strings.ContainsAny(name, "/\\") {
+		return "", fmt.Errorf("%w: folder name must be 1-%d characters without slashes", entity.ErrInvalidInput, maxFolderNameLength)
+	}
+
+	return name, nil
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/repository"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxHoldReasonLength = 1024
+	maxHoldActorLength  = 255
+)
+
+type LegalHoldUsecase struct {
+	repo      repository.LegalHoldRepository
+	documents *DocumentUsecase
+}
+
+func NewLegalHoldUsecase(repo repository.LegalHoldRepository, documents *DocumentUsecase) *LegalHoldUsecase {
+	return &LegalHoldUsecase{repo: repo, documents: documents}
+}
+
+// Place puts a document under legal hold. Trashed documents can be held too,
+// which keeps them from being purged.
+func (u *LegalHoldUsecase) Place(ctx context.Context, documentID, reason, createdBy string) (hold *entity.LegalHold, err error) {
+	defer func() {
+		u.documents.audit.Record(ctx, entity.AuditActionHoldPlace, documentID, holdDetail(hold, createdBy), err)
+	}()
+
+	reason, err = normalizeHoldText("reason", reason, maxHoldReasonLength)
+	if err != nil {
+		return nil, err
+	}
+
+	createdBy, err = normalizeHoldText("created_by", actorName(ctx, createdBy), maxHoldActorLength)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := u.documents.load(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold = &entity.LegalHold{
+		ID:         uuid.New().String(),
+		DocumentID: doc.ID,
+		Reason:     reason,
+		CreatedBy:  createdBy,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := u.repo.Save(ctx, hold); err != nil {
+		return nil, fmt.Errorf("Failed to save legal hold %w", err)
+	}
+
+	if err := u.documents.publish(ctx, "file.hold_placed", doc, map[string]interface{}{
+		"hold_id": hold.ID,
+		"reason":  hold.Reason,
+		"actor":   hold.CreatedBy,
+	}); err != nil {
+		return nil, fmt.Errorf("Failed to publish hold event %w", err)
+	}
+
+	return hold, nil
+}
+
+// List lists the holds of a document the caller can read, trashed ones
+// included, as holds are what keep them from being purged.
+func (u *LegalHoldUsecase) List(ctx context.Context, documentID string, activeOnly bool) ([]*entity.LegalHold, error) {
+	doc, err := u.documents.load(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.documents.authorize(ctx, doc, entity.ACLPermissionRead); err != nil {
+		return nil, err
+	}
+
+	holds, err := u.repo.FindByDocument(ctx, documentID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list legal holds %w", err)
+	}
+
+	return holds, nil
+}
+
+func (u *LegalHoldUsecase) Release(ctx context.Context, documentID, holdID, releasedBy, reason string) (hold *entity.LegalHold, err error) {
+	defer func() {
+		u.documents.audit.Record(ctx, entity.AuditActionHoldRelease, documentID, "hold "+holdID+" by "+releasedBy, err)
+	}()
+
+	releasedBy, err = normalizeHoldText("released_by", actorName(ctx, releasedBy), maxHoldActorLength)
+	if err != nil {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxHoldReasonLength {
+		return nil, fmt.Errorf("%w: reason must be at most %d characters", entity.ErrInvalidInput, maxHoldReasonLength)
+	}
+
+	// Loaded first so that holds on other tenants' documents are never touched.
+	doc, err := u.documents.load(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err = u.repo.FindById(ctx, holdID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find legal hold %w", err)
+	}
+
+	if hold.DocumentID != documentID {
+		return nil, fmt.Errorf("Failed to find legal hold %w", entity.ErrLegalHoldNotFound)
+	}
+
+	if !hold.IsActive() {
+		return nil, fmt.Errorf("Failed to release legal hold %w", entity.ErrLegalHoldReleased)
+	}
+
+	now := time.Now()
+	hold.ReleasedBy = &releasedBy
+	hold.ReleasedAt = &now
+	if reason != "" {
+		hold.ReleaseReason = &reason
+	}
+
+	if err := u.repo.Release(ctx, hold); err != nil {
+		return nil, fmt.Errorf("Failed to release legal hold %w", err)
+	}
+
+	if err := u.documents.publish(ctx, "file.hold_released", doc, map[string]interface{}{
+		"hold_id": hold.ID,
+		"reason":  reason,
+		"actor":   releasedBy,
+	}); err != nil {
+		return nil, fmt.Errorf("Failed to publish hold event %w", err)
+	}
+
+	return hold, nil
+}
+
+// actorName prefers the authenticated principal over a name supplied by the caller.
+func actorName(ctx context.Context, supplied string) string {
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		return principal.ID
+	}
+
+	return supplied
+}
+
+func holdDetail(hold *entity.LegalHold, createdBy string) string {
+	if hold == nil {
+		return ""
+	}
+
+	return "hold " + hold.ID + " by " + createdBy
+}
+
+func normalizeHoldText(field, value string, maxLength int) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || len(value) > maxLength {
+		return "", fmt.Errorf("%w: %s must be 1 to %d characters", entity.ErrInvalidInput, field, maxLength)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,612 @@
+package usecase
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"docvault/entity"
+	"docvault/repository"
+	"docvault/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// importProgressInterval is how many entries an async import processes
+// between saving its progress.
+const importProgressInterval = 50
+
+// tarMagicOffset is where a tar header carries "ustar".
+const tarMagicOffset = 257
+
+// importSystemFiles are metadata that archivers, mostly on macOS, add
+// alongside the real files.
+var importSystemFiles = []string{"__MACOSX", ".DS_Store", "Thumbs.db", "desktop.ini"}
+
+// ImportLimits protect imports from archive bombs; 0 disables a limit.
+type ImportLimits struct {
+	// MaxEntries counts every entry, directories included.
+	MaxEntries    int
+	MaxEntryBytes int64
+	// MaxTotalBytes caps the unpacked size of all files together.
+	MaxTotalBytes int64
+	// MaxCompressionRatio rejects zip entries that expand more than this many
+	// times their compressed size.
+	MaxCompressionRatio int64
+}
+
+type ImportUsecase struct {
+	repo      repository.ImportRepository
+	documents *DocumentUsecase
+	folders   *FolderUsecase
+	storage   service.StorageService
+	limits    ImportLimits
+	jobs      *JobUsecase
+}
+
+type ImportInput struct {
+	FileName string
+	FileSize int64
+	// File is read as a stream; zip archives are read in place when File is
+	// also an io.ReaderAt and spooled to a temporary file otherwise.
+	File     io.Reader
+	FolderID string
+	Tags     []string
+	Metadata map[string]string
+}
+
+func NewImportUsecase(repo repository.ImportRepository, documents *DocumentUsecase, folders *FolderUsecase, storage service.StorageService, limits ImportLimits) *ImportUsecase {
+	return &ImportUsecase{repo: repo, documents: documents, folders: folders, storage: storage, limits: limits}
+}
+
+// RegisterJobs makes async imports run as jobs of jobs. Start queues them;
+// they cannot be submitted as jobs directly.
+func (u *ImportUsecase) RegisterJobs(jobs *JobUsecase) {
+	u.jobs = jobs
+	jobs.Register(JobKindImport, JobKind{
+		Permission: entity.PermissionDocumentsWrite,
+		Validate:   validateImportJob,
+		Run:        u.runImportJob,
+		Finished:   u.finishImportJob,
+	})
+}
+
+// Import unpacks an archive while the caller waits. Every file becomes a
+// document through the normal upload; a failing file is reported in the
+// result and does not stop the others.
+func (u *ImportUsecase) Import(ctx context.Context, input ImportInput) (*entity.Import, error) {
+	imp, err := u.newImport(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, cleanup, err := u.openArchive(input.File, input.FileSize)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	imp.Status = entity.ImportStatusRunning
+	err = u.run(ctx, imp, archive, input, nil)
+	if err != nil && len(imp.Results) == 0 {
+		return nil, err
+	}
+	finishImport(imp, err)
+
+	return imp, nil
+}
+
+// Start stores the archive and queues an import job, under the import's id,
+// to unpack it. Progress and results are kept with the import, which Get
+// returns.
+func (u *ImportUsecase) Start(ctx context.Context, input ImportInput) (*entity.Import, error) {
+	imp, err := u.newImport(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := json.Marshal(importJobParams{FileSize: input.FileSize, Tags: input.Tags, Metadata: input.Metadata})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to encode import params %w", err)
+	}
+
+	key := importObjectKey(imp)
+	if err := u.storage.Upload(ctx, key, input.FileSize, "application/octet-stream", input.File); err != nil {
+		return nil, fmt.Errorf("Failed to upload to storage %w", err)
+	}
+
+	if err := u.repo.Save(ctx, imp); err != nil {
+		u.documents.removeObject(ctx, key)
+		return nil, fmt.Errorf("Failed to save import %w", err)
+	}
+
+	if _, err := u.jobs.enqueue(ctx, JobKindImport, imp.ID, params); err != nil {
+		u.documents.removeObject(ctx, key)
+		finishImport(imp, err)
+		u.save(ctx, imp)
+		return nil, fmt.Errorf("Failed to queue import %w", err)
+	}
+
+	return imp, nil
+}
+
+func (u *ImportUsecase) Get(ctx context.Context, id string) (*entity.Import, error) {
+	imp, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find import %w", err)
+	}
+
+	if !inTenant(ctx, imp.TenantID) {
+		return nil, fmt.Errorf("Failed to find import %w", entity.ErrImportNotFound)
+	}
+
+	// Results name files the caller may not otherwise see, so imports are
+	// private to whoever started them.
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && !principal.Can(entity.PermissionAdmin) &&
+		(imp.CreatedBy == nil || *imp.CreatedBy != principal.ID) {
+		return nil, fmt.Errorf("Failed to find import %w", entity.ErrImportNotFound)
+	}
+
+	return imp, nil
+}
+
+func (u *ImportUsecase) newImport(ctx context.Context, input ImportInput) (*entity.Import, error) {
+	if _, err := normalizeTags(input.Tags); err != nil {
+		return nil, err
+	}
+
+	if _, err := normalizeMetadata(input.Metadata); err != nil {
+		return nil, err
+	}
+
+	folderID, err := u.documents.resolveFolder(ctx, input.FolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdBy *string
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		createdBy = &principal.ID
+	}
+
+	now := time.Now()
+	return &entity.Import{
+		ID:        uuid.New().String(),
+		TenantID:  tenantOf(ctx),
+		FileName:  input.FileName,
+		FolderID:  folderID,
+		Status:    entity.ImportStatusQueued,
+		Results:   []entity.ImportResult{},
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func importObjectKey(imp *entity.Import) string {
+	return "imports/" + imp.TenantID + "/" + imp.ID
+}
+
+// importJobParams carry what an async import needs besides its stored
+// archive and its imports row.
+type importJobParams struct {
+	FileSize int64             `json:"file_size"`
+	Tags     []string          `json:"tags"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+func validateImportJob(params json.RawMessage) error {
+	return fmt.Errorf("%w: import jobs are started with POST /api/documents/import", entity.ErrInvalidInput)
+}
+
+// runImportJob unpacks the archive Start stored. When ctx is canceled it
+// saves the results so far and leaves the import for finishImportJob to
+// close with the job's outcome.
+func (u *ImportUsecase) runImportJob(ctx context.Context, job *entity.Job, run *JobRun) error {
+	var p importJobParams
+	if err := json.Unmarshal([]byte(job.Params), &p); err != nil {
+		return fmt.Errorf("%w: invalid import params: %v", entity.ErrInvalidInput, err)
+	}
+
+	imp, err := u.repo.FindById(ctx, job.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to find import %w", err)
+	}
+
+	imp.Status = entity.ImportStatusRunning
+	u.save(ctx, imp)
+
+	input := ImportInput{FileName: imp.FileName, FileSize: p.FileSize, Tags: p.Tags, Metadata: p.Metadata}
+	err = func() error {
+		object, err := u.storage.Download(ctx, importObjectKey(imp))
+		if err != nil {
+			return fmt.Errorf("Failed to download from storage %w", err)
+		}
+		defer object.Close()
+
+		archive, cleanup, err := u.openArchive(object, input.FileSize)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		return u.run(ctx, imp, archive, input, func() {
+			u.save(ctx, imp)
+			run.Progress(ctx, int64(len(imp.Results)), 0)
+		})
+	}()
+
+	if ctx.Err() != nil {
+		u.save(ctx, imp)
+		return ctx.Err()
+	}
+
+	finishImport(imp, err)
+	u.save(ctx, imp)
+	return err
+}
+
+// finishImportJob removes the stored archive once an import job has ended,
+// and closes the import if the job ended before runImportJob could.
+func (u *ImportUsecase) finishImportJob(ctx context.Context, job *entity.Job) {
+	imp, err := u.repo.FindById(ctx, job.ID)
+	if err != nil {
+		fmt.Printf("Failed to find import %s: %v\n", job.ID, err)
+		return
+	}
+
+	u.documents.removeObject(ctx, importObjectKey(imp))
+	if imp.CompletedAt != nil {
+		return
+	}
+
+	switch {
+	case job.Status == entity.JobStatusCanceled:
+		finishImport(imp, nil)
+		imp.Status = entity.ImportStatusCanceled
+	case job.Error != nil:
+		finishImport(imp, errors.New(*job.Error))
+	default:
+		finishImport(imp, nil)
+	}
+	u.save(ctx, imp)
+}
+
+// save also runs once ctx is canceled, to record where the import stopped.
+func (u *ImportUsecase) save(ctx context.Context, imp *entity.Import) {
+	imp.UpdatedAt = time.Now()
+	if err := u.repo.Update(context.WithoutCancel(ctx), imp); err != nil {
+		fmt.Printf("Failed to save import %s: %v\n", imp.ID, err)
+	}
+}
+
+func finishImport(imp *entity.Import, err error) {
+	now := time.Now()
+	imp.Status = entity.ImportStatusCompleted
+	imp.CompletedAt = &now
+	if err != nil {
+		message := err.Error()
+		imp.Status = entity.ImportStatusFailed
+		imp.Error = &message
+	}
+}
+
+// run imports every entry of archive into imp, calling progress now and then.
+// Only problems with the archive as a whole are returned.
+func (u *ImportUsecase) run(ctx context.Context, imp *entity.Import, archive importArchive, input ImportInput, progress func()) error {
+	folders := map[string]string{}
+	entries := 0
+	var total int64
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		entry, err := archive.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entries++
+		if u.limits.MaxEntries > 0 && entries > u.limits.MaxEntries {
+			return fmt.Errorf("%w: more than %d entries", entity.ErrArchiveTooLarge, u.limits.MaxEntries)
+		}
+		if entry.dir {
+			continue
+		}
+
+		total += entry.size
+		if u.limits.MaxTotalBytes > 0 && total > u.limits.MaxTotalBytes {
+			return fmt.Errorf("%w: more than %d bytes unpacked", entity.ErrArchiveTooLarge, u.limits.MaxTotalBytes)
+		}
+
+		imp.Add(u.importEntry(ctx, imp, entry, input, folders))
+		if progress != nil && len(imp.Results)%importProgressInterval == 0 {
+			progress()
+		}
+	}
+}
+
+func (u *ImportUsecase) importEntry(ctx context.Context, imp *entity.Import, entry *importEntry, input ImportInput, folders map[string]string) entity.ImportResult {
+	result := entity.ImportResult{Path: entry.name, Status: entity.ImportEntryFailed}
+	fail := func(err error) entity.ImportResult {
+		message := err.Error()
+		result.Error = &message
+		return result
+	}
+	skip := func(reason string) entity.ImportResult {
+		result.Status = entity.ImportEntrySkipped
+		result.Error = &reason
+		return result
+	}
+
+	dirs, fileName, err := importPath(entry.name)
+	switch {
+	case err != nil:
+		return fail(err)
+	case !entry.regular:
+		return skip("not a regular file")
+	case slices.Contains(importSystemFiles, fileName) || (len(dirs) > 0 && slices.Contains(importSystemFiles, dirs[0])):
+		return skip("system file")
+	case u.limits.MaxEntryBytes > 0 && entry.size > u.limits.MaxEntryBytes:
+		return fail(fmt.Errorf("%w: at most %d bytes per file", entity.ErrFileTooLarge, u.limits.MaxEntryBytes))
+	case u.limits.MaxCompressionRatio > 0 && entry.compressed >= 0 && entry.size > 0 &&
+		(entry.compressed == 0 || entry.size/entry.compressed > u.limits.MaxCompressionRatio):
+		return fail(fmt.Errorf("%w: compressed more than %d to 1", entity.ErrArchiveTooLarge, u.limits.MaxCompressionRatio))
+	}
+
+	folderID, err := u.importFolder(ctx, imp, dirs, folders)
+	if err != nil {
+		return fail(err)
+	}
+
+	file, err := entry.open()
+	if err != nil {
+		return fail(fmt.Errorf("%w: %v", entity.ErrInvalidInput, err))
+	}
+	defer file.Close()
+
+	doc, err := u.documents.Upload(ctx, UploadInput{
+		FileName: fileName,
+		FileSize: entry.size,
+		File:     file,
+		FolderID: folderID,
+		Tags:     input.Tags,
+		Metadata: input.Metadata,
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	result.Status = entity.ImportEntryImported
+	result.DocumentID = &doc.ID
+	return result
+}
+
+// importFolder finds or creates the folders dirs names below the import's
+// folder and returns the innermost one's ID.
+func (u *ImportUsecase) importFolder(ctx context.Context, imp *entity.Import, dirs []string, folders map[string]string) (string, error) {
+	folderID := ""
+	if imp.FolderID != nil {
+		folderID = *imp.FolderID
+	}
+
+	for i, name := range dirs {
+		dir := strings.Join(dirs[:i+1], "/")
+		if id, ok := folders[dir]; ok {
+			folderID = id
+			continue
+		}
+
+		folder, err := u.folders.findOrCreateChild(ctx, folderID, name)
+		if err != nil {
+			return "", err
+		}
+		folders[dir] = folder.ID
+		folderID = folder.ID
+	}
+
+	return folderID, nil
+}
+
+// importPath splits an entry name into its directories and file name,
+// rejecting names that would land outside the import's folder.
+func importPath(name string) ([]string, string, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') || strings.ContainsRune(name, 0) {
+		return nil, "", fmt.Errorf("%w: unsafe path", entity.ErrInvalidInput)
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(name, "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			return nil, "", fmt.Errorf("%w: unsafe path", entity.ErrInvalidInput)
+		default:
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) == 0 {
+		return nil, "", fmt.Errorf("%w: empty path", entity.ErrInvalidInput)
+	}
+
+	return segments[:len(segments)-1], segments[len(segments)-1], nil
+}
+
+type importEntry struct {
+	name    string
+	size    int64
+	dir     bool
+	regular bool
+	// compressed is the stored size of zip entries and -1 for tar entries.
+	compressed int64
+	open       func() (io.ReadCloser, error)
+}
+
+type importArchive interface {
+	// next returns io.EOF after the last entry.
+	next() (*importEntry, error)
+}
+
+// openArchive recognises zip, tar and gzipped tar archives by content, not by
+// file name. The returned cleanup must be called once the archive is read.
+func (u *ImportUsecase) openArchive(file io.Reader, size int64) (importArchive, func(), error) {
+	buffered := bufio.NewReader(file)
+	head, err := buffered.Peek(tarMagicOffset + len("ustar"))
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, nil, fmt.Errorf("Failed to read archive %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")) || bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return u.openZip(file, buffered, size)
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: corrupt gzip stream: %v", entity.ErrInvalidInput, err)
+		}
+		return &tarArchive{reader: tar.NewReader(u.capTarStream(gz))}, func() { gz.Close() }, nil
+	case len(head) >= tarMagicOffset+len("ustar") && string(head[tarMagicOffset:]) == "ustar":
+		return &tarArchive{reader: tar.NewReader(buffered)}, func() {}, nil
+	}
+
+	return nil, nil, fmt.Errorf("%w: file is not a zip or tar archive", entity.ErrInvalidInput)
+}
+
+func (u *ImportUsecase) openZip(file io.Reader, buffered io.Reader, size int64) (importArchive, func(), error) {
+	cleanup := func() {}
+	readerAt, ok := file.(io.ReaderAt)
+	if !ok || size <= 0 {
+		// The central directory sits at the end, so the zip has to be seekable.
+		spool, err := os.CreateTemp("", "docvault-import-*.zip")
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to spool archive %w", err)
+		}
+		cleanup = func() {
+			spool.Close()
+			os.Remove(spool.Name())
+		}
+		if size, err = io.Copy(spool, buffered); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("Failed to spool archive %w", err)
+		}
+		readerAt = spool
+	}
+
+	reader, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("%w: corrupt zip archive: %v", entity.ErrInvalidInput, err)
+	}
+
+	// Zip archives declare their contents up front, so limits can be checked
+	// before anything is imported.
+	var total uint64
+	for _, file := range reader.File {
+		total += file.UncompressedSize64
+	}
+	if u.limits.MaxEntries > 0 && len(reader.File) > u.limits.MaxEntries {
+		cleanup()
+		return nil, nil, fmt.Errorf("%w: more than %d entries", entity.ErrArchiveTooLarge, u.limits.MaxEntries)
+	}
+	if u.limits.MaxTotalBytes > 0 && total > uint64(u.limits.MaxTotalBytes) {
+		cleanup()
+		return nil, nil, fmt.Errorf("%w: more than %d bytes unpacked", entity.ErrArchiveTooLarge, u.limits.MaxTotalBytes)
+	}
+
+	return &zipArchive{files: reader.File}, cleanup, nil
+}
+
+// capTarStream bounds how much a gzip stream may inflate to: the file limits
+// plus room for a header and padding per entry and the end of the archive.
+func (u *ImportUsecase) capTarStream(r io.Reader) io.Reader {
+	if u.limits.MaxTotalBytes <= 0 || u.limits.MaxEntries <= 0 {
+		return r
+	}
+
+	return &cappedReader{reader: r, remaining: u.limits.MaxTotalBytes + int64(u.limits.MaxEntries+1)*2048 + 1<<20}
+}
+
+type cappedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *cappedReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, fmt.Errorf("%w: archive inflates beyond the import limits", entity.ErrArchiveTooLarge)
+	}
+
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+
+	return n, err
+}
+
+type zipArchive struct {
+	files []*zip.File
+}
+
+func (a *zipArchive) next() (*importEntry, error) {
+	if len(a.files) == 0 {
+		return nil, io.EOF
+	}
+	file := a.files[0]
+	a.files = a.files[1:]
+
+	mode := file.Mode()
+	return &importEntry{
+		name:       file.Name,
+		size:       int64(file.UncompressedSize64),
+		dir:        mode.IsDir() || strings.HasSuffix(file.Name, "/"),
+		regular:    mode.IsRegular(),
+		compressed: int64(file.CompressedSize64),
+		open:       file.Open,
+	}, nil
+}
+
+type tarArchive struct {
+	reader *tar.Reader
+}
+
+func (a *tarArchive) next() (*importEntry, error) {
+	header, err := a.reader.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	if err != nil {
+		if errors.Is(err, entity.ErrArchiveTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: corrupt tar archive: %v", entity.ErrInvalidInput, err)
+	}
+
+	return &importEntry{
+		name:       header.Name,
+		size:       header.Size,
+		dir:        header.Typeflag == tar.TypeDir,
+		regular:    header.Typeflag == tar.TypeReg,
+		compressed: -1,
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(a.reader), nil
+		},
+	}, nil
+}
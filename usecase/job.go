@@ -0,0 +1,430 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/repository"
+	"docvault/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	JobKindArchive          = "archive"
+	JobKindThumbnails       = "thumbnails"
+	JobKindStorageMigration = "storage_migration"
+	JobKindImport           = "import"
+)
+
+// jobProgressInterval throttles how often a running job writes its progress
+// and notices that it has been canceled.
+const jobProgressInterval = time.Second
+
+// jobMaxAttempts caps how often a resumable job starts over after a crash,
+// so one that brings the process down does not do so forever.
+const jobMaxAttempts = 3
+
+// JobFunc runs one job. It reports progress and stores its outcome through
+// run, and should return promptly once ctx is done.
+type JobFunc func(ctx context.Context, job *entity.Job, run *JobRun) error
+
+type JobKind struct {
+	// Permission is what the submitter needs to submit the kind.
+	Permission string
+	// Platform kinds act on every tenant and only platform principals may
+	// submit them.
+	Platform bool
+	// Resumable kinds start over after a restart; others are marked failed.
+	Resumable bool
+	// Validate checks params on submission.
+	Validate func(params json.RawMessage) error
+	Run      JobFunc
+	// Finished, when set, runs once a job of the kind has ended, including
+	// jobs canceled while queued or cut short by a restart, which Run never
+	// got to finish.
+	Finished func(ctx context.Context, job *entity.Job)
+}
+
+type JobUsecase struct {
+	repo      repository.JobRepository
+	storage   service.StorageService
+	retention time.Duration
+
+	kinds map[string]JobKind
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+func NewJobUsecase(repo repository.JobRepository, storage service.StorageService, retention time.Duration) *JobUsecase {
+	return &JobUsecase{
+		repo:      repo,
+		storage:   storage,
+		retention: retention,
+		kinds:     map[string]JobKind{},
+		running:   map[string]context.CancelFunc{},
+	}
+}
+
+// Register makes a kind of job available. Kinds are registered while the
+// application is wired up, before any job runs.
+func (u *JobUsecase) Register(kind string, definition JobKind) {
+	u.kinds[kind] = definition
+}
+
+func (u *JobUsecase) Submit(ctx context.Context, kind string, params json.RawMessage) (*entity.Job, error) {
+	definition, ok := u.kinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown job kind %q", entity.ErrInvalidInput, kind)
+	}
+
+	principal, hasPrincipal := entity.PrincipalFromContext(ctx)
+	if hasPrincipal && !principal.Can(definition.Permission) {
+		return nil, fmt.Errorf("%w: %s jobs require %s", entity.ErrForbidden, kind, definition.Permission)
+	}
+	if hasPrincipal && definition.Platform && !principal.IsPlatform() {
+		return nil, fmt.Errorf("%w: %s jobs are for platform principals", entity.ErrForbidden, kind)
+	}
+
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	if definition.Validate != nil {
+		if err := definition.Validate(params); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.enqueue(ctx, kind, uuid.New().String(), params)
+}
+
+// enqueue saves a job the caller has already checked, under id.
+func (u *JobUsecase) enqueue(ctx context.Context, kind, id string, params json.RawMessage) (*entity.Job, error) {
+	now := time.Now()
+	job := &entity.Job{
+		ID:        id,
+		TenantID:  tenantOf(ctx),
+		Kind:      kind,
+		Status:    entity.JobStatusQueued,
+		Params:    string(params),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		job.Principal = principal
+		job.CreatedBy = &principal.ID
+	}
+
+	if err := u.repo.Save(ctx, job); err != nil {
+		return nil, fmt.Errorf("Failed to save job %w", err)
+	}
+
+	return job, nil
+}
+
+// Get returns a job to the principal that submitted it, or to an admin.
+func (u *JobUsecase) Get(ctx context.Context, id string) (*entity.Job, error) {
+	job, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find job %w", err)
+	}
+
+	if !inTenant(ctx, job.TenantID) {
+		return nil, fmt.Errorf("Failed to find job %w", entity.ErrJobNotFound)
+	}
+
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && !principal.Can(entity.PermissionAdmin) &&
+		(job.CreatedBy == nil || *job.CreatedBy != principal.ID) {
+		return nil, fmt.Errorf("Failed to find job %w", entity.ErrJobNotFound)
+	}
+
+	return job, nil
+}
+
+func (u *JobUsecase) List(ctx context.Context, filter entity.JobFilter) ([]*entity.Job, error) {
+	filter.TenantID, _ = entity.TenantFromContext(ctx)
+	filter.CreatedBy = nil
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && !principal.Can(entity.PermissionAdmin) {
+		filter.CreatedBy = &principal.ID
+	}
+
+	jobs, err := u.repo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list jobs %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Cancel cancels a queued job at once and asks a running one to stop.
+func (u *JobUsecase) Cancel(ctx context.Context, id string) (*entity.Job, error) {
+	job, err := u.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	queued := job.Status == entity.JobStatusQueued
+
+	if err := u.repo.RequestCancel(ctx, id, time.Now()); err != nil {
+		return nil, fmt.Errorf("Failed to cancel job %w", err)
+	}
+
+	u.mu.Lock()
+	if cancel, ok := u.running[id]; ok {
+		cancel()
+	}
+	u.mu.Unlock()
+
+	job, err = u.Get(ctx, id)
+	if err == nil && queued && job.Status == entity.JobStatusCanceled {
+		u.finished(ctx, job)
+	}
+
+	return job, err
+}
+
+func (u *JobUsecase) Artifact(ctx context.Context, id string) (*entity.Job, io.ReadCloser, error) {
+	job, err := u.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if job.ArtifactKey == nil {
+		return nil, nil, fmt.Errorf("Failed to download job artifact %w", entity.ErrNoJobArtifact)
+	}
+
+	object, err := u.storage.Download(ctx, *job.ArtifactKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to download from storage %w", err)
+	}
+
+	return job, object, nil
+}
+
+// Recover deals with jobs a restart left running: ones asked to stop are
+// canceled, resumable ones go back in the queue until they run out of
+// attempts, and the rest are marked failed. It must run before any worker
+// claims a job.
+func (u *JobUsecase) Recover(ctx context.Context) error {
+	jobs, err := u.repo.FindByStatus(ctx, entity.JobStatusRunning)
+	if err != nil {
+		return fmt.Errorf("Failed to find interrupted jobs %w", err)
+	}
+
+	for _, job := range jobs {
+		switch {
+		case job.CancelRequested:
+			u.finish(ctx, job, entity.ErrJobCanceled)
+		case !u.kinds[job.Kind].Resumable:
+			u.finish(ctx, job, errors.New("interrupted by a restart"))
+		case job.Attempts >= jobMaxAttempts:
+			u.finish(ctx, job, fmt.Errorf("interrupted by a restart %d times", job.Attempts))
+		default:
+			if err := u.repo.Requeue(ctx, job.ID, time.Now()); err != nil {
+				return fmt.Errorf("Failed to requeue job %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RunNext claims the oldest queued job and runs it to the end, reporting
+// whether there was one.
+func (u *JobUsecase) RunNext(ctx context.Context) (bool, error) {
+	job, err := u.repo.Claim(ctx, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("Failed to claim job %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	u.run(ctx, job)
+	return true, nil
+}
+
+func (u *JobUsecase) run(ctx context.Context, job *entity.Job) {
+	definition, ok := u.kinds[job.Kind]
+	if !ok {
+		u.finish(ctx, job, fmt.Errorf("unknown job kind %q", job.Kind))
+		return
+	}
+
+	// Jobs act with the access their submitter had when submitting them.
+	runCtx, cancel := context.WithCancel(entity.WithTenant(ctx, job.TenantID))
+	defer cancel()
+	if job.Principal != nil {
+		runCtx = entity.WithPrincipal(runCtx, job.Principal)
+	}
+
+	u.mu.Lock()
+	u.running[job.ID] = cancel
+	u.mu.Unlock()
+	defer func() {
+		u.mu.Lock()
+		delete(u.running, job.ID)
+		u.mu.Unlock()
+	}()
+
+	run := &JobRun{usecase: u, job: job, cancel: cancel}
+	err := definition.Run(runCtx, job, run)
+
+	switch {
+	case err == nil:
+	case ctx.Err() != nil && definition.Resumable:
+		// Shutting down; the job starts over after the restart, and a
+		// clean shutdown does not use up one of its attempts.
+		if err := u.repo.Release(context.WithoutCancel(ctx), job.ID, time.Now()); err != nil {
+			fmt.Printf("Failed to release job %s: %v\n", job.ID, err)
+		}
+		return
+	case ctx.Err() != nil:
+		err = errors.New("interrupted by shutdown")
+	case runCtx.Err() != nil:
+		err = entity.ErrJobCanceled
+	}
+
+	u.finish(ctx, job, err)
+}
+
+// finish stores the outcome of job; err is nil for success and
+// ErrJobCanceled for a job that stopped because it was asked to.
+func (u *JobUsecase) finish(ctx context.Context, job *entity.Job, err error) {
+	ctx = context.WithoutCancel(ctx)
+	now := time.Now()
+	job.UpdatedAt = now
+	job.CompletedAt = &now
+	job.Status = entity.JobStatusSucceeded
+
+	if err != nil {
+		job.Status = entity.JobStatusFailed
+		if errors.Is(err, entity.ErrJobCanceled) {
+			job.Status = entity.JobStatusCanceled
+		}
+		message := err.Error()
+		job.Error = &message
+
+		if job.ArtifactKey != nil {
+			u.removeArtifact(ctx, *job.ArtifactKey)
+			job.ArtifactKey, job.ArtifactName, job.ArtifactContentType, job.ArtifactSize = nil, nil, nil, nil
+		}
+	}
+
+	if err := u.repo.Finish(ctx, job); err != nil {
+		fmt.Printf("Failed to finish job %s: %v\n", job.ID, err)
+		return
+	}
+
+	u.finished(ctx, job)
+}
+
+// finished runs the Finished hook of job's kind.
+func (u *JobUsecase) finished(ctx context.Context, job *entity.Job) {
+	if definition := u.kinds[job.Kind]; definition.Finished != nil {
+		definition.Finished(context.WithoutCancel(entity.WithTenant(ctx, job.TenantID)), job)
+	}
+}
+
+// PurgeFinished removes jobs, and their artifacts, that finished longer ago
+// than the retention period.
+func (u *JobUsecase) PurgeFinished(ctx context.Context) error {
+	jobs, err := u.repo.FindFinishedBefore(ctx, time.Now().Add(-u.retention))
+	if err != nil {
+		return fmt.Errorf("Failed to find finished jobs %w", err)
+	}
+
+	for _, job := range jobs {
+		if job.ArtifactKey != nil {
+			u.removeArtifact(ctx, *job.ArtifactKey)
+		}
+		if err := u.repo.Delete(ctx, job.ID); err != nil {
+			return fmt.Errorf("Failed to delete job %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (u *JobUsecase) removeArtifact(ctx context.Context, key string) {
+	if err := u.storage.Delete(ctx, key); err != nil {
+		fmt.Printf("Failed to remove job artifact %s: %v\n", key, err)
+	}
+}
+
+// RegisterJobs makes the document jobs available through jobs.
+func (u *DocumentUsecase) RegisterJobs(jobs *JobUsecase) {
+	jobs.Register(JobKindArchive, JobKind{
+		Permission: entity.PermissionDocumentsRead,
+		Resumable:  true,
+		Validate:   validateArchiveJob,
+		Run:        u.runArchiveJob,
+	})
+	jobs.Register(JobKindThumbnails, JobKind{
+		Permission: entity.PermissionAdmin,
+		Resumable:  true,
+		Validate:   validateThumbnailJob,
+		Run:        u.runThumbnailJob,
+	})
+}
+
+// JobRun is a running job's handle for reporting progress and storing its
+// outcome.
+type JobRun struct {
+	usecase *JobUsecase
+	job     *entity.Job
+	cancel  context.CancelFunc
+	saved   time.Time
+}
+
+// Progress records done out of total units of work, with total 0 when it is
+// not known yet. It cancels the job's context once cancellation has been
+// requested, possibly from another instance.
+func (r *JobRun) Progress(ctx context.Context, done, total int64) {
+	r.job.Progress, r.job.Total = done, total
+	if time.Since(r.saved) < jobProgressInterval && done != total {
+		return
+	}
+	r.saved = time.Now()
+
+	cancelRequested, err := r.usecase.repo.UpdateProgress(context.WithoutCancel(ctx), r.job.ID, done, total, r.saved)
+	if err != nil {
+		fmt.Printf("Failed to save progress of job %s: %v\n", r.job.ID, err)
+		return
+	}
+	if cancelRequested {
+		r.cancel()
+	}
+}
+
+// SetResult stores a JSON summary of the job's outcome.
+func (r *JobRun) SetResult(result any) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("Failed to encode job result %w", err)
+	}
+
+	value := string(encoded)
+	r.job.Result = &value
+	return nil
+}
+
+// StoreArtifact uploads the job's output, which can then be downloaded as
+// name until the job is purged.
+func (r *JobRun) StoreArtifact(ctx context.Context, name, contentType string, size int64, content io.Reader) error {
+	key := "jobs/" + r.job.TenantID + "/" + r.job.ID + "/" + name
+	if err := r.usecase.storage.Upload(ctx, key, size, contentType, content); err != nil {
+		return fmt.Errorf("Failed to upload to storage %w", err)
+	}
+
+	r.job.ArtifactKey = &key
+	r.job.ArtifactName = &name
+	r.job.ArtifactContentType = &contentType
+	r.job.ArtifactSize = &size
+	return nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/service"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultRolesClaim  = "roles"
+	defaultGroupsClaim = "groups"
+	defaultTenantClaim = "tenant_id"
+)
+
+type JWTAuthenticator struct {
+	verifier    service.TokenVerifier
+	rolesClaim  string
+	groupsClaim string
+	tenantClaim string
+}
+
+func NewJWTAuthenticator(verifier service.TokenVerifier, rolesClaim, groupsClaim, tenantClaim string) *JWTAuthenticator {
+	if rolesClaim == "" {
+		rolesClaim = defaultRolesClaim
+	}
+	if groupsClaim == "" {
+		groupsClaim = defaultGroupsClaim
+	}
+	if tenantClaim == "" {
+		tenantClaim = defaultTenantClaim
+	}
+
+	return &JWTAuthenticator{verifier: verifier, rolesClaim: rolesClaim, groupsClaim: groupsClaim, tenantClaim: tenantClaim}
+}
+
+// Authenticate resolves a bearer JWT to a user principal whose permissions
+// come from the roles claim. Users are always pinned to a tenant, the default
+// one when the token names none. Credentials that are not shaped like a JWT yield
+// (nil, nil) so other authenticators can try them.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, credential string) (*entity.Principal, error) {
+	if strings.Count(credential, ".") != 2 {
+		return nil, nil
+	}
+
+	claims, err := a.verifier.Verify(credential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrUnauthorized, err)
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", entity.ErrUnauthorized)
+	}
+
+	name, _ := claims["name"].(string)
+	if name == "" {
+		name = subject
+	}
+
+	roles := claimStrings(claims[a.rolesClaim])
+
+	tenantID, _ := claims[a.tenantClaim].(string)
+	if tenantID == "" {
+		tenantID = entity.DefaultTenantID
+	}
+
+	return &entity.Principal{
+		ID:          "user:" + subject,
+		Name:        name,
+		Type:        entity.PrincipalTypeUser,
+		Roles:       roles,
+		Groups:      claimStrings(claims[a.groupsClaim]),
+		Permissions: entity.PermissionsForRoles(roles),
+		TenantID:    tenantID,
+	}, nil
+}
+
+// claimStrings accepts a claim given as an array of strings or as a single
+// space-separated string.
+func claimStrings(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := []string{}
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+
+	return []string{}
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// reconcileGracePeriod spares objects younger than this, as an upload stores
+// its object before it saves the document.
+const reconcileGracePeriod = time.Hour
+
+// reconcileSkippedPrefixes hold objects that are not documents and are
+// cleaned up by their own imports and jobs.
+var reconcileSkippedPrefixes = []string{"imports/", "jobs/"}
+
+// ReconcileOrphan is an object no document refers to.
+type ReconcileOrphan struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	// Deleted reports whether repair removed the object; Err why it could not.
+	Deleted bool
+	Err     error
+}
+
+// ReconcileMissing is a document whose object is not in storage.
+type ReconcileMissing struct {
+	Document *entity.Document
+	// Marked reports whether the document is marked missing, whether by this
+	// run or an earlier one; Err is why repair could not mark it.
+	Marked bool
+	Err    error
+}
+
+type ReconcileReport struct {
+	Repair    bool
+	Objects   int
+	Documents int
+	Orphans   []ReconcileOrphan
+	Missing   []ReconcileMissing
+	// Found lists documents marked missing whose object is back; repair
+	// clears their mark.
+	Found []string
+}
+
+// Reconcile compares the objects in storage with the documents of every
+// tenant. It reports objects no document refers to and documents without an
+// object; with repair it deletes those objects and marks those documents
+// missing, which stops them from being downloaded.
+func (u *DocumentUsecase) Reconcile(ctx context.Context, repair bool) (*ReconcileReport, error) {
+	// Documents are loaded first, so that an upload finishing in between
+	// shows up as a young object rather than as a missing one.
+	docs, err := u.repo.FindStored(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find documents %w", err)
+	}
+
+	objects, err := u.storage.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list storage %w", err)
+	}
+
+	report := &ReconcileReport{Repair: repair, Objects: len(objects), Documents: len(docs),
+		Orphans: []ReconcileOrphan{}, Missing: []ReconcileMissing{}, Found: []string{}}
+
+	referenced := referencedBy(docs)
+	stored := map[string]bool{}
+	cutoff := time.Now().Add(-reconcileGracePeriod)
+	for _, object := range objects {
+		stored[object.Key] = true
+		if referenced(object.Key) || object.LastModified.After(cutoff) {
+			continue
+		}
+
+		orphan := ReconcileOrphan{Key: object.Key, Size: object.Size, LastModified: object.LastModified}
+		if repair {
+			orphan.Err = u.storage.Delete(ctx, object.Key)
+			orphan.Deleted = orphan.Err == nil
+		}
+		report.Orphans = append(report.Orphans, orphan)
+	}
+
+	now := time.Now()
+	for _, doc := range docs {
+		switch {
+		case stored[doc.ObjectKey()] && doc.MissingAt != nil:
+			report.Found = append(report.Found, doc.ID)
+			if repair {
+				if err := u.repo.UpdateMissing(ctx, doc.ID, nil); err != nil {
+					fmt.Printf("Failed to clear missing mark of %s: %v\n", doc.ID, err)
+				}
+			}
+		case !stored[doc.ObjectKey()]:
+			missing := ReconcileMissing{Document: doc, Marked: doc.MissingAt != nil}
+			if repair && !missing.Marked {
+				missing.Err = u.repo.UpdateMissing(ctx, doc.ID, &now)
+				missing.Marked = missing.Err == nil
+			}
+			report.Missing = append(report.Missing, missing)
+		}
+	}
+
+	fmt.Printf("Reconciled %d objects with %d documents: %d orphaned, %d missing\n", len(objects), len(docs), len(report.Orphans), len(report.Missing))
+	return report, nil
+}
+
+// ReferencedObjects loads the documents of every tenant and returns a check
+// of whether an object is one reconciliation would keep.
+func (u *DocumentUsecase) ReferencedObjects(ctx context.Context) (func(key string) bool, error) {
+	docs, err := u.repo.FindStored(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find documents %w", err)
+	}
+
+	return referencedBy(docs), nil
+}
+
+// referencedBy reports whether an object belongs to one of docs, directly or
+// as a thumbnail, or is kept apart from documents altogether.
+func referencedBy(docs []*entity.Document) func(key string) bool {
+	known := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		known[doc.ObjectKey()] = true
+	}
+
+	return func(key string) bool {
+		return known[reconcileOwnerKey(key)] ||
+			slices.ContainsFunc(reconcileSkippedPrefixes, func(prefix string) bool { return strings.HasPrefix(key, prefix) })
+	}
+}
+
+// reconcileOwnerKey is the key of the document object that key belongs to:
+// key itself, or the original for a thumbnail.
+func reconcileOwnerKey(key string) string {
+	if !strings.HasPrefix(key, thumbnailPrefix) {
+		return key
+	}
+
+	owner := strings.TrimPrefix(key, thumbnailPrefix)
+	if i := strings.LastIndex(owner, "/"); i >= 0 {
+		return owner[:i]
+	}
+
+	return owner
+}
+
+// ensureStored blocks access to documents whose object reconciliation found
+// missing.
+func ensureStored(doc *entity.Document) error {
+	if doc.MissingAt != nil {
+		return entity.ErrDocumentMissing
+	}
+
+	return nil
+}
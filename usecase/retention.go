@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/repository"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxPolicyNameLength = 128
+
+var policyContentTypePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9!#$&^_.+-]*/(\*|[a-z0-9][a-z0-9!#$&^_.+-]*)$`)
+
+type RetentionUsecase struct {
+	repo      repository.RetentionPolicyRepository
+	folders   repository.FolderRepository
+	documents *DocumentUsecase
+}
+
+type RetentionPolicyInput struct {
+	Name string
+	// ContentType is an exact MIME type, a "type/*" pattern or "" for any type.
+	ContentType  string
+	FolderID     string
+	DefaultTTL   *time.Duration
+	MinRetention *time.Duration
+	MaxRetention *time.Duration
+	NeverExpire  bool
+	Priority     int
+}
+
+func NewRetentionUsecase(repo repository.RetentionPolicyRepository, folders repository.FolderRepository, documents *DocumentUsecase) *RetentionUsecase {
+	return &RetentionUsecase{repo: repo, folders: folders, documents: documents}
+}
+
+func (u *RetentionUsecase) Create(ctx context.Context, input RetentionPolicyInput) (*entity.RetentionPolicy, error) {
+	now := time.Now()
+	policy := &entity.RetentionPolicy{
+		ID:        uuid.New().String(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := u.assign(ctx, policy, input); err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.Save(ctx, policy); err != nil {
+		return nil, fmt.Errorf("Failed to save retention policy %w", err)
+	}
+
+	if err := u.reapply(ctx); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (u *RetentionUsecase) Get(ctx context.Context, id string) (*entity.RetentionPolicy, error) {
+	policy, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find retention policy %w", err)
+	}
+
+	return policy, nil
+}
+
+func (u *RetentionUsecase) List(ctx context.Context) ([]*entity.RetentionPolicy, error) {
+	policies, err := u.repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list retention policies %w", err)
+	}
+
+	return policies, nil
+}
+
+func (u *RetentionUsecase) Update(ctx context.Context, id string, input RetentionPolicyInput) (*entity.RetentionPolicy, error) {
+	policy, err := u.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.assign(ctx, policy, input); err != nil {
+		return nil, err
+	}
+	policy.UpdatedAt = time.Now()
+
+	if err := u.repo.Update(ctx, policy); err != nil {
+		return nil, fmt.Errorf("Failed to update retention policy %w", err)
+	}
+
+	if err := u.reapply(ctx); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (u *RetentionUsecase) Delete(ctx context.Context, id string) error {
+	if err := u.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("Failed to delete retention policy %w", err)
+	}
+
+	return u.reapply(ctx)
+}
+
+func (u *RetentionUsecase) reapply(ctx context.Context) error {
+	if _, err := u.documents.ReapplyRetention(ctx); err != nil {
+		return fmt.Errorf("Failed to apply retention policies %w", err)
+	}
+
+	return nil
+}
+
+func (u *RetentionUsecase) assign(ctx context.Context, policy *entity.RetentionPolicy, input RetentionPolicyInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > maxPolicyNameLength {
+		return fmt.Errorf("%w: policy name must be 1 to %d characters", entity.ErrInvalidInput, maxPolicyNameLength)
+	}
+
+	contentType := strings.ToLower(strings.TrimSpace(input.ContentType))
+	if contentType != "" && !policyContentTypePattern.MatchString(contentType) {
+		return fmt.Errorf("%w: content_type must be a MIME type or a type/* pattern", entity.ErrInvalidInput)
+	}
+
+	for field, duration := range map[string]*time.Duration{
+		"default_ttl":   input.DefaultTTL,
+		"min_retention": input.MinRetention,
+		"max_retention": input.MaxRetention,
+	} {
+		if duration != nil && *duration <= 0 {
+			return fmt.Errorf("%w: %s must be positive", entity.ErrInvalidInput, field)
+		}
+	}
+
+	if input.MinRetention != nil && input.MaxRetention != nil && *input.MinRetention > *input.MaxRetention {
+		return fmt.Errorf("%w: min_retention must not exceed max_retention", entity.ErrInvalidInput)
+	}
+
+	if input.NeverExpire && (input.DefaultTTL != nil || input.MaxRetention != nil) {
+		return fmt.Errorf("%w: never_expire cannot be combined with default_ttl or max_retention", entity.ErrInvalidInput)
+	}
+
+	var folderID *string
+	if input.FolderID != "" {
+		folder, err := u.folders.FindById(ctx, input.FolderID)
+		if err != nil {
+			return fmt.Errorf("Failed to find folder %w", err)
+		}
+		folderID = &folder.ID
+	}
+
+	policy.Name = name
+	policy.ContentType = contentType
+	policy.FolderID = folderID
+	policy.DefaultTTL = input.DefaultTTL
+	policy.MinRetention = input.MinRetention
+	policy.MaxRetention = input.MaxRetention
+	policy.NeverExpire = input.NeverExpire
+	policy.Priority = input.Priority
+
+	return nil
+}
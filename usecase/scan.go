@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/service"
+	"fmt"
+	"io"
+	"time"
+)
+
+// quarantinePrefix is where infected objects are moved, away from the
+// tenant prefixes that downloads and listings read from.
+const quarantinePrefix = "quarantine/"
+
+// scanBatchSize bounds how many pending documents one ScanPending run scans.
+const scanBatchSize = 100
+
+// A pending document whose background scan fails is retried after
+// scanRetryDelay, and given up on with the error status once scanMaxAttempts
+// have failed. Failures that outlast the scanner being down a while are the
+// document's own.
+const (
+	scanRetryDelay  = time.Hour
+	scanMaxAttempts = 5
+)
+
+func WithScanner(scanner service.Scanner) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.scanner = scanner
+	}
+}
+
+// scanWhileUploading feeds everything read from file to the scanner as the
+// upload consumes it. The returned wait func must be called once the upload
+// has finished reading and reports the scan result.
+func (u *DocumentUsecase) scanWhileUploading(ctx context.Context, file io.Reader) (io.Reader, func(uploadErr error) (service.ScanResult, error)) {
+	pr, pw := io.Pipe()
+	type outcome struct {
+		result service.ScanResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+
+	go func() {
+		result, err := u.scanner.Scan(ctx, pr)
+		// The scanner may stop early; keep draining so the upload never blocks.
+		io.Copy(io.Discard, pr)
+		done <- outcome{result, err}
+	}()
+
+	return io.TeeReader(file, pw), func(uploadErr error) (service.ScanResult, error) {
+		if uploadErr != nil {
+			pw.CloseWithError(uploadErr)
+		} else {
+			pw.Close()
+		}
+
+		scanned := <-done
+		return scanned.result, scanned.err
+	}
+}
+
+// applyScan records result on doc without persisting it. Infected objects are
+// copied into quarantine; the original key is returned so the caller can
+// remove it once doc has been saved with its new storage key.
+func (u *DocumentUsecase) applyScan(ctx context.Context, doc *entity.Document, result service.ScanResult) (string, error) {
+	now := time.Now()
+
+	if !result.Infected {
+		doc.ScanStatus = entity.ScanStatusClean
+		doc.ScanResult = nil
+		doc.ScannedAt = &now
+		return "", nil
+	}
+
+	originalKey := doc.ObjectKey()
+	quarantineKey := quarantinePrefix + originalKey
+	if err := u.copyObject(ctx, doc, quarantineKey); err != nil {
+		return "", fmt.Errorf("Failed to quarantine document %w", err)
+	}
+
+	signature := result.Signature
+	doc.ScanStatus = entity.ScanStatusInfected
+	doc.ScanResult = &signature
+	doc.ScannedAt = &now
+	doc.StorageKey = quarantineKey
+	return originalKey, nil
+}
+
+func (u *DocumentUsecase) copyObject(ctx context.Context, doc *entity.Document, key string) error {
+	object, err := u.storage.Download(ctx, doc.ObjectKey())
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	return u.storage.Upload(ctx, key, doc.FileSize, doc.ContentType, object)
+}
+
+// removeObject deletes an object that is no longer referenced; failures only
+// leave an orphan behind, so they are logged rather than returned. It also
+// runs when ctx is canceled, as it often undoes work the canceled request did.
+func (u *DocumentUsecase) removeObject(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+
+	if err := u.storage.Delete(context.WithoutCancel(ctx), key); err != nil {
+		fmt.Printf("Failed to remove object %s: %v\n", key, err)
+	}
+}
+
+// publishScan emits file.scanned for clean documents and file.quarantined,
+// plus an audit entry, for infected ones.
+func (u *DocumentUsecase) publishScan(ctx context.Context, doc *entity.Document) error {
+	if doc.ScanStatus != entity.ScanStatusInfected {
+		return u.publish(ctx, "file.scanned", doc, map[string]interface{}{"scan_status": doc.ScanStatus})
+	}
+
+	u.audit.Record(ctx, entity.AuditActionQuarantine, doc.ID, *doc.ScanResult, nil)
+	return u.publish(ctx, "file.quarantined", doc, map[string]interface{}{
+		"scan_status": doc.ScanStatus,
+		"signature":   *doc.ScanResult,
+	})
+}
+
+// ensureScanned blocks access to infected documents and, when a scanner is
+// configured, to documents that have not been scanned yet.
+func (u *DocumentUsecase) ensureScanned(doc *entity.Document) error {
+	switch {
+	case doc.ScanStatus == entity.ScanStatusInfected:
+		return entity.ErrDocumentInfected
+	case u.scanner != nil && doc.ScanStatus == entity.ScanStatusError:
+		return entity.ErrScanFailed
+	case u.scanner != nil && doc.ScanStatus != entity.ScanStatusClean:
+		return entity.ErrScanPending
+	}
+
+	return nil
+}
+
+// ScanPending scans documents whose upload scan failed or that predate
+// scanning. Documents the scanner cannot handle are retried later, behind
+// the ones not tried yet, until they reach scanMaxAttempts.
+func (u *DocumentUsecase) ScanPending(ctx context.Context) error {
+	if u.scanner == nil {
+		return nil
+	}
+
+	now := time.Now()
+	pending, err := u.repo.FindByScanStatus(ctx, entity.ScanStatusPending, now.Add(-scanRetryDelay), scanBatchSize)
+	if err != nil {
+		return fmt.Errorf("Failed to find pending documents %w", err)
+	}
+
+	for _, doc := range pending {
+		if err := u.scan(ctx, doc); err != nil {
+			fmt.Printf("Failed to scan document %s: %v\n", doc.ID, err)
+			if err := u.repo.RecordScanFailure(ctx, doc.ID, err.Error(), now, scanMaxAttempts); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u *DocumentUsecase) scan(ctx context.Context, doc *entity.Document) error {
+	object, err := u.storage.Download(ctx, doc.ObjectKey())
+	if err != nil {
+		return fmt.Errorf("Failed to download from storage %w", err)
+	}
+	result, err := u.scanner.Scan(ctx, object)
+	object.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to scan document %w", err)
+	}
+
+	replacedKey, err := u.applyScan(ctx, doc, result)
+	if err != nil {
+		return err
+	}
+
+	if err := u.repo.UpdateScan(ctx, doc); err != nil {
+		if replacedKey != "" {
+			u.removeObject(ctx, doc.StorageKey)
+		}
+		return fmt.Errorf("Failed to update scan status %w", err)
+	}
+	u.removeObject(ctx, replacedKey)
+
+	return u.publishScan(ctx, doc)
+}
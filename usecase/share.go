@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"docvault/entity"
+	"docvault/repository"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	shareTokenBytes = 32
+	// maxSharePasswordLength is bcrypt's input limit.
+	maxSharePasswordLength = 72
+)
+
+type ShareUsecase struct {
+	repo      repository.ShareLinkRepository
+	documents *DocumentUsecase
+}
+
+func NewShareUsecase(repo repository.ShareLinkRepository, documents *DocumentUsecase) *ShareUsecase {
+	return &ShareUsecase{repo: repo, documents: documents}
+}
+
+type ShareInput struct {
+	// ExpiresIn is the link lifetime in seconds; 0 never expires.
+	ExpiresIn int
+	Password  string
+	// MaxDownloads limits how often the link can be used; 0 is unlimited.
+	MaxDownloads int64
+}
+
+// Create issues a share link for a document and returns it together with its
+// token, which is not stored and cannot be retrieved again.
+func (u *ShareUsecase) Create(ctx context.Context, documentID string, input ShareInput) (link *entity.ShareLink, token string, err error) {
+	defer func() {
+		detail := ""
+		if link != nil {
+			detail = "link " + link.ID
+		}
+		u.documents.audit.Record(ctx, entity.AuditActionShareCreate, documentID, detail, err)
+	}()
+
+	if input.ExpiresIn < 0 {
+		return nil, "", fmt.Errorf("%w: expires_in must not be negative", entity.ErrInvalidInput)
+	}
+	if input.MaxDownloads < 0 {
+		return nil, "", fmt.Errorf("%w: max_downloads must not be negative", entity.ErrInvalidInput)
+	}
+	if len(input.Password) > maxSharePasswordLength {
+		return nil, "", fmt.Errorf("%w: password must be at most %d bytes", entity.ErrInvalidInput, maxSharePasswordLength)
+	}
+
+	doc, err := u.documents.findManageable(ctx, documentID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	buf := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", fmt.Errorf("Failed to generate random bytes %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+
+	link = &entity.ShareLink{
+		ID:         uuid.New().String(),
+		DocumentID: doc.ID,
+		TokenHash:  hashSecret(token),
+		CreatedBy:  actorName(ctx, auditActorSystem),
+		CreatedAt:  time.Now(),
+	}
+	if input.ExpiresIn > 0 {
+		expiresAt := link.CreatedAt.Add(time.Duration(input.ExpiresIn) * time.Second)
+		link.ExpiresAt = &expiresAt
+	}
+	if input.MaxDownloads > 0 {
+		link.MaxDownloads = &input.MaxDownloads
+	}
+	if input.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, "", fmt.Errorf("Failed to hash share password %w", err)
+		}
+		passwordHash := string(hash)
+		link.PasswordHash = &passwordHash
+	}
+
+	if err := u.repo.Save(ctx, link); err != nil {
+		return nil, "", fmt.Errorf("Failed to save share link %w", err)
+	}
+
+	return link, token, nil
+}
+
+func (u *ShareUsecase) List(ctx context.Context, documentID string) ([]*entity.ShareLink, error) {
+	doc, err := u.documents.findFor(ctx, documentID, entity.ACLPermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := u.repo.FindByDocument(ctx, doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list share links %w", err)
+	}
+
+	return links, nil
+}
+
+func (u *ShareUsecase) Revoke(ctx context.Context, documentID, linkID string) (err error) {
+	defer func() {
+		u.documents.audit.Record(ctx, entity.AuditActionShareRevoke, documentID, "link "+linkID, err)
+	}()
+
+	if _, err := u.documents.findManageable(ctx, documentID); err != nil {
+		return err
+	}
+
+	if err := u.repo.Revoke(ctx, documentID, linkID, time.Now()); err != nil {
+		return fmt.Errorf("Failed to revoke share link %w", err)
+	}
+
+	return nil
+}
+
+// Download resolves a share token to its document and opens it. The download
+// is only counted once the object is open, and the count is taken atomically so
+// concurrent requests cannot exceed the link's limit.
+func (u *ShareUsecase) Download(ctx context.Context, token, password string) (doc *entity.Document, object io.ReadCloser, err error) {
+	var link *entity.ShareLink
+	defer func() {
+		if link != nil {
+			u.documents.audit.Record(ctx, entity.AuditActionShareUse, link.DocumentID, "link "+link.ID, err)
+		}
+	}()
+
+	link, err = u.repo.FindByTokenHash(ctx, hashSecret(token))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to find share link %w", err)
+	}
+
+	now := time.Now()
+	if !link.IsActive(now) {
+		return nil, nil, fmt.Errorf("Failed to use share link %w", entity.ErrShareLinkUnavailable)
+	}
+
+	if link.HasPassword() {
+		if password == "" || bcrypt.CompareHashAndPassword([]byte(*link.PasswordHash), []byte(password)) != nil {
+			return nil, nil, fmt.Errorf("%w: share link password is missing or wrong", entity.ErrUnauthorized)
+		}
+	}
+
+	doc, err = u.documents.find(ctx, link.DocumentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := u.documents.ensureScanned(doc); err != nil {
+		return nil, nil, fmt.Errorf("Failed to download document %w", err)
+	}
+	if err := ensureStored(doc); err != nil {
+		return nil, nil, fmt.Errorf("Failed to download document %w", err)
+	}
+
+	object, err = u.documents.storage.Download(ctx, doc.ObjectKey())
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to download from storage %w", err)
+	}
+
+	if err := u.repo.ConsumeDownload(ctx, link.ID, now); err != nil {
+		object.Close()
+		return nil, nil, fmt.Errorf("Failed to use share link %w", err)
+	}
+
+	return doc, object, nil
+}
@@ -0,0 +1,380 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"docvault/entity"
+	"docvault/repository"
+	"docvault/service"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StorageOpener connects to the backend a storage URL such as minio://bucket
+// or file:///srv/docvault names.
+type StorageOpener func(url string) (service.StorageService, error)
+
+type StorageMigrationUsecase struct {
+	repo    repository.StorageMigrationRepository
+	storage *service.MigratingStorage
+	open    StorageOpener
+
+	mu sync.Mutex
+	// active is the URL of the backend storage currently serves from.
+	active string
+}
+
+// StorageMigrationStatus describes the active backend and the latest
+// migration, with the outcome of each object it has copied so far.
+type StorageMigrationStatus struct {
+	Active    string
+	Migration *entity.StorageMigration
+	Items     []*entity.StorageMigrationItem
+}
+
+type storageMigrationParams struct {
+	Target string `json:"target"`
+}
+
+func NewStorageMigrationUsecase(repo repository.StorageMigrationRepository, storage *service.MigratingStorage, open StorageOpener, active string) *StorageMigrationUsecase {
+	return &StorageMigrationUsecase{repo: repo, storage: storage, open: open, active: active}
+}
+
+func (u *StorageMigrationUsecase) RegisterJobs(jobs *JobUsecase) {
+	jobs.Register(JobKindStorageMigration, JobKind{
+		Permission: entity.PermissionAdmin,
+		Platform:   true,
+		Resumable:  true,
+		Validate:   u.validateJob,
+		Run:        u.runJob,
+	})
+}
+
+// Restore picks up where earlier migrations left storage at startup: reads
+// fall back again during an unfinished one, and a finished one keeps serving
+// from its target even while the configuration still names the source.
+func (u *StorageMigrationUsecase) Restore(ctx context.Context) error {
+	migration, err := u.repo.FindLatest(ctx)
+	if errors.Is(err, entity.ErrStorageMigrationNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to find storage migration %w", err)
+	}
+
+	if migration.Source != u.active {
+		return nil
+	}
+	if u.ensureUnreplicated() != nil {
+		return fmt.Errorf("Storage migration to %s found; finish it and set STORAGE_URL to the target before setting STORAGE_REPLICA_URL", migration.Target)
+	}
+
+	target, err := u.open(migration.Target)
+	if err != nil {
+		return fmt.Errorf("Failed to open storage %s %w", migration.Target, err)
+	}
+
+	u.storage.Begin(target)
+	if migration.Status == entity.StorageMigrationCompleted {
+		u.storage.Finish()
+		u.active = migration.Target
+		fmt.Printf("Serving storage from %s, which a migration moved it to; set STORAGE_URL to match\n", migration.Target)
+	}
+
+	return nil
+}
+
+func (u *StorageMigrationUsecase) Status(ctx context.Context) (*StorageMigrationStatus, error) {
+	u.mu.Lock()
+	status := &StorageMigrationStatus{Active: u.active}
+	u.mu.Unlock()
+
+	migration, err := u.repo.FindLatest(ctx)
+	if errors.Is(err, entity.ErrStorageMigrationNotFound) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find storage migration %w", err)
+	}
+
+	items, err := u.repo.FindItems(ctx, migration.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find storage migration items %w", err)
+	}
+
+	status.Migration, status.Items = migration, items
+	return status, nil
+}
+
+func (u *StorageMigrationUsecase) validateJob(params json.RawMessage) error {
+	var p storageMigrationParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return fmt.Errorf("%w: invalid storage migration params: %v", entity.ErrInvalidInput, err)
+	}
+
+	if err := u.ensureUnreplicated(); err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	active := u.active
+	u.mu.Unlock()
+
+	switch {
+	case strings.TrimSpace(p.Target) == "":
+		return fmt.Errorf("%w: target is required", entity.ErrInvalidInput)
+	case p.Target == active:
+		return fmt.Errorf("%w: storage is already served from %s", entity.ErrInvalidInput, p.Target)
+	}
+
+	if _, err := u.open(p.Target); err != nil {
+		return fmt.Errorf("%w: %v", entity.ErrInvalidInput, err)
+	}
+
+	return nil
+}
+
+// runJob copies every object of the active backend that the target lacks,
+// verifying each copy's checksum, and switches to the target once all are
+// there. A failed or interrupted run is resumed by running it again, which
+// skips the objects already copied.
+func (u *StorageMigrationUsecase) runJob(ctx context.Context, job *entity.Job, run *JobRun) error {
+	var p storageMigrationParams
+	if err := json.Unmarshal([]byte(job.Params), &p); err != nil {
+		return fmt.Errorf("%w: invalid storage migration params: %v", entity.ErrInvalidInput, err)
+	}
+
+	migration, err := u.begin(ctx, p.Target, job.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	// Uploads that started before the move may still be writing to the
+	// source, and the listing has to include what they write.
+	if err := u.storage.Drain(ctx); err != nil {
+		return err
+	}
+
+	source, target := u.storage.Backends()
+	objects, err := source.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("Failed to list storage %w", err)
+	}
+
+	items, err := u.repo.FindItems(ctx, migration.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to find storage migration items %w", err)
+	}
+	done := map[string]bool{}
+	for _, item := range items {
+		done[item.Key] = item.Status != entity.StorageMigrationItemFailed
+	}
+
+	counts := map[string]int{}
+	run.Progress(ctx, 0, int64(len(objects)))
+	for i, object := range objects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if done[object.Key] {
+			counts["skipped"]++
+		} else {
+			item := copyStorageObject(ctx, source, target, object)
+			item.MigrationID = migration.ID
+			if err := u.repo.SaveItem(ctx, item); err != nil {
+				return fmt.Errorf("Failed to save storage migration item %w", err)
+			}
+			counts[item.Status]++
+		}
+		run.Progress(ctx, int64(i+1), int64(len(objects)))
+	}
+
+	result := map[string]any{
+		"migration_id": migration.ID,
+		"source":       migration.Source,
+		"target":       migration.Target,
+		"copied":       counts[entity.StorageMigrationItemCopied],
+		"present":      counts[entity.StorageMigrationItemPresent],
+		"skipped":      counts["skipped"],
+		"failed":       counts[entity.StorageMigrationItemFailed],
+	}
+	if err := run.SetResult(result); err != nil {
+		return err
+	}
+
+	if failed := counts[entity.StorageMigrationItemFailed]; failed > 0 {
+		return fmt.Errorf("%d objects failed to copy; run the migration again to retry them", failed)
+	}
+
+	return u.finish(ctx, migration)
+}
+
+// begin continues the unfinished migration to target or starts a new one,
+// from then on writing new objects to target.
+func (u *StorageMigrationUsecase) begin(ctx context.Context, targetURL string, createdBy *string) (*entity.StorageMigration, error) {
+	if err := u.ensureUnreplicated(); err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	migration, err := u.repo.FindLatest(ctx)
+	if err != nil && !errors.Is(err, entity.ErrStorageMigrationNotFound) {
+		return nil, fmt.Errorf("Failed to find storage migration %w", err)
+	}
+
+	if migration != nil && migration.Status == entity.StorageMigrationCopying && migration.Source == u.active {
+		if migration.Target != targetURL {
+			return nil, fmt.Errorf("%w: a migration to %s has not finished", entity.ErrInvalidInput, migration.Target)
+		}
+	} else {
+		now := time.Now()
+		migration = &entity.StorageMigration{
+			ID:        uuid.New().String(),
+			Source:    u.active,
+			Target:    targetURL,
+			Status:    entity.StorageMigrationCopying,
+			CreatedBy: createdBy,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := u.repo.Save(ctx, migration); err != nil {
+			return nil, fmt.Errorf("Failed to save storage migration %w", err)
+		}
+	}
+
+	if _, current := u.storage.Backends(); current == nil {
+		target, err := u.open(targetURL)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open storage %s %w", targetURL, err)
+		}
+		// New uploads go to target from here on, so it has to work first.
+		if err := target.Health(ctx); err != nil {
+			return nil, fmt.Errorf("Failed to reach storage %s %w", targetURL, err)
+		}
+		u.storage.Begin(target)
+	}
+
+	return migration, nil
+}
+
+func (u *StorageMigrationUsecase) finish(ctx context.Context, migration *entity.StorageMigration) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := time.Now()
+	migration.Status = entity.StorageMigrationCompleted
+	migration.UpdatedAt = now
+	migration.CompletedAt = &now
+	if err := u.repo.Update(context.WithoutCancel(ctx), migration); err != nil {
+		return fmt.Errorf("Failed to update storage migration %w", err)
+	}
+
+	u.storage.Finish()
+	u.active = migration.Target
+	fmt.Printf("Storage migrated from %s to %s; set STORAGE_URL to match\n", migration.Source, migration.Target)
+	return nil
+}
+
+// ensureUnreplicated refuses migrations of replicated storage: the target
+// would replace both replicas with a single backend.
+func (u *StorageMigrationUsecase) ensureUnreplicated() error {
+	active, _ := u.storage.Backends()
+	if _, ok := active.(service.ReplicaReporter); ok {
+		return fmt.Errorf("%w: storage is replicated; unset STORAGE_REPLICA_URL before migrating it", entity.ErrInvalidInput)
+	}
+
+	return nil
+}
+
+// copyStorageObject copies object unless target has it already and checks
+// that the copy reads back with the original's SHA-256. An object the target
+// already has counts as present only when it matches the source; any other
+// is copied over.
+func copyStorageObject(ctx context.Context, source, target service.StorageService, object service.StorageObject) *entity.StorageMigrationItem {
+	item := &entity.StorageMigrationItem{Key: object.Key, Size: object.Size, Status: entity.StorageMigrationItemFailed, UpdatedAt: time.Now()}
+	fail := func(err error) *entity.StorageMigrationItem {
+		message := err.Error()
+		item.Error = &message
+		return item
+	}
+
+	existing, err := target.List(ctx, object.Key)
+	if err != nil {
+		return fail(fmt.Errorf("Failed to list target storage %w", err))
+	}
+	for _, candidate := range existing {
+		if candidate.Key != object.Key || candidate.Size != object.Size {
+			continue
+		}
+
+		checksum, err := storageChecksum(ctx, source, object.Key)
+		if err != nil {
+			return fail(fmt.Errorf("Failed to download from storage %w", err))
+		}
+		existingChecksum, err := storageChecksum(ctx, target, object.Key)
+		if err != nil {
+			return fail(fmt.Errorf("Failed to read target storage %w", err))
+		}
+		if existingChecksum == checksum {
+			item.Status = entity.StorageMigrationItemPresent
+			item.Checksum = &checksum
+			return item
+		}
+	}
+
+	reader, err := source.Download(ctx, object.Key)
+	if err != nil {
+		return fail(fmt.Errorf("Failed to download from storage %w", err))
+	}
+	hash := sha256.New()
+	err = target.Upload(ctx, object.Key, object.Size, "application/octet-stream", io.TeeReader(reader, hash))
+	reader.Close()
+	if err != nil {
+		return fail(fmt.Errorf("Failed to upload to storage %w", err))
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+
+	copiedChecksum, err := storageChecksum(ctx, target, object.Key)
+	if err != nil {
+		return fail(fmt.Errorf("Failed to verify copy %w", err))
+	}
+	if copiedChecksum != checksum {
+		// Removed so that a retry does not take it for an object written
+		// to the target since.
+		if err := target.Delete(context.WithoutCancel(ctx), object.Key); err != nil {
+			fmt.Printf("Failed to remove bad copy of %s: %v\n", object.Key, err)
+		}
+		return fail(fmt.Errorf("checksum mismatch: copied %s, read back %s", checksum, copiedChecksum))
+	}
+
+	item.Status = entity.StorageMigrationItemCopied
+	item.Checksum = &checksum
+	return item
+}
+
+// storageChecksum returns the hex SHA-256 of the object stored under key.
+func storageChecksum(ctx context.Context, storage service.StorageService, key string) (string, error) {
+	object, err := storage.Download(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer object.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, object); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
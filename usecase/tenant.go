@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/repository"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxTenantNameLength = 255
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+type TenantUsecase struct {
+	repo      repository.TenantRepository
+	documents *DocumentUsecase
+}
+
+type TenantInput struct {
+	Name               string
+	QuotaBytes         *int64
+	QuotaDocuments     *int64
+	UserQuotaBytes     *int64
+	UserQuotaDocuments *int64
+	DefaultRetention   *time.Duration
+}
+
+func NewTenantUsecase(repo repository.TenantRepository, documents *DocumentUsecase) *TenantUsecase {
+	return &TenantUsecase{repo: repo, documents: documents}
+}
+
+// tenantOf returns the tenant a request works in; internal callers without one
+// land in the default tenant.
+func tenantOf(ctx context.Context) string {
+	if tenantID, ok := entity.TenantFromContext(ctx); ok {
+		return tenantID
+	}
+
+	return entity.DefaultTenantID
+}
+
+// inTenant reports whether a record belongs to the request's tenant. Requests
+// without a tenant, such as scheduled jobs, see every tenant.
+func inTenant(ctx context.Context, tenantID string) bool {
+	current, ok := entity.TenantFromContext(ctx)
+	return !ok || current == tenantID
+}
+
+func (u *TenantUsecase) Create(ctx context.Context, id string, input TenantInput) (*entity.Tenant, error) {
+	id = strings.TrimSpace(id)
+	if !tenantIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("%w: tenant id must be 1 to 63 lowercase letters, digits or dashes", entity.ErrInvalidInput)
+	}
+
+	now := time.Now()
+	tenant := &entity.Tenant{
+		ID:        id,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := assignTenant(tenant, input); err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.Save(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("Failed to save tenant %w", err)
+	}
+
+	return tenant, nil
+}
+
+func (u *TenantUsecase) Get(ctx context.Context, id string) (*entity.Tenant, error) {
+	tenant, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find tenant %w", err)
+	}
+
+	return tenant, nil
+}
+
+func (u *TenantUsecase) List(ctx context.Context) ([]*entity.Tenant, error) {
+	tenants, err := u.repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list tenants %w", err)
+	}
+
+	return tenants, nil
+}
+
+func (u *TenantUsecase) Update(ctx context.Context, id string, input TenantInput) (*entity.Tenant, error) {
+	tenant, err := u.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	retentionChanged := !sameDuration(tenant.DefaultRetention, input.DefaultRetention)
+	if err := assignTenant(tenant, input); err != nil {
+		return nil, err
+	}
+	tenant.UpdatedAt = time.Now()
+
+	if err := u.repo.Update(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("Failed to update tenant %w", err)
+	}
+
+	if retentionChanged {
+		if _, err := u.documents.ReapplyRetention(ctx); err != nil {
+			return nil, fmt.Errorf("Failed to apply retention defaults %w", err)
+		}
+	}
+
+	return tenant, nil
+}
+
+// Delete removes an empty tenant. The default tenant always exists.
+func (u *TenantUsecase) Delete(ctx context.Context, id string) error {
+	if id == entity.DefaultTenantID {
+		return fmt.Errorf("%w: the default tenant cannot be deleted", entity.ErrInvalidInput)
+	}
+
+	if err := u.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("Failed to delete tenant %w", err)
+	}
+
+	return nil
+}
+
+// ResolveTenant confirms a tenant named by a request exists.
+func (u *TenantUsecase) ResolveTenant(ctx context.Context, id string) error {
+	if _, err := u.repo.FindById(ctx, id); err != nil {
+		if errors.Is(err, entity.ErrTenantNotFound) {
+			return err
+		}
+		return fmt.Errorf("Failed to find tenant %w", err)
+	}
+
+	return nil
+}
+
+func assignTenant(tenant *entity.Tenant, input TenantInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > maxTenantNameLength {
+		return fmt.Errorf("%w: tenant name must be 1 to %d characters", entity.ErrInvalidInput, maxTenantNameLength)
+	}
+
+	for field, limit := range map[string]*int64{
+		"quota_bytes":          input.QuotaBytes,
+		"quota_documents":      input.QuotaDocuments,
+		"user_quota_bytes":     input.UserQuotaBytes,
+		"user_quota_documents": input.UserQuotaDocuments,
+	} {
+		if limit != nil && *limit <= 0 {
+			return fmt.Errorf("%w: %s must be positive", entity.ErrInvalidInput, field)
+		}
+	}
+
+	if input.DefaultRetention != nil && *input.DefaultRetention <= 0 {
+		return fmt.Errorf("%w: default_retention must be positive", entity.ErrInvalidInput)
+	}
+
+	tenant.Name = name
+	tenant.QuotaBytes = input.QuotaBytes
+	tenant.QuotaDocuments = input.QuotaDocuments
+	tenant.UserQuotaBytes = input.UserQuotaBytes
+	tenant.UserQuotaDocuments = input.UserQuotaDocuments
+	tenant.DefaultRetention = input.DefaultRetention
+
+	return nil
+}
+
+func sameDuration(a, b *time.Duration) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
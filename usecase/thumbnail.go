@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"docvault/entity"
+	"docvault/repository"
+	"docvault/service"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// thumbnailPrefix keeps derived objects apart from the documents themselves.
+const thumbnailPrefix = "thumbnails/"
+
+// WithThumbnails renders a thumbnail fitting within each of sizes for every
+// image upload. The first size is served when none is requested.
+func WithThumbnails(thumbnails repository.ThumbnailRepository, thumbnailer service.Thumbnailer, sizes []int) DocumentOption {
+	return func(u *DocumentUsecase) {
+		u.thumbnails = thumbnails
+		u.thumbnailer = thumbnailer
+		u.thumbnailSizes = sizes
+	}
+}
+
+func thumbnailKey(doc *entity.Document, size int) string {
+	return thumbnailPrefix + doc.ObjectKey() + "/" + strconv.Itoa(size)
+}
+
+// GenerateThumbnails renders and stores the configured thumbnail sizes for an
+// image document. Other documents are left alone.
+func (u *DocumentUsecase) GenerateThumbnails(ctx context.Context, id string) error {
+	if u.thumbnailer == nil || len(u.thumbnailSizes) == 0 {
+		return nil
+	}
+
+	doc, err := u.load(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(entity.ThumbnailContentTypes, baseMediaType(doc.ContentType)) || doc.IsTrashed() || doc.ScanStatus == entity.ScanStatusInfected {
+		return nil
+	}
+
+	object, err := u.storage.Download(ctx, doc.ObjectKey())
+	if err != nil {
+		return fmt.Errorf("Failed to download from storage %w", err)
+	}
+	rendered, err := u.thumbnailer.Thumbnails(object, u.thumbnailSizes)
+	object.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to render thumbnails %w", err)
+	}
+
+	for _, render := range rendered {
+		thumbnail := &entity.Thumbnail{
+			DocumentID:  doc.ID,
+			Size:        render.Size,
+			StorageKey:  thumbnailKey(doc, render.Size),
+			ContentType: render.ContentType,
+			Width:       render.Width,
+			Height:      render.Height,
+			FileSize:    int64(len(render.Data)),
+			CreatedAt:   time.Now(),
+		}
+
+		if err := u.storage.Upload(ctx, thumbnail.StorageKey, thumbnail.FileSize, thumbnail.ContentType, bytes.NewReader(render.Data)); err != nil {
+			return fmt.Errorf("Failed to upload thumbnail %w", err)
+		}
+
+		if err := u.thumbnails.Save(ctx, thumbnail); err != nil {
+			return fmt.Errorf("Failed to save thumbnail %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Thumbnail opens the thumbnail of one of the configured sizes; 0 picks the
+// first. It is subject to the same checks as downloading the document.
+func (u *DocumentUsecase) Thumbnail(ctx context.Context, id string, size int) (*entity.Thumbnail, io.ReadCloser, error) {
+	if u.thumbnails == nil || len(u.thumbnailSizes) == 0 {
+		return nil, nil, fmt.Errorf("Failed to find thumbnail %w", entity.ErrThumbnailNotFound)
+	}
+
+	if size == 0 {
+		size = u.thumbnailSizes[0]
+	}
+	if !slices.Contains(u.thumbnailSizes, size) {
+		return nil, nil, fmt.Errorf("%w: size must be one of %v", entity.ErrInvalidInput, u.thumbnailSizes)
+	}
+
+	doc, err := u.findFor(ctx, id, entity.ACLPermissionRead)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := u.ensureScanned(doc); err != nil {
+		return nil, nil, fmt.Errorf("Failed to read thumbnail %w", err)
+	}
+
+	thumbnail, err := u.thumbnails.Find(ctx, doc.ID, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to find thumbnail %w", err)
+	}
+
+	object, err := u.storage.Download(ctx, thumbnail.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to download from storage %w", err)
+	}
+
+	return thumbnail, object, nil
+}
+
+// removeThumbnails deletes a document's derived objects ahead of purging it;
+// the rows go with the document itself.
+func (u *DocumentUsecase) removeThumbnails(ctx context.Context, doc *entity.Document) error {
+	if u.thumbnails == nil {
+		return nil
+	}
+
+	thumbnails, err := u.thumbnails.FindByDocument(ctx, doc.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to find thumbnails %w", err)
+	}
+
+	for _, thumbnail := range thumbnails {
+		u.removeObject(ctx, thumbnail.StorageKey)
+	}
+
+	return nil
+}
+
+// thumbnailJobParams select the documents a thumbnails job renders again,
+// by ids or by filter.
+type thumbnailJobParams struct {
+	IDs      []string          `json:"ids"`
+	FolderID *string           `json:"folder_id"`
+	Tags     []string          `json:"tags"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+func (p thumbnailJobParams) filter() entity.DocumentFilter {
+	return entity.DocumentFilter{FolderID: p.FolderID, Tags: p.Tags, Metadata: p.Metadata}
+}
+
+func validateThumbnailJob(params json.RawMessage) error {
+	var p thumbnailJobParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return fmt.Errorf("%w: invalid thumbnails params: %v", entity.ErrInvalidInput, err)
+	}
+
+	if len(p.IDs) > 0 && selectsDocuments(p.filter()) {
+		return fmt.Errorf("%w: select documents by ids or by filter, not both", entity.ErrInvalidInput)
+	}
+
+	return nil
+}
+
+// runThumbnailJob re-renders the thumbnails of the selected documents, or of
+// every document when none are selected, such as after the configured sizes
+// changed. A document that fails is counted and does not stop the others.
+func (u *DocumentUsecase) runThumbnailJob(ctx context.Context, job *entity.Job, run *JobRun) error {
+	var p thumbnailJobParams
+	if err := json.Unmarshal([]byte(job.Params), &p); err != nil {
+		return fmt.Errorf("%w: invalid thumbnails params: %v", entity.ErrInvalidInput, err)
+	}
+
+	ids := p.IDs
+	if len(ids) == 0 {
+		docs, err := u.List(ctx, p.filter())
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			ids = append(ids, doc.ID)
+		}
+	}
+
+	failed := 0
+	for i, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := u.GenerateThumbnails(ctx, id); err != nil {
+			fmt.Printf("Failed to render thumbnails of %s: %v\n", id, err)
+			failed++
+		}
+		run.Progress(ctx, int64(i+1), int64(len(ids)))
+	}
+
+	return run.SetResult(map[string]int{"documents": len(ids), "failed": failed})
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"docvault/entity"
+	"docvault/repository"
+	"fmt"
+)
+
+type UsageUsecase struct {
+	repo    repository.UsageRepository
+	tenants repository.TenantRepository
+}
+
+// QuotaUsage pairs a usage total with the quota that applies to it.
+type QuotaUsage struct {
+	Usage *entity.Usage
+	Quota entity.Quota
+}
+
+func NewUsageUsecase(repo repository.UsageRepository, tenants repository.TenantRepository) *UsageUsecase {
+	return &UsageUsecase{repo: repo, tenants: tenants}
+}
+
+// Get reports the caller's tenant usage and, for authenticated callers, their
+// own usage within it.
+func (u *UsageUsecase) Get(ctx context.Context) ([]*QuotaUsage, error) {
+	tenant, err := u.tenants.FindById(ctx, tenantOf(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find tenant %w", err)
+	}
+
+	var ownerID *string
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		ownerID = &principal.ID
+	}
+
+	report := []*QuotaUsage{}
+	for _, quota := range tenant.Quotas(ownerID) {
+		usage, err := u.repo.Find(ctx, quota.Scope, quota.SubjectID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to find storage usage %w", err)
+		}
+		report = append(report, &QuotaUsage{Usage: usage, Quota: quota})
+	}
+
+	return report, nil
+}
+
+// Reconcile recomputes usage totals from the documents table, repairing any
+// drift left by failed writes or manual database changes.
+func (u *UsageUsecase) Reconcile(ctx context.Context) error {
+	corrected, err := u.repo.Reconcile(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to reconcile storage usage %w", err)
+	}
+
+	for _, usage := range corrected {
+		fmt.Printf("Corrected storage usage for %s %s: %d bytes, %d documents\n", usage.Scope, usage.SubjectID, usage.Bytes, usage.Documents)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"context"
+	"docvault/usecase"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type JobWorker struct {
+	jobs        *usecase.JobUsecase
+	concurrency int
+}
+
+func NewJobWorker(jobs *usecase.JobUsecase, concurrency int) *JobWorker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &JobWorker{jobs: jobs, concurrency: concurrency}
+}
+
+func (w *JobWorker) Start(ctx context.Context) {
+	if err := w.jobs.Recover(ctx); err != nil {
+		fmt.Println(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(w.concurrency)
+	for range w.concurrency {
+		go func() {
+			w.run(ctx)
+			wg.Done()
+		}()
+	}
+
+	purgeTicker := time.NewTicker(time.Hour)
+	for {
+		select {
+		case <-purgeTicker.C:
+			if err := w.jobs.PurgeFinished(ctx); err != nil {
+				fmt.Println(err)
+			}
+		case <-ctx.Done():
+			purgeTicker.Stop()
+			wg.Wait()
+			return
+		}
+	}
+}
+
+// run works through queued jobs one at a time, polling while there are none.
+func (w *JobWorker) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		ran, err := w.jobs.RunNext(ctx)
+		if err != nil {
+			fmt.Println(err)
+		}
+		if ran {
+			continue
+		}
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+		}
+	}
+}
@@ -3,20 +3,29 @@ package worker
 import (
 	"context"
 	"docvault/service"
+	"docvault/usecase"
+	"encoding/json"
 	"fmt"
 	"log"
 )
 
 type NotificationWorker struct {
-	queue service.QueueService
+	queue     service.QueueService
+	documents *usecase.DocumentUsecase
 }
 
-func NewNotificationWorker(queue service.QueueService) *NotificationWorker {
+func NewNotificationWorker(queue service.QueueService, documents *usecase.DocumentUsecase) *NotificationWorker {
 	return &NotificationWorker{
-		queue: queue,
+		queue:     queue,
+		documents: documents,
 	}
 }
 
+type event struct {
+	Type       string `json:"type"`
+	DocumentID string `json:"document_id"`
+}
+
 func (w *NotificationWorker) Start(ctx context.Context) {
 	msgChan, err := w.queue.Consume(ctx)
 	if err != nil {
@@ -26,5 +35,21 @@ func (w *NotificationWorker) Start(ctx context.Context) {
 
 	for message := range msgChan {
 		fmt.Printf("Received message: %s\n", message)
+		w.handle(ctx, message)
+	}
+}
+
+func (w *NotificationWorker) handle(ctx context.Context, message string) {
+	var e event
+	if err := json.Unmarshal([]byte(message), &e); err != nil {
+		fmt.Printf("Failed to decode message: %v\n", err)
+		return
+	}
+
+	switch e.Type {
+	case "file.uploaded":
+		if err := w.documents.GenerateThumbnails(ctx, e.DocumentID); err != nil {
+			fmt.Printf("Failed to generate thumbnails for document %s: %v\n", e.DocumentID, err)
+		}
 	}
 }
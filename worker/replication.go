@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"docvault/service"
+	"docvault/usecase"
+	"fmt"
+	"time"
+)
+
+// replicationRetryInterval is how often repairs that failed are retried.
+const replicationRetryInterval = 30 * time.Second
+
+// ReplicationWorker drains the repair queue of replicated storage as repairs
+// are queued, retries the ones that fail, and resyncs the replicas at start
+// and every resyncInterval, which 0 limits to the start.
+type ReplicationWorker struct {
+	storage        *service.ReplicatedStorage
+	documents      *usecase.DocumentUsecase
+	resyncInterval time.Duration
+}
+
+func NewReplicationWorker(storage *service.ReplicatedStorage, documents *usecase.DocumentUsecase, resyncInterval time.Duration) *ReplicationWorker {
+	return &ReplicationWorker{storage: storage, documents: documents, resyncInterval: resyncInterval}
+}
+
+func (w *ReplicationWorker) Start(ctx context.Context) {
+	w.resync(ctx)
+
+	retryTicker := time.NewTicker(replicationRetryInterval)
+	defer retryTicker.Stop()
+
+	var resync <-chan time.Time
+	if w.resyncInterval > 0 {
+		resyncTicker := time.NewTicker(w.resyncInterval)
+		defer resyncTicker.Stop()
+		resync = resyncTicker.C
+	}
+
+	for {
+		select {
+		case <-w.storage.Queued():
+			w.storage.Repair(ctx)
+		case <-retryTicker.C:
+			w.storage.Repair(ctx)
+		case <-resync:
+			w.resync(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *ReplicationWorker) resync(ctx context.Context) {
+	// Documents are loaded before the listings, as in reconciliation.
+	referenced, err := w.documents.ReferencedObjects(ctx)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := w.storage.Resync(ctx, referenced); err != nil {
+		fmt.Println(err)
+		return
+	}
+	w.storage.Repair(ctx)
+}
@@ -3,26 +3,66 @@ package worker
 import (
 	"context"
 	"docvault/usecase"
+	"fmt"
 	"time"
 )
 
 type SchedulerWorker struct {
-	usecase *usecase.DocumentUsecase
+	usecase           *usecase.DocumentUsecase
+	usage             *usecase.UsageUsecase
+	trashRetention    time.Duration
+	reconcileInterval time.Duration
+	fsckInterval      time.Duration
+	fsckRepair        bool
 }
 
-func NewSchedulerWorker(usecase *usecase.DocumentUsecase) *SchedulerWorker {
-	return &SchedulerWorker{usecase: usecase}
+func NewSchedulerWorker(usecase *usecase.DocumentUsecase, usage *usecase.UsageUsecase, trashRetention, reconcileInterval time.Duration) *SchedulerWorker {
+	return &SchedulerWorker{usecase: usecase, usage: usage, trashRetention: trashRetention, reconcileInterval: reconcileInterval}
+}
+
+// WithFsck reconciles storage with the documents table every interval,
+// repairing what it finds when repair is set. It is off by default.
+func (s *SchedulerWorker) WithFsck(interval time.Duration, repair bool) *SchedulerWorker {
+	s.fsckInterval = interval
+	s.fsckRepair = repair
+	return s
 }
 
 func (s *SchedulerWorker) Start(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	var reconcile <-chan time.Time
+	if s.reconcileInterval > 0 {
+		reconcileTicker := time.NewTicker(s.reconcileInterval)
+		defer reconcileTicker.Stop()
+		reconcile = reconcileTicker.C
+	}
+
+	var fsck <-chan time.Time
+	if s.fsckInterval > 0 {
+		fsckTicker := time.NewTicker(s.fsckInterval)
+		defer fsckTicker.Stop()
+		fsck = fsckTicker.C
+	}
 
 	for {
 		select {
 		case <-ticker.C:
 			s.usecase.DeleteExpiredDocuments(ctx)
+			s.usecase.PurgeTrash(ctx, s.trashRetention)
+			if err := s.usecase.ScanPending(ctx); err != nil {
+				fmt.Println(err)
+			}
+		case <-reconcile:
+			if err := s.usage.Reconcile(ctx); err != nil {
+				fmt.Println(err)
+			}
+		case <-fsck:
+			if _, err := s.usecase.Reconcile(ctx, s.fsckRepair); err != nil {
+				fmt.Println(err)
+			}
 		case <-ctx.Done():
-			ticker.Stop()
 			return
 		}
 	}